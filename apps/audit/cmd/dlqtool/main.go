@@ -0,0 +1,192 @@
+// Command dlqtool inspects and optionally replays messages sitting in the
+// audit service's dead-letter topic, giving operators a recovery path
+// after an Elasticsearch outage without writing one-off scripts.
+//
+// By default it runs in dry-run mode: it prints a summary of each matching
+// message and does not commit consumer offsets, so repeated runs see the
+// same messages. Pass -replay to republish matching messages back to the
+// source topic and commit their offsets, so they aren't replayed again by
+// a later run of this tool. A matching message that has already reached
+// -max-retries is never replayed -- it's written to -quarantine-topic
+// instead, the same terminal state onIndexFailure routes it to on the live
+// consumer, so this tool can't undo that by putting it back in rotation.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/kafkaauth"
+)
+
+func main() {
+	var (
+		brokers         = flag.String("brokers", "localhost:9092", "comma-separated Kafka broker addresses")
+		dlqTopic        = flag.String("dlq-topic", "transaction-events-dlq", "dead-letter topic to read from")
+		sourceTopic     = flag.String("source-topic", "transaction-events", "topic to republish replayed messages to")
+		quarantineTopic = flag.String("quarantine-topic", "transaction-events-quarantine", "topic matching messages at or past -max-retries are sent to instead of being replayed")
+		maxRetries      = flag.Int("max-retries", 0, "quarantine instead of replaying a message whose retry_count is at least this many (0 disables quarantining)")
+		groupID         = flag.String("group-id", "audit-dlq-tool", "consumer group ID used to track read position in the DLQ topic")
+		errorType       = flag.String("error-type", "", "only consider messages with this error_type (default: any)")
+		since           = flag.String("since", "", "only consider messages with failed_at on or after this RFC3339 timestamp")
+		until           = flag.String("until", "", "only consider messages with failed_at on or before this RFC3339 timestamp")
+		replay          = flag.Bool("replay", false, "republish matching messages to -source-topic and commit their offsets (default: dry-run)")
+		readTimeout     = flag.Duration("read-timeout", 10*time.Second, "stop after this long without a new message")
+	)
+	flag.Parse()
+
+	var sinceTime, untilTime time.Time
+	var err error
+	if *since != "" {
+		sinceTime, err = time.Parse(time.RFC3339, *since)
+		if err != nil {
+			log.Fatalf("dlqtool: invalid -since: %v", err)
+		}
+	}
+	if *until != "" {
+		untilTime, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			log.Fatalf("dlqtool: invalid -until: %v", err)
+		}
+	}
+
+	kafkaAuth := kafkaauth.LoadFromEnv()
+	if err := kafkaAuth.Validate(); err != nil {
+		log.Fatalf("dlqtool: %v", err)
+	}
+	dialer, err := kafkaAuth.Dialer()
+	if err != nil {
+		log.Fatalf("dlqtool: failed to build kafka dialer: %v", err)
+	}
+	transport, err := kafkaAuth.Transport()
+	if err != nil {
+		log.Fatalf("dlqtool: failed to build kafka transport: %v", err)
+	}
+
+	brokerList := strings.Split(*brokers, ",")
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        brokerList,
+		Topic:          *dlqTopic,
+		GroupID:        *groupID,
+		Dialer:         dialer,
+		CommitInterval: 0, // commit explicitly, only when replaying
+	})
+	defer reader.Close()
+
+	var writer, quarantineWriter *kafka.Writer
+	if *replay {
+		writer = &kafka.Writer{
+			Addr:         kafka.TCP(brokerList...),
+			Topic:        *sourceTopic,
+			RequiredAcks: kafka.RequireOne,
+		}
+		quarantineWriter = &kafka.Writer{
+			Addr:         kafka.TCP(brokerList...),
+			Topic:        *quarantineTopic,
+			RequiredAcks: kafka.RequireOne,
+		}
+		if transport != nil {
+			writer.Transport = transport
+			quarantineWriter.Transport = transport
+		}
+		defer writer.Close()
+		defer quarantineWriter.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *readTimeout)
+	defer cancel()
+
+	var inspected, matched, replayed, quarantined int
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			break // context deadline: no more messages within -read-timeout
+		}
+		inspected++
+
+		var doc dlq.FailedDocument
+		if err := json.Unmarshal(msg.Value, &doc); err != nil {
+			log.Printf("dlqtool: skipping message at offset %d: %v", msg.Offset, err)
+			continue
+		}
+		if !matches(doc, *errorType, sinceTime, untilTime) {
+			continue
+		}
+		matched++
+		printSummary(doc)
+
+		if !*replay {
+			continue
+		}
+
+		if *maxRetries > 0 && doc.RetryCount >= *maxRetries {
+			if err := quarantineWriter.WriteMessages(context.Background(), kafka.Message{
+				Key:     []byte(doc.DocumentID),
+				Value:   msg.Value,
+				Headers: dlq.HeadersToKafka(doc.Headers),
+			}); err != nil {
+				log.Printf("dlqtool: failed to quarantine document %s: %v", doc.DocumentID, err)
+				continue
+			}
+			if err := reader.CommitMessages(context.Background(), msg); err != nil {
+				log.Printf("dlqtool: failed to commit offset for document %s: %v", doc.DocumentID, err)
+				continue
+			}
+			quarantined++
+			continue
+		}
+
+		// Headers carries doc's retry bookkeeping (dlq.RetryCountHeader,
+		// dlq.ErrorHistoryHeader) forward onto the replayed message, so
+		// onIndexFailure sees this document's accumulated retry state if
+		// it fails again instead of starting over from zero.
+		if err := writer.WriteMessages(context.Background(), kafka.Message{
+			Key:     []byte(doc.DocumentID),
+			Value:   doc.Payload,
+			Headers: dlq.HeadersToKafka(doc.Headers),
+		}); err != nil {
+			log.Printf("dlqtool: failed to replay document %s: %v", doc.DocumentID, err)
+			continue
+		}
+		if err := reader.CommitMessages(context.Background(), msg); err != nil {
+			log.Printf("dlqtool: failed to commit offset for document %s: %v", doc.DocumentID, err)
+			continue
+		}
+		replayed++
+	}
+
+	mode := "dry-run"
+	if *replay {
+		mode = "replay"
+	}
+	fmt.Printf("dlqtool: mode=%s inspected=%d matched=%d replayed=%d quarantined=%d\n", mode, inspected, matched, replayed, quarantined)
+}
+
+// matches reports whether doc satisfies all of the given filters. A zero
+// time.Time for since/until means that bound is unset.
+func matches(doc dlq.FailedDocument, errorType string, since, until time.Time) bool {
+	if errorType != "" && doc.ErrorType != errorType {
+		return false
+	}
+	if !since.IsZero() && doc.FailedAt.Before(since) {
+		return false
+	}
+	if !until.IsZero() && doc.FailedAt.After(until) {
+		return false
+	}
+	return true
+}
+
+func printSummary(doc dlq.FailedDocument) {
+	fmt.Printf("%s\terror=%s\treason=%s\tretry_count=%d\tfailed_at=%s\n",
+		doc.DocumentID, doc.ErrorType, doc.Reason, doc.RetryCount, doc.FailedAt.Format(time.RFC3339))
+}
@@ -0,0 +1,450 @@
+// Command audit consumes transaction-created events from Kafka and indexes
+// them into Elasticsearch for search and audit trails.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/accountlookup"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dispatch"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/elasticsearch"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/httpapi"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/ingest"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/kafkaauth"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/offsettracker"
+)
+
+// readErrorsTotal counts non-context-cancellation errors returned by the
+// Kafka reader, so a broker outage shows up in metrics and not just logs.
+var readErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "audit_kafka_read_errors_total",
+	Help: "Total number of errors returned by the Kafka reader's ReadMessage, excluding context cancellation.",
+})
+
+// readBackoff controls how long consume waits between consecutive read
+// errors. It resets to BaseDelay after any successful read.
+var readBackoff = struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}{BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+// backpressurePollInterval is how often consume rechecks the indexer's
+// in-flight count while paused waiting for it to drain.
+var backpressurePollInterval = 250 * time.Millisecond
+
+// backpressurePausedTotal counts how many times consume paused reading
+// from Kafka because the Elasticsearch indexer's in-flight count reached
+// its configured high watermark.
+var backpressurePausedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "audit_backpressure_paused_total",
+	Help: "Total number of times the Kafka consumer paused reading because the Elasticsearch indexer's in-flight count reached its high watermark.",
+})
+
+func main() {
+	cfg := config.Load()
+
+	kafkaAuth := kafkaauth.LoadFromEnv()
+	if err := kafkaAuth.Validate(); err != nil {
+		log.Fatalf("audit: %v", err)
+	}
+	kafkaTransport, err := kafkaAuth.Transport()
+	if err != nil {
+		log.Fatalf("audit: failed to build kafka transport: %v", err)
+	}
+	kafkaDialer, err := kafkaAuth.Dialer()
+	if err != nil {
+		log.Fatalf("audit: failed to build kafka dialer: %v", err)
+	}
+
+	dlqSink, closeDLQSink, err := newDLQSink(cfg, kafkaTransport)
+	if err != nil {
+		log.Fatalf("audit: failed to create DLQ sink: %v", err)
+	}
+
+	quarantineSink, closeQuarantineSink, err := newQuarantineSink(cfg, kafkaTransport)
+	if err != nil {
+		log.Fatalf("audit: failed to create quarantine sink: %v", err)
+	}
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+		URL:              cfg.ESURL,
+		Index:            cfg.ESIndex,
+		Username:         cfg.ESUsername,
+		Password:         cfg.ESPassword,
+		SkipTLSVerify:    cfg.ESSkipTLSVerify,
+		CACertPath:       cfg.ESCACertPath,
+		TLSMinVersion:    cfg.ESTLSMinVersion,
+		TLSCipherSuites:  cfg.ESTLSCipherSuites,
+		MaxRetries:       cfg.ESMaxRetries,
+		RetryOnStatus:    cfg.ESRetryOnStatus,
+		RequestTimeout:   cfg.ESRequestTimeout,
+		MaxDocumentBytes: cfg.ESMaxDocumentBytes,
+		RefreshInterval:  cfg.ESRefreshInterval,
+		DLQSink:          dlqSink,
+		DLQMaxRetries:    cfg.DLQMaxRetries,
+		QuarantineSink:   quarantineSink,
+	})
+	if err != nil {
+		log.Fatalf("audit: failed to create elasticsearch client: %v", err)
+	}
+
+	startOffset, err := kafkaStartOffset(cfg.KafkaStartOffset)
+	if err != nil {
+		log.Fatalf("audit: %v", err)
+	}
+
+	readerConfig := kafka.ReaderConfig{
+		Brokers: cfg.KafkaBrokers,
+		GroupID: cfg.KafkaGroupID,
+		Dialer:  kafkaDialer,
+		// StartOffset only matters the first time KafkaGroupID reads a
+		// partition with no committed offset yet -- see
+		// config.Config.KafkaStartOffset.
+		StartOffset: startOffset,
+		// Commit explicitly, only once Elasticsearch has confirmed a
+		// message's document is indexed (or permanently failed and sent to
+		// the DLQ) -- see resolveOffset. Messages are sharded across
+		// worker goroutines by key rather than by partition, so they can
+		// resolve out of order; offsetTracker withholds a commit until
+		// every earlier offset on the same partition has also resolved,
+		// so a crash can never skip past a message that wasn't actually
+		// confirmed, including one caught mid-processing by a rebalance
+		// reassigning its partition elsewhere.
+		CommitInterval: 0,
+	}
+	// GroupTopics subscribes the consumer group to several topics at once
+	// (e.g. transactions, reversals, account lifecycle), so one audit
+	// service can consume all of them under a single group instead of one
+	// process per topic. Topic is kafka-go's field for the single-topic
+	// case; the two are mutually exclusive, so the single-topic
+	// configuration (the common case) keeps using the field it always
+	// has rather than switching every deployment onto GroupTopics.
+	if len(cfg.KafkaTopics) == 1 {
+		readerConfig.Topic = cfg.KafkaTopics[0]
+	} else {
+		readerConfig.GroupTopics = cfg.KafkaTopics
+	}
+	reader := kafka.NewReader(readerConfig)
+
+	healthServer, err := httpapi.New(cfg.HealthAddr, esClient, dlqSink, cfg.FailureRateThreshold, cfg.AdminAllowedCIDRs, cfg.AdminToken, cfg.PprofEnabled, reader, cfg.KafkaBrokers, kafkaDialer)
+	if err != nil {
+		log.Fatalf("audit: failed to create health server: %v", err)
+	}
+
+	var resolver accountlookup.Resolver
+	if cfg.EnrichmentEnabled {
+		resolver, err = accountlookup.NewGRPCResolver(cfg.LedgerCoreAddr, cfg.AccountCacheSize)
+		if err != nil {
+			log.Fatalf("audit: failed to create account resolver: %v", err)
+		}
+	}
+	go func() {
+		if err := healthServer.Start(); err != nil {
+			log.Printf("audit: health server error: %v", err)
+		}
+	}()
+
+	idemCache := idempotency.NewCache(cfg.IdempotencyCacheSize, cfg.IdempotencyWindow)
+	offsetTracker := offsettracker.New()
+	resolveOffset := makeOffsetResolver(reader, offsetTracker)
+
+	processor := &ingest.Processor{ES: esClient, Resolver: resolver, DLQ: dlqSink, Idempotency: idemCache}
+	pool := dispatch.NewPool(cfg.WorkerCount, func(ctx context.Context, msg kafka.Message) error {
+		return processor.Process(ctx, msg, resolveOffset)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("audit: consuming topics=%v group=%s brokers=%v workers=%d", cfg.KafkaTopics, cfg.KafkaGroupID, cfg.KafkaBrokers, cfg.WorkerCount)
+	consume(ctx, reader, pool, offsetTracker, esClient, cfg.BackpressureHighWatermark, cfg.BackpressureLowWatermark)
+
+	log.Printf("audit: shutting down")
+	pool.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Order matters here: esClient.Close flushes the bulk indexer, which
+	// can synchronously send failed items to dlqSink before it returns, so
+	// the DLQ sink must not be closed until that flush is done. The
+	// in-flight count is logged before Close so shutdown logs show what the
+	// flush had to drain, even if it times out partway through.
+	log.Printf("audit: %d item(s) in flight at shutdown", esClient.InFlight())
+
+	if err := esClient.Close(shutdownCtx); err != nil {
+		log.Printf("audit: error closing elasticsearch client: %v", err)
+	}
+	if err := reader.Close(); err != nil {
+		log.Printf("audit: error closing kafka reader: %v", err)
+	}
+	if err := closeDLQSink(); err != nil {
+		log.Printf("audit: error closing DLQ sink: %v", err)
+	}
+	if err := closeQuarantineSink(); err != nil {
+		log.Printf("audit: error closing quarantine sink: %v", err)
+	}
+	if err := healthServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("audit: error shutting down health server: %v", err)
+	}
+}
+
+// offsetCommitter is the subset of *kafka.Reader that makeOffsetResolver
+// depends on, so tests can exercise the commit-withholding behavior
+// without a live consumer group.
+type offsetCommitter interface {
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// makeOffsetResolver returns a function that marks a partition/offset pair
+// as resolved in tracker and, if that advances the partition's commit
+// watermark, commits it on reader. It's meant to be called from wherever a
+// message's processing outcome is finally decided -- including
+// asynchronously, from an Elasticsearch bulk indexer callback -- rather
+// than right after Submit, which only means the message was handed off.
+func makeOffsetResolver(reader offsetCommitter, tracker *offsettracker.Tracker) func(partition int, offset int64) {
+	return func(partition int, offset int64) {
+		watermark, ok := tracker.Confirm(partition, offset)
+		if !ok {
+			return
+		}
+		commit := kafka.Message{Partition: partition, Offset: watermark}
+		if err := reader.CommitMessages(context.Background(), commit); err != nil {
+			log.Printf("audit: failed to commit offset %d for partition %d: %v", watermark, partition, err)
+		}
+	}
+}
+
+// kafkaStartOffset maps cfg.KafkaStartOffset's "earliest"/"latest" to the
+// kafka.ReaderConfig.StartOffset constant it corresponds to.
+func kafkaStartOffset(value string) (int64, error) {
+	switch value {
+	case "earliest":
+		return kafka.FirstOffset, nil
+	case "latest":
+		return kafka.LastOffset, nil
+	default:
+		return 0, fmt.Errorf("unsupported KAFKA_START_OFFSET %q (want \"earliest\" or \"latest\")", value)
+	}
+}
+
+// dlqProducerAcks maps cfg.DLQProducerAcks' "one"/"all"/"none" to the
+// kafka.RequiredAcks constant it corresponds to, the same mapping pattern
+// kafkaStartOffset uses for KafkaStartOffset.
+func dlqProducerAcks(value string) (kafka.RequiredAcks, error) {
+	switch value {
+	case "one", "":
+		return kafka.RequireOne, nil
+	case "all":
+		return kafka.RequireAll, nil
+	case "none":
+		return kafka.RequireNone, nil
+	default:
+		return 0, fmt.Errorf("unsupported DLQ_PRODUCER_ACKS %q (want \"one\", \"all\", or \"none\")", value)
+	}
+}
+
+// dlqProducerMetrics is shared by every dlq.Producer the audit process
+// builds (the regular DLQ sink and the quarantine sink, when either is
+// "kafka"), so a document's error_type/source_topic counts the same way
+// regardless of which topic it ended up on.
+var dlqProducerMetrics = dlq.NewProducerMetrics(prometheus.DefaultRegisterer)
+
+// dlqProducerConfig builds the dlq.ProducerConfig the "kafka" DLQ and
+// quarantine sinks are constructed with, from cfg's DLQProducer* settings.
+func dlqProducerConfig(cfg *config.Config) (dlq.ProducerConfig, error) {
+	acks, err := dlqProducerAcks(cfg.DLQProducerAcks)
+	if err != nil {
+		return dlq.ProducerConfig{}, err
+	}
+	return dlq.ProducerConfig{
+		RequiredAcks: acks,
+		BatchSize:    cfg.DLQProducerBatchSize,
+		BatchTimeout: cfg.DLQProducerBatchTimeout,
+		Metrics:      dlqProducerMetrics,
+	}, nil
+}
+
+// newDLQSink builds the dlq.DeadLetterSink selected by cfg.DLQSinkType and
+// a no-op-safe close function for it.
+func newDLQSink(cfg *config.Config, kafkaTransport *kafka.Transport) (dlq.DeadLetterSink, func() error, error) {
+	switch cfg.DLQSinkType {
+	case "file":
+		sink, err := dlq.NewFileSink(cfg.DLQFilePath, cfg.DLQFileMaxBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sink, sink.Close, nil
+	case "kafka", "":
+		producerCfg, err := dlqProducerConfig(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		sink := dlq.NewProducer(cfg.KafkaBrokers, cfg.KafkaDLQTopic, kafkaTransport, producerCfg)
+		return sink, sink.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("audit: unsupported DLQ_SINK_TYPE %q", cfg.DLQSinkType)
+	}
+}
+
+// newQuarantineSink builds the dlq.DeadLetterSink a document lands on once
+// it exceeds cfg.DLQMaxRetries, wrapped in dlq.QuarantineSink so a
+// crash-and-redeliver doesn't quarantine it twice, and a no-op-safe close
+// function for it. It mirrors newDLQSink's sink-type switch, writing to a
+// separate topic/file so onIndexFailure's quarantined documents don't
+// reappear in the regular DLQ a reprocessor reads from.
+func newQuarantineSink(cfg *config.Config, kafkaTransport *kafka.Transport) (dlq.DeadLetterSink, func() error, error) {
+	var inner interface {
+		dlq.DeadLetterSink
+		Close() error
+	}
+	switch cfg.QuarantineSinkType {
+	case "file":
+		sink, err := dlq.NewFileSink(cfg.QuarantineFilePath, cfg.QuarantineFileMaxBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		inner = sink
+	case "kafka", "":
+		producerCfg, err := dlqProducerConfig(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		inner = dlq.NewProducer(cfg.KafkaBrokers, cfg.QuarantineTopic, kafkaTransport, producerCfg)
+	default:
+		return nil, nil, fmt.Errorf("audit: unsupported QUARANTINE_SINK_TYPE %q", cfg.QuarantineSinkType)
+	}
+	sink := dlq.NewQuarantineSink(inner, cfg.QuarantineDedupCacheSize, cfg.QuarantineDedupWindow)
+	return sink, inner.Close, nil
+}
+
+// messageReader is the subset of *kafka.Reader that consume depends on,
+// so tests can exercise the backoff behavior with a fake.
+type messageReader interface {
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+}
+
+// backpressureGauge is the subset of *elasticsearch.Client that consume
+// depends on to sense indexer backpressure, so tests can exercise the
+// pause/resume behavior with a fake instead of a live Elasticsearch
+// cluster.
+type backpressureGauge interface {
+	InFlight() int64
+}
+
+// consume runs the main read loop until ctx is canceled, handing each
+// message to pool for concurrent, per-key-ordered processing. Consecutive
+// read errors are separated by an exponential backoff, bounded by
+// readBackoff.MaxDelay and reset to readBackoff.BaseDelay after any
+// successful read. Context cancellation always exits immediately, without
+// waiting out a pending backoff; the caller is responsible for draining
+// pool afterward.
+//
+// Before each read, consume calls waitForCapacity so that a slow or
+// failing Elasticsearch cluster pauses Kafka reads rather than letting
+// them keep piling failures into the DLQ -- see highWatermark/
+// lowWatermark's doc comments on config.Config for the thresholds this
+// applies.
+//
+// Consumer group rebalances (a partition being assigned elsewhere) happen
+// transparently inside reader.ReadMessage; segmentio/kafka-go doesn't
+// expose a revoke/assign callback consume could hook to pause reads ahead
+// of one. Instead, correctness during a rebalance rests entirely on the
+// offset commit scheme: a partition is only ever committed up to
+// offsetTracker's contiguous watermark, which only advances once
+// Elasticsearch has actually confirmed (or permanently failed and
+// DLQ'd) every earlier message on it -- see makeOffsetResolver and
+// ingest.Processor.Process. So whenever the rebalance actually lands, whatever
+// hasn't been committed yet is, by construction, still unconfirmed, and
+// the new partition owner reprocesses it rather than losing it. The cost
+// is the usual at-least-once one: a message already indexed but not yet
+// committed can be reprocessed, which IndexTransaction's external
+// versioning and idemCache both already tolerate.
+//
+// consume calls tracker.Observe for each message right after reading it
+// and before handing it to pool, so the tracker's notion of a partition's
+// earliest in-flight offset reflects read order rather than whatever
+// order workers happen to finish in -- see offsettracker.Tracker.Observe.
+func consume(ctx context.Context, reader messageReader, pool *dispatch.Pool, tracker *offsettracker.Tracker, gauge backpressureGauge, highWatermark, lowWatermark int64) {
+	delay := readBackoff.BaseDelay
+	for {
+		if err := waitForCapacity(ctx, gauge, highWatermark, lowWatermark); err != nil {
+			return
+		}
+
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			readErrorsTotal.Inc()
+			log.Printf("audit: error reading message, backing off %s: %v", delay, err)
+			if !sleepOrDone(ctx, delay) {
+				return
+			}
+			delay *= 2
+			if delay > readBackoff.MaxDelay {
+				delay = readBackoff.MaxDelay
+			}
+			continue
+		}
+		delay = readBackoff.BaseDelay
+		tracker.Observe(msg.Partition, msg.Offset)
+
+		if err := pool.Submit(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("audit: error submitting message at offset %d: %v", msg.Offset, err)
+		}
+	}
+}
+
+// waitForCapacity blocks until gauge's in-flight count drops to or below
+// lowWatermark, polling every backpressurePollInterval. It returns
+// immediately, without polling at all, if highWatermark is <= 0
+// (backpressure disabled) or gauge hasn't yet reached highWatermark. It
+// returns a non-nil error only when ctx is canceled while waiting, which
+// the caller should treat as a signal to stop consuming rather than an
+// error to log.
+func waitForCapacity(ctx context.Context, gauge backpressureGauge, highWatermark, lowWatermark int64) error {
+	if highWatermark <= 0 || gauge.InFlight() < highWatermark {
+		return nil
+	}
+
+	log.Printf("audit: pausing kafka reads, indexer in-flight count reached high watermark (%d)", highWatermark)
+	backpressurePausedTotal.Inc()
+	for gauge.InFlight() > lowWatermark {
+		if !sleepOrDone(ctx, backpressurePollInterval) {
+			return ctx.Err()
+		}
+	}
+	log.Printf("audit: resuming kafka reads, indexer in-flight count at or below low watermark (%d)", lowWatermark)
+	return nil
+}
+
+// sleepOrDone waits for d or until ctx is canceled, whichever comes first.
+// It reports whether the wait completed without cancellation.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
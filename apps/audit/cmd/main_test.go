@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/offsettracker"
+)
+
+// fakeReader returns errReadFailed for the first failCount calls, then
+// succeeds with an empty message and blocks (via context) afterward.
+type fakeReader struct {
+	failCount int
+	calls     []time.Time
+}
+
+var errReadFailed = errors.New("fake: read failed")
+
+func (f *fakeReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	f.calls = append(f.calls, time.Now())
+	if len(f.calls) <= f.failCount {
+		return kafka.Message{}, errReadFailed
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func TestConsumeBacksOffOnRepeatedReadErrors(t *testing.T) {
+	origBase, origMax := readBackoff.BaseDelay, readBackoff.MaxDelay
+	readBackoff.BaseDelay = 10 * time.Millisecond
+	readBackoff.MaxDelay = 200 * time.Millisecond
+	defer func() {
+		readBackoff.BaseDelay, readBackoff.MaxDelay = origBase, origMax
+	}()
+
+	reader := &fakeReader{failCount: 4}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	consume(ctx, reader, nil, offsettracker.New(), nil, 0, 0)
+
+	if len(reader.calls) < reader.failCount+1 {
+		t.Fatalf("expected at least %d read attempts, got %d", reader.failCount+1, len(reader.calls))
+	}
+
+	var gaps []time.Duration
+	for i := 1; i <= reader.failCount; i++ {
+		gaps = append(gaps, reader.calls[i].Sub(reader.calls[i-1]))
+	}
+	for i := 1; i < len(gaps); i++ {
+		if gaps[i] < gaps[i-1] {
+			t.Fatalf("expected backoff to grow (or plateau at max), gap %d (%s) < gap %d (%s)", i, gaps[i], i-1, gaps[i-1])
+		}
+	}
+	if gaps[0] < readBackoff.BaseDelay {
+		t.Fatalf("expected first backoff gap >= base delay %s, got %s", readBackoff.BaseDelay, gaps[0])
+	}
+}
+
+// fakeCommitter records every CommitMessages call, so a test can assert on
+// exactly which offsets were committed and in what order.
+type fakeCommitter struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+}
+
+func (f *fakeCommitter) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+// TestOffsetResolverWithholdsCommitDuringReassignment simulates a
+// partition being reassigned mid-backlog: two in-flight messages (offsets
+// 5 and 6) finish out of order because their worker goroutines' calls
+// into Elasticsearch complete out of order, which is exactly what a
+// rebalance reassigning the partition to another consumer can't be
+// allowed to race past. The later offset resolving first must not commit
+// anything -- only once the earlier one also resolves should the
+// watermark (and the commit) advance to cover both.
+func TestOffsetResolverWithholdsCommitDuringReassignment(t *testing.T) {
+	committer := &fakeCommitter{}
+	tracker := offsettracker.New()
+	resolve := makeOffsetResolver(committer, tracker)
+
+	// The reader sees 5 then 6, in that order, before either is dispatched;
+	// their worker goroutines then resolve out of order.
+	tracker.Observe(0, 5)
+	tracker.Observe(0, 6)
+
+	resolve(0, 6)
+	if len(committer.msgs) != 0 {
+		t.Fatalf("expected no commit while offset 5 is still unresolved, got %v", committer.msgs)
+	}
+
+	resolve(0, 5)
+	if len(committer.msgs) != 1 {
+		t.Fatalf("expected exactly one commit once the gap closed, got %v", committer.msgs)
+	}
+	if committer.msgs[0].Partition != 0 || committer.msgs[0].Offset != 6 {
+		t.Fatalf("expected a commit for partition 0 offset 6, got %+v", committer.msgs[0])
+	}
+}
+
+// fakeGauge is a backpressureGauge whose InFlight value can be changed
+// mid-test, so a test can simulate the indexer draining while consume is
+// paused waiting for it.
+type fakeGauge struct {
+	mu       sync.Mutex
+	inFlight int64
+}
+
+func (f *fakeGauge) InFlight() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.inFlight
+}
+
+func (f *fakeGauge) set(v int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inFlight = v
+}
+
+func TestWaitForCapacity_DisabledWhenHighWatermarkIsZero(t *testing.T) {
+	gauge := &fakeGauge{inFlight: 1000}
+	if err := waitForCapacity(context.Background(), gauge, 0, 0); err != nil {
+		t.Fatalf("waitForCapacity: %v", err)
+	}
+}
+
+func TestWaitForCapacity_ReturnsImmediatelyBelowHighWatermark(t *testing.T) {
+	gauge := &fakeGauge{inFlight: 5}
+	if err := waitForCapacity(context.Background(), gauge, 10, 2); err != nil {
+		t.Fatalf("waitForCapacity: %v", err)
+	}
+}
+
+func TestWaitForCapacity_BlocksUntilGaugeDrainsToLowWatermark(t *testing.T) {
+	origInterval := backpressurePollInterval
+	backpressurePollInterval = 10 * time.Millisecond
+	defer func() { backpressurePollInterval = origInterval }()
+
+	gauge := &fakeGauge{inFlight: 100}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		gauge.set(2)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		if err := waitForCapacity(context.Background(), gauge, 10, 5); err != nil {
+			t.Errorf("waitForCapacity: %v", err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waitForCapacity did not return after the gauge drained")
+	}
+}
+
+func TestWaitForCapacity_ContextCancelDuringPauseReturnsError(t *testing.T) {
+	origInterval := backpressurePollInterval
+	backpressurePollInterval = 10 * time.Millisecond
+	defer func() { backpressurePollInterval = origInterval }()
+
+	gauge := &fakeGauge{inFlight: 100}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := waitForCapacity(ctx, gauge, 10, 5); err == nil {
+		t.Fatal("waitForCapacity: want error on context cancellation, got nil")
+	}
+}
+
+func TestConsumeExitsImmediatelyOnContextCancel(t *testing.T) {
+	readBackoff.BaseDelay = 50 * time.Millisecond
+	readBackoff.MaxDelay = time.Second
+
+	reader := &fakeReader{failCount: 1000}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		consume(ctx, reader, nil, offsettracker.New(), nil, 0, 0)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("consume did not return promptly after context cancellation")
+	}
+}
@@ -0,0 +1,315 @@
+// Command reindextool replays a bounded range of a topic's messages
+// through the audit pipeline into Elasticsearch, without touching the
+// live consumer group. It's the disaster-recovery path for re-running a
+// window of events after fixing an ES mapping bug or rebuilding an index
+// from scratch: point it at the affected offset or time range and an
+// optional -target-index, and it indexes exactly what the live consumer
+// would have, via the same internal/ingest.Processor.
+//
+// The range may be given as offsets (-from-offset/-to-offset) or
+// timestamps (-since/-until), but not a mix of both. Each bound is
+// applied independently per partition; "to" and "until" are open-ended,
+// meaning "read to the partition's current end" or "no upper time bound"
+// respectively, not "unbounded and keep following new writes" -- the end
+// offset is captured once at startup, so the tool always terminates.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/accountlookup"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/elasticsearch"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/ingest"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/kafkaauth"
+)
+
+func main() {
+	var (
+		brokers     = flag.String("brokers", "localhost:9092", "comma-separated Kafka broker addresses")
+		topic       = flag.String("topic", "transaction-events", "topic to reindex from")
+		fromOffset  = flag.Int64("from-offset", -1, "first offset to reindex, per partition (mutually exclusive with -since/-until)")
+		toOffset    = flag.Int64("to-offset", -1, "last offset to reindex, per partition, inclusive (default: each partition's current end)")
+		since       = flag.String("since", "", "only reindex messages with a timestamp on or after this RFC3339 time (mutually exclusive with -from-offset/-to-offset)")
+		until       = flag.String("until", "", "only reindex messages with a timestamp before this RFC3339 time (default: no upper bound)")
+		dlqTopic    = flag.String("dlq-topic", "", "topic to send indexing failures to (default: disabled, failures are only logged)")
+		targetIndex = flag.String("target-index", "", "Elasticsearch index to reindex into (default: -es-index)")
+
+		esURL             = flag.String("es-url", "http://localhost:9200", "Elasticsearch URL")
+		esIndex           = flag.String("es-index", "transactions", "Elasticsearch index (overridden by -target-index)")
+		esUsername        = flag.String("es-username", "", "Elasticsearch username")
+		esPassword        = flag.String("es-password", "", "Elasticsearch password")
+		esSkipTLSVerify   = flag.Bool("es-skip-tls-verify", false, "skip Elasticsearch server certificate verification")
+		esCACertPath      = flag.String("es-ca-cert-path", "", "PEM file trusted when verifying the Elasticsearch cluster's certificate")
+		esTLSMinVersion   = flag.String("es-tls-min-version", "", `minimum TLS version for Elasticsearch, "1.2" or "1.3" (default: "1.2")`)
+		esTLSCipherSuites = flag.String("es-tls-cipher-suites", "", "comma-separated allowlist of Elasticsearch cipher suite names (default: tlsconfig.DefaultCipherSuites)")
+		esMaxRetries      = flag.Int("es-max-retries", 3, "Elasticsearch client retry count")
+		esRequestTimeout  = flag.Duration("es-request-timeout", 10*time.Second, "timeout for direct Elasticsearch calls")
+
+		enrich           = flag.Bool("enrich", false, "populate account owner/currency via a ledger-core lookup, same as ENRICHMENT_ENABLED on the live consumer")
+		ledgerCoreAddr   = flag.String("ledger-core-addr", "", "ledger-core gRPC address, required when -enrich is set")
+		accountCacheSize = flag.Int("account-cache-size", 1024, "account lookup LRU cache size, used when -enrich is set")
+
+		idempotencyCacheSize = flag.Int("idempotency-cache-size", 4096, "idempotency dedup cache size, guards against a message appearing twice within the reindexed range")
+		idempotencyWindow    = flag.Duration("idempotency-window", 10*time.Minute, "idempotency dedup window")
+
+		disableRefreshDuringBackfill = flag.Bool("disable-refresh-during-backfill", false, "set the index's refresh_interval to -1 for the run, restoring it (and forcing a final _refresh) on completion; speeds up large reindexes at the cost of searchability until it finishes")
+		restoreRefreshInterval       = flag.String("restore-refresh-interval", elasticsearch.DefaultRefreshInterval, "refresh_interval to restore once -disable-refresh-during-backfill completes")
+	)
+	flag.Parse()
+
+	usingOffsets := *fromOffset >= 0 || *toOffset >= 0
+	usingTimestamps := *since != "" || *until != ""
+	if usingOffsets && usingTimestamps {
+		log.Fatalf("reindextool: -from-offset/-to-offset and -since/-until are mutually exclusive")
+	}
+	if !usingOffsets && !usingTimestamps {
+		log.Fatalf("reindextool: one of -from-offset or -since is required")
+	}
+
+	var sinceTime, untilTime time.Time
+	var err error
+	if *since != "" {
+		sinceTime, err = time.Parse(time.RFC3339, *since)
+		if err != nil {
+			log.Fatalf("reindextool: invalid -since: %v", err)
+		}
+	}
+	if *until != "" {
+		untilTime, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			log.Fatalf("reindextool: invalid -until: %v", err)
+		}
+	}
+
+	if *enrich && *ledgerCoreAddr == "" {
+		log.Fatalf("reindextool: -enrich requires -ledger-core-addr")
+	}
+
+	kafkaAuth := kafkaauth.LoadFromEnv()
+	if err := kafkaAuth.Validate(); err != nil {
+		log.Fatalf("reindextool: %v", err)
+	}
+	dialer, err := kafkaAuth.Dialer()
+	if err != nil {
+		log.Fatalf("reindextool: failed to build kafka dialer: %v", err)
+	}
+	transport, err := kafkaAuth.Transport()
+	if err != nil {
+		log.Fatalf("reindextool: failed to build kafka transport: %v", err)
+	}
+
+	brokerList := strings.Split(*brokers, ",")
+
+	var dlqSink dlq.DeadLetterSink
+	if *dlqTopic != "" {
+		producer := dlq.NewProducer(brokerList, *dlqTopic, transport, dlq.DefaultProducerConfig())
+		defer producer.Close()
+		dlqSink = producer
+	}
+
+	index := *esIndex
+	if *targetIndex != "" {
+		index = *targetIndex
+	}
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+		URL:             *esURL,
+		Index:           index,
+		Username:        *esUsername,
+		Password:        *esPassword,
+		SkipTLSVerify:   *esSkipTLSVerify,
+		CACertPath:      *esCACertPath,
+		TLSMinVersion:   *esTLSMinVersion,
+		TLSCipherSuites: splitCSVFlag(*esTLSCipherSuites),
+		MaxRetries:      *esMaxRetries,
+		RetryOnStatus:   []int{502, 503, 504, 429},
+		RequestTimeout:  *esRequestTimeout,
+		DLQSink:         dlqSink,
+	})
+	if err != nil {
+		log.Fatalf("reindextool: failed to create elasticsearch client: %v", err)
+	}
+
+	var resolver accountlookup.Resolver
+	if *enrich {
+		resolver, err = accountlookup.NewGRPCResolver(*ledgerCoreAddr, *accountCacheSize)
+		if err != nil {
+			log.Fatalf("reindextool: failed to create account resolver: %v", err)
+		}
+	}
+
+	processor := &ingest.Processor{
+		ES:          esClient,
+		Resolver:    resolver,
+		DLQ:         dlqSink,
+		Idempotency: idempotency.NewCache(*idempotencyCacheSize, *idempotencyWindow),
+	}
+
+	ctx := context.Background()
+
+	if *disableRefreshDuringBackfill {
+		if err := esClient.SetRefreshInterval(ctx, "-1"); err != nil {
+			log.Fatalf("reindextool: failed to disable refresh for the backfill: %v", err)
+		}
+		log.Printf("reindextool: disabled refresh_interval for the backfill; will restore it to %s and force a refresh on completion", *restoreRefreshInterval)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", brokerList[0])
+	if err != nil {
+		log.Fatalf("reindextool: failed to dial %s: %v", brokerList[0], err)
+	}
+	partitions, err := conn.ReadPartitions(*topic)
+	conn.Close()
+	if err != nil {
+		log.Fatalf("reindextool: failed to read partitions for topic %s: %v", *topic, err)
+	}
+
+	var processed, failed int64
+	var wg sync.WaitGroup
+	for _, p := range partitions {
+		partition := p.ID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, errs := reindexPartition(ctx, processor, dialer, brokerList, *topic, partition, *fromOffset, *toOffset, sinceTime, untilTime)
+			atomic.AddInt64(&processed, n)
+			atomic.AddInt64(&failed, errs)
+		}()
+	}
+	wg.Wait()
+
+	if *disableRefreshDuringBackfill {
+		if err := esClient.SetRefreshInterval(ctx, *restoreRefreshInterval); err != nil {
+			log.Printf("reindextool: failed to restore refresh_interval to %s: %v", *restoreRefreshInterval, err)
+		} else if err := esClient.Refresh(ctx); err != nil {
+			log.Printf("reindextool: failed to force a final refresh: %v", err)
+		}
+	}
+
+	fmt.Printf("reindextool: topic=%s index=%s partitions=%d processed=%d failed=%d\n", *topic, index, len(partitions), processed, failed)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := esClient.Close(shutdownCtx); err != nil {
+		log.Printf("reindextool: error closing elasticsearch client: %v", err)
+	}
+}
+
+// splitCSVFlag splits a comma-separated flag value into its entries,
+// trimming whitespace around each and dropping empty ones, so an unset
+// flag yields nil rather than a slice holding one empty string.
+func splitCSVFlag(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// reindexPartition reads partition on topic between a start and end
+// offset resolved from either the offset or timestamp flags, running
+// every message in range through processor. Offset commits are never
+// made -- this reader has no group ID and is discarded once the range is
+// exhausted -- so a rerun with the same bounds reindexes the same
+// messages again. It returns the number of messages processed and the
+// number that processor.Process returned an error for; those are logged
+// and counted, not fatal, so one bad message doesn't abort the rest of
+// the range.
+func reindexPartition(ctx context.Context, processor *ingest.Processor, dialer *kafka.Dialer, brokers []string, topic string, partition int, fromOffset, toOffset int64, since, until time.Time) (processed, failed int64) {
+	start, end, err := resolveRange(ctx, dialer, brokers[0], topic, partition, fromOffset, toOffset, since, until)
+	if err != nil {
+		log.Printf("reindextool: partition %d: failed to resolve offset range: %v", partition, err)
+		return 0, 0
+	}
+	if start >= end {
+		return 0, 0
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   brokers,
+		Topic:     topic,
+		Partition: partition,
+		Dialer:    dialer,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(start); err != nil {
+		log.Printf("reindextool: partition %d: failed to seek to offset %d: %v", partition, start, err)
+		return 0, 0
+	}
+
+	noopResolve := func(int, int64) {}
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			log.Printf("reindextool: partition %d: error reading message: %v", partition, err)
+			return processed, failed
+		}
+		if msg.Offset >= end {
+			return processed, failed
+		}
+
+		if err := processor.Process(ctx, msg, noopResolve); err != nil {
+			log.Printf("reindextool: partition %d offset %d: %v", partition, msg.Offset, err)
+			failed++
+		}
+		processed++
+	}
+}
+
+// resolveRange turns the offset or timestamp flags into a concrete
+// [start, end) offset range for partition. end is exclusive in both
+// modes: ReadLastOffset already returns the next offset to be written,
+// and -to-offset (inclusive on the command line) is adjusted by one to
+// match.
+func resolveRange(ctx context.Context, dialer *kafka.Dialer, broker, topic string, partition int, fromOffset, toOffset int64, since, until time.Time) (start, end int64, err error) {
+	conn, err := dialer.DialLeader(ctx, "tcp", broker, topic, partition)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dial partition leader: %w", err)
+	}
+	defer conn.Close()
+
+	switch {
+	case !since.IsZero():
+		start, err = conn.ReadOffset(since)
+		if err != nil {
+			return 0, 0, fmt.Errorf("resolve -since offset: %w", err)
+		}
+	case fromOffset >= 0:
+		start = fromOffset
+	default:
+		return 0, 0, fmt.Errorf("no start bound given")
+	}
+
+	switch {
+	case !until.IsZero():
+		end, err = conn.ReadOffset(until)
+		if err != nil {
+			return 0, 0, fmt.Errorf("resolve -until offset: %w", err)
+		}
+	case toOffset >= 0:
+		end = toOffset + 1
+	default:
+		end, err = conn.ReadLastOffset()
+		if err != nil {
+			return 0, 0, fmt.Errorf("resolve partition end offset: %w", err)
+		}
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,77 @@
+// Package accountlookup resolves an account's owning user and currency via
+// ledger-core's GetAccount RPC, to enrich indexed audit documents with
+// metadata TransactionCreatedEvent doesn't carry. Lookups go through a
+// small LRU cache, since the accounts involved in the most transactions
+// are also the ones most often looked up.
+package accountlookup
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/ledgerpb"
+)
+
+// Info is what Resolve returns for an account.
+type Info struct {
+	OwnerUserID string
+	Currency    string
+}
+
+// Resolver resolves account metadata by account ID.
+type Resolver interface {
+	Resolve(ctx context.Context, accountID string) (Info, error)
+}
+
+// NewGRPCResolver dials ledger-core at addr and returns a Resolver backed
+// by its GetAccount RPC, wrapped with an LRU cache holding up to
+// cacheSize entries. The dial is non-blocking (grpc.NewClient does not
+// connect eagerly); the first lookup pays the connection-establishment
+// cost.
+func NewGRPCResolver(addr string, cacheSize int) (Resolver, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("accountlookup: dial %s: %w", addr, err)
+	}
+	base := &grpcResolver{client: ledgerpb.NewLedgerServiceClient(conn)}
+	return &cachingResolver{base: base, cache: newLRUCache(cacheSize)}, nil
+}
+
+// grpcResolver calls ledger-core's GetAccount RPC directly, with no
+// caching of its own -- that's cachingResolver's job.
+type grpcResolver struct {
+	client ledgerpb.LedgerServiceClient
+}
+
+func (r *grpcResolver) Resolve(ctx context.Context, accountID string) (Info, error) {
+	resp, err := r.client.GetAccount(ctx, &ledgerpb.GetAccountRequest{AccountId: accountID})
+	if err != nil {
+		return Info{}, fmt.Errorf("accountlookup: get account %s: %w", accountID, err)
+	}
+	return Info{OwnerUserID: resp.UserId, Currency: resp.Currency}, nil
+}
+
+// cachingResolver serves Resolve from an lruCache, falling through to base
+// on a miss. A failed lookup is not cached, so a transient ledger-core
+// error doesn't poison the cache for subsequent attempts at the same
+// account.
+type cachingResolver struct {
+	base  Resolver
+	cache *lruCache
+}
+
+func (r *cachingResolver) Resolve(ctx context.Context, accountID string) (Info, error) {
+	if info, ok := r.cache.get(accountID); ok {
+		return info, nil
+	}
+
+	info, err := r.base.Resolve(ctx, accountID)
+	if err != nil {
+		return Info{}, err
+	}
+	r.cache.set(accountID, info)
+	return info, nil
+}
@@ -0,0 +1,338 @@
+// Package config loads the audit service's runtime configuration from the
+// environment, applying sane defaults so the service is runnable against a
+// local Kafka/Elasticsearch stack with no env vars set.
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds every environment-tunable setting for the audit service.
+// Fields are added here as features need them; keep defaults backward
+// compatible.
+type Config struct {
+	KafkaBrokers []string
+	// KafkaTopics is the set of topics the consumer group subscribes to.
+	// A single entry is the common case; several let one audit service
+	// consume multiple event streams (e.g. transactions, reversals,
+	// account lifecycle) under one consumer group instead of running a
+	// separate process per topic. Each message's own Kafka topic -- not
+	// this list -- is what ends up in dlq.FailedDocument.SourceTopic, so
+	// a DLQ entry always names the topic it actually came from.
+	KafkaTopics   []string
+	KafkaDLQTopic string
+	KafkaGroupID  string
+	// KafkaStartOffset is "earliest" (the default) or "latest". It only
+	// takes effect the first time KafkaGroupID reads a given partition,
+	// i.e. when there's no committed offset for it yet -- once the group
+	// has committed an offset, the reader resumes from there regardless
+	// of this setting, the same as any other Kafka consumer group. Use
+	// "earliest" to backfill ES from the start of the topic with a fresh
+	// group ID, or "latest" to start a new group ID without reprocessing
+	// history.
+	KafkaStartOffset string
+
+	ESURL           string
+	ESIndex         string
+	ESUsername      string
+	ESPassword      string
+	ESSkipTLSVerify bool
+	// ESCACertPath, if set, is a PEM file trusted when verifying the
+	// cluster's certificate, on top of the system roots.
+	ESCACertPath string
+	// ESTLSMinVersion and ESTLSCipherSuites set the compliance baseline
+	// applied via tlsconfig.Build -- "1.2" (the default) or "1.3", and an
+	// allowlist of standard Go cipher suite names (default
+	// tlsconfig.DefaultCipherSuites).
+	ESTLSMinVersion   string
+	ESTLSCipherSuites []string
+	// ESMaxRetries and ESRetryOnStatus configure the ES client's built-in
+	// retry behavior; see elasticsearch.Config. ESRequestTimeout bounds
+	// calls the client makes directly against ES (Flush, EraseAccount).
+	ESMaxRetries     int
+	ESRetryOnStatus  []int
+	ESRequestTimeout time.Duration
+	// ESMaxDocumentBytes caps the marshaled size of a document sent to ES;
+	// see elasticsearch.Config.MaxDocumentBytes.
+	ESMaxDocumentBytes int
+	// ESRefreshInterval sets the index's settings.refresh_interval when
+	// it's created; see elasticsearch.Config.RefreshInterval. It has no
+	// effect on an index that already exists -- reindextool's
+	// -disable-refresh-during-backfill flag is the way to change refresh
+	// behavior on an existing index for a bulk backfill.
+	ESRefreshInterval string
+
+	// DLQSinkType selects where failed-to-index documents are sent:
+	// "kafka" (default) or "file".
+	DLQSinkType string
+	// DLQFilePath and DLQFileMaxBytes configure the "file" sink. A
+	// DLQFileMaxBytes of 0 disables rotation.
+	DLQFilePath     string
+	DLQFileMaxBytes int64
+	// DLQProducerAcks selects the ack level the "kafka" DLQ and
+	// quarantine sinks wait for before a write is considered successful:
+	// "one" (default, today's behavior), "all" for the strongest
+	// durability guarantee kafka-go exposes, or "none". See
+	// dlq.ProducerConfig.RequiredAcks for why this, not an "idempotent"
+	// setting, is the lever exposed here.
+	DLQProducerAcks string
+	// DLQProducerBatchSize and DLQProducerBatchTimeout bound how many
+	// messages, or how long, the "kafka" DLQ and quarantine sinks batch
+	// before flushing to the broker. Zero for either keeps kafka-go's own
+	// defaults (100 messages, 1s). See dlq.ProducerConfig.
+	DLQProducerBatchSize    int
+	DLQProducerBatchTimeout time.Duration
+
+	// DLQMaxRetries caps how many times a document may be sent to the DLQ
+	// sink before it's routed to the quarantine sink instead. 0 disables
+	// quarantining, so every failure keeps going back to the regular DLQ
+	// sink forever.
+	DLQMaxRetries int
+	// QuarantineSinkType, QuarantineTopic, QuarantineFilePath, and
+	// QuarantineFileMaxBytes configure the quarantine sink the same way
+	// DLQSinkType/DLQFilePath/DLQFileMaxBytes configure the regular one;
+	// see dlq.QuarantineSink.
+	QuarantineSinkType     string
+	QuarantineTopic        string
+	QuarantineFilePath     string
+	QuarantineFileMaxBytes int64
+	// QuarantineDedupCacheSize and QuarantineDedupWindow bound
+	// dlq.QuarantineSink's own idempotency cache, which keeps a crash-and-
+	// redeliver from quarantining the same document twice.
+	QuarantineDedupCacheSize int
+	QuarantineDedupWindow    time.Duration
+
+	HealthAddr           string
+	FailureRateThreshold float64
+
+	// ShutdownTimeout bounds the whole graceful-shutdown sequence
+	// (flushing the ES bulk indexer, closing the Kafka reader and DLQ
+	// sink, stopping the health server) once a SIGINT/SIGTERM is
+	// received.
+	ShutdownTimeout time.Duration
+
+	// AdminAllowedCIDRs restricts which networks may call the admin
+	// endpoints (/admin/flush, /admin/erase). Empty means no caller is
+	// allowed, so the endpoints are closed by default rather than open by
+	// default.
+	AdminAllowedCIDRs []string
+	// AdminToken additionally gates /admin/erase, on top of
+	// AdminAllowedCIDRs: it's destructive, so it requires both the caller's
+	// network and a shared secret to match. Empty fails every request
+	// closed.
+	AdminToken string
+
+	// PprofEnabled registers net/http/pprof's handlers under /debug/pprof
+	// on the health server, gated by AdminAllowedCIDRs like the other
+	// admin endpoints. Default off: a profiling endpoint left reachable
+	// by accident in production leaks goroutine stacks, heap contents,
+	// and request timing to anyone who can reach it.
+	PprofEnabled bool
+
+	// WorkerCount is the number of worker goroutines processing messages
+	// concurrently, via internal/dispatch.Pool.
+	WorkerCount int
+
+	// EnrichmentEnabled turns on the ledger-core GetAccount lookup that
+	// populates TransactionDocument's owner/currency fields. Off by
+	// default: it's an extra RPC dependency on the hot path, and not every
+	// deployment's analysts need it.
+	EnrichmentEnabled bool
+	// LedgerCoreAddr is the ledger-core gRPC address EnrichmentEnabled
+	// looks up accounts against.
+	LedgerCoreAddr string
+	// AccountCacheSize bounds the account lookup LRU cache's size. See
+	// accountlookup.NewGRPCResolver.
+	AccountCacheSize int
+
+	// IdempotencyCacheSize bounds how many idempotency keys the consumer
+	// remembers for duplicate detection. IdempotencyWindow bounds how long
+	// each one is remembered. See internal/idempotency.
+	IdempotencyCacheSize int
+	IdempotencyWindow    time.Duration
+
+	// BackpressureHighWatermark pauses Kafka reads once the Elasticsearch
+	// bulk indexer has this many documents in flight -- added but not yet
+	// flushed or failed, see elasticsearch.Client.InFlight -- so a slow or
+	// failing cluster can't be outrun into an unbounded DLQ flood. Zero
+	// (the default) disables backpressure, preserving the original
+	// read-at-full-speed behavior.
+	BackpressureHighWatermark int64
+	// BackpressureLowWatermark resumes Kafka reads once InFlight drops
+	// back to or below this level, after having paused at
+	// BackpressureHighWatermark. Ignored when BackpressureHighWatermark is
+	// 0.
+	BackpressureLowWatermark int64
+}
+
+func Load() *Config {
+	return &Config{
+		KafkaBrokers:     getEnvList("KAFKA_BROKERS", "localhost:9092"),
+		KafkaTopics:      getEnvList("KAFKA_TOPIC", "transaction-events"),
+		KafkaDLQTopic:    getEnv("KAFKA_DLQ_TOPIC", "transaction-events-dlq"),
+		KafkaGroupID:     getEnv("KAFKA_GROUP_ID", "audit-service-group"),
+		KafkaStartOffset: getEnv("KAFKA_START_OFFSET", "earliest"),
+
+		ESURL:           getEnv("ES_URL", "http://localhost:9200"),
+		ESIndex:         getEnv("ES_INDEX", "transactions"),
+		ESUsername:      getEnv("ES_USERNAME", ""),
+		ESPassword:      getEnv("ES_PASSWORD", ""),
+		ESSkipTLSVerify: getEnvBool("ES_SKIP_TLS_VERIFY", false),
+		ESCACertPath:    getEnv("ES_CA_CERT_PATH", ""),
+
+		ESTLSMinVersion:   getEnv("ES_TLS_MIN_VERSION", ""),
+		ESTLSCipherSuites: getEnvList("ES_TLS_CIPHER_SUITES", ""),
+
+		ESMaxRetries:       getEnvInt("ES_MAX_RETRIES", 3),
+		ESRetryOnStatus:    getEnvIntList("ES_RETRY_ON_STATUS", []int{502, 503, 504, 429}),
+		ESRequestTimeout:   getEnvDuration("ES_REQUEST_TIMEOUT_MS", 10*time.Second),
+		ESMaxDocumentBytes: getEnvInt("ES_MAX_DOCUMENT_BYTES", 1<<20),
+		ESRefreshInterval:  getEnv("ES_REFRESH_INTERVAL", "1s"),
+
+		DLQSinkType:     getEnv("DLQ_SINK_TYPE", "kafka"),
+		DLQFilePath:     getEnv("DLQ_FILE_PATH", "dlq.jsonl"),
+		DLQFileMaxBytes: getEnvInt64("DLQ_FILE_MAX_BYTES", 100*1024*1024),
+
+		DLQProducerAcks:         getEnv("DLQ_PRODUCER_ACKS", "one"),
+		DLQProducerBatchSize:    getEnvInt("DLQ_PRODUCER_BATCH_SIZE", 0),
+		DLQProducerBatchTimeout: getEnvDuration("DLQ_PRODUCER_BATCH_TIMEOUT_MS", 0),
+
+		DLQMaxRetries:          getEnvInt("DLQ_MAX_RETRIES", 0),
+		QuarantineSinkType:     getEnv("QUARANTINE_SINK_TYPE", "kafka"),
+		QuarantineTopic:        getEnv("QUARANTINE_TOPIC", "transaction-events-quarantine"),
+		QuarantineFilePath:     getEnv("QUARANTINE_FILE_PATH", "quarantine.jsonl"),
+		QuarantineFileMaxBytes: getEnvInt64("QUARANTINE_FILE_MAX_BYTES", 100*1024*1024),
+
+		QuarantineDedupCacheSize: getEnvInt("QUARANTINE_DEDUP_CACHE_SIZE", 1024),
+		QuarantineDedupWindow:    getEnvDuration("QUARANTINE_DEDUP_WINDOW_MS", time.Hour),
+
+		HealthAddr:           getEnv("HEALTH_ADDR", ":8081"),
+		FailureRateThreshold: getEnvFloat("HEALTH_FAILURE_RATE_THRESHOLD", 0.5),
+		AdminAllowedCIDRs:    getEnvList("ADMIN_ALLOWED_CIDRS", ""),
+		AdminToken:           getEnv("ADMIN_TOKEN", ""),
+		PprofEnabled:         getEnvBool("PPROF_ENABLED", false),
+		ShutdownTimeout:      getEnvDuration("SHUTDOWN_TIMEOUT_MS", 30*time.Second),
+
+		WorkerCount: getEnvInt("WORKER_COUNT", 4),
+
+		EnrichmentEnabled: getEnvBool("ENRICHMENT_ENABLED", false),
+		LedgerCoreAddr:    getEnv("LEDGER_CORE_ADDR", ""),
+		AccountCacheSize:  getEnvInt("ACCOUNT_CACHE_SIZE", 1024),
+
+		IdempotencyCacheSize: getEnvInt("IDEMPOTENCY_CACHE_SIZE", 4096),
+		IdempotencyWindow:    getEnvDuration("IDEMPOTENCY_WINDOW_MS", 10*time.Minute),
+
+		BackpressureHighWatermark: getEnvInt64("BACKPRESSURE_HIGH_WATERMARK", 0),
+		BackpressureLowWatermark:  getEnvInt64("BACKPRESSURE_LOW_WATERMARK", 0),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// getEnvList reads a comma-separated list of trimmed values, falling back
+// to a single-element list containing fallback when unset or empty.
+func getEnvList(key, fallback string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		v = fallback
+	}
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// getEnvIntList reads a comma-separated list of ints, falling back to
+// fallback when unset, empty, or containing a value that doesn't parse.
+func getEnvIntList(key string, fallback []int) []int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	var out []int
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return fallback
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// getEnvDuration reads an integer number of milliseconds from key.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	ms, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func getEnvInt64(key string, fallback int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
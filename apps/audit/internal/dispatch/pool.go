@@ -0,0 +1,92 @@
+// Package dispatch fans Kafka messages out to a pool of worker goroutines
+// while preserving per-key ordering, so the audit consumer can process
+// messages concurrently without reordering updates to the same entity.
+package dispatch
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Handler processes a single Kafka message. It is called from one of the
+// pool's worker goroutines.
+type Handler func(ctx context.Context, msg kafka.Message) error
+
+// Pool runs workerCount goroutines, each owning one shard of an ordered
+// queue. Messages with the same key always land on the same shard, so
+// they're handled in the order Submit was called for that key, while
+// messages with different keys may be processed concurrently.
+type Pool struct {
+	shards  []chan kafka.Message
+	handler Handler
+	wg      sync.WaitGroup
+}
+
+// shardQueueSize bounds how many messages can be buffered per shard before
+// Submit blocks, applying backpressure to the reader.
+const shardQueueSize = 64
+
+// NewPool starts workerCount worker goroutines and returns a Pool ready to
+// accept messages via Submit. workerCount is clamped to at least 1.
+func NewPool(workerCount int, handler Handler) *Pool {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	p := &Pool{
+		shards:  make([]chan kafka.Message, workerCount),
+		handler: handler,
+	}
+	for i := range p.shards {
+		shard := make(chan kafka.Message, shardQueueSize)
+		p.shards[i] = shard
+		p.wg.Add(1)
+		go p.runWorker(shard)
+	}
+	return p
+}
+
+func (p *Pool) runWorker(shard <-chan kafka.Message) {
+	defer p.wg.Done()
+	for msg := range shard {
+		if err := p.handler(context.Background(), msg); err != nil {
+			log.Printf("dispatch: error processing message at offset %d: %v", msg.Offset, err)
+		}
+	}
+}
+
+// Submit enqueues msg onto the shard owned by its key, blocking until
+// there's room or ctx is canceled. Messages with an empty key are spread
+// round-robin-ish by offset, since there's no ordering constraint to
+// preserve for them.
+func (p *Pool) Submit(ctx context.Context, msg kafka.Message) error {
+	shard := p.shards[p.shardFor(msg)]
+	select {
+	case shard <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) shardFor(msg kafka.Message) int {
+	if len(msg.Key) == 0 {
+		return int(msg.Offset) % len(p.shards)
+	}
+	h := fnv.New32a()
+	h.Write(msg.Key)
+	return int(h.Sum32()) % len(p.shards)
+}
+
+// Close closes every shard's queue and blocks until all workers have
+// drained their remaining buffered messages and exited. Callers should
+// stop feeding Submit before calling Close.
+func (p *Pool) Close() {
+	for _, shard := range p.shards {
+		close(shard)
+	}
+	p.wg.Wait()
+}
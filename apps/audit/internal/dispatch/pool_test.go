@@ -0,0 +1,97 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// TestPoolPreservesPerKeyOrdering submits messages for several keys
+// interleaved across the pool's workers and asserts that, for each key,
+// the handler observes them in submission order despite concurrent
+// processing of different keys.
+func TestPoolPreservesPerKeyOrdering(t *testing.T) {
+	const keys = 5
+	const perKey = 50
+
+	var mu sync.Mutex
+	seen := make(map[string][]int)
+
+	pool := NewPool(4, func(ctx context.Context, msg kafka.Message) error {
+		// Simulate variable processing time so workers genuinely race.
+		time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+
+		key := string(msg.Key)
+		seq := 0
+		fmt.Sscanf(string(msg.Value), "%d", &seq)
+
+		mu.Lock()
+		seen[key] = append(seen[key], seq)
+		mu.Unlock()
+		return nil
+	})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for k := 0; k < keys; k++ {
+		key := fmt.Sprintf("key-%d", k)
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			for seq := 0; seq < perKey; seq++ {
+				msg := kafka.Message{Key: []byte(key), Value: []byte(fmt.Sprintf("%d", seq))}
+				if err := pool.Submit(ctx, msg); err != nil {
+					t.Errorf("submit: %v", err)
+					return
+				}
+			}
+		}(key)
+	}
+	wg.Wait()
+	pool.Close()
+
+	for k := 0; k < keys; k++ {
+		key := fmt.Sprintf("key-%d", k)
+		seqs := seen[key]
+		if len(seqs) != perKey {
+			t.Fatalf("key %s: expected %d messages, got %d", key, perKey, len(seqs))
+		}
+		for i, seq := range seqs {
+			if seq != i {
+				t.Fatalf("key %s: out of order at position %d: got seq %d, want %d", key, i, seq, i)
+			}
+		}
+	}
+}
+
+func TestPoolSubmitRespectsContextCancellation(t *testing.T) {
+	block := make(chan struct{})
+	pool := NewPool(1, func(ctx context.Context, msg kafka.Message) error {
+		<-block
+		return nil
+	})
+	defer func() {
+		close(block)
+		pool.Close()
+	}()
+
+	// Fill the single shard's buffer plus the in-flight slot so the next
+	// Submit has to wait, then cancel the context and confirm it returns.
+	ctx := context.Background()
+	for i := 0; i < shardQueueSize+1; i++ {
+		if err := pool.Submit(ctx, kafka.Message{Key: []byte("k"), Offset: int64(i)}); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pool.Submit(cancelCtx, kafka.Message{Key: []byte("k")}); err == nil {
+		t.Fatal("expected Submit to return an error after context cancellation")
+	}
+}
@@ -0,0 +1,116 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var _ DeadLetterSink = (*FileSink)(nil)
+
+// FileSink writes FailedDocuments as newline-delimited JSON to a local
+// file, for environments that would rather spill DLQ failures to disk (or
+// a mounted object-storage volume) than run a Kafka DLQ topic. The active
+// file is rotated to a numbered sibling once it exceeds MaxBytes.
+type FileSink struct {
+	// Path is the active file's path, e.g. "/var/log/audit/dlq.jsonl".
+	// Rotated files are written alongside it as "<path>.1", "<path>.2", etc.
+	Path string
+	// MaxBytes rotates the active file once its size reaches this many
+	// bytes. Zero disables rotation.
+	MaxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	sent atomic.Int64
+}
+
+// NewFileSink opens (or creates) path for appending and returns a ready
+// FileSink. maxBytes of 0 disables rotation.
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	s := &FileSink{Path: path, MaxBytes: maxBytes}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("dlq: opening %s: %w", s.Path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("dlq: stat %s: %w", s.Path, err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// SendToDeadLetter appends doc as one JSON line, rotating the file first
+// if it has grown past MaxBytes.
+func (s *FileSink) SendToDeadLetter(ctx context.Context, doc FailedDocument) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	body = append(body, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.MaxBytes > 0 && s.size+int64(len(body)) > s.MaxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(body)
+	if err != nil {
+		return fmt.Errorf("dlq: writing to %s: %w", s.Path, err)
+	}
+	s.size += int64(n)
+	s.sent.Add(1)
+	return nil
+}
+
+// rotate closes the active file, renames it to the next available
+// "<path>.N" sibling, and opens a fresh empty file at Path. Callers must
+// hold s.mu.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("dlq: closing %s before rotation: %w", s.Path, err)
+	}
+
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s.%d", s.Path, n)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			if err := os.Rename(s.Path, candidate); err != nil {
+				return fmt.Errorf("dlq: rotating %s to %s: %w", s.Path, candidate, err)
+			}
+			break
+		}
+	}
+
+	return s.open()
+}
+
+// SentCount returns the number of documents successfully written since
+// process start.
+func (s *FileSink) SentCount() int64 {
+	return s.sent.Load()
+}
+
+// Close closes the active file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,55 @@
+package dlq
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSinkRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dlq.jsonl")
+
+	sink, err := NewFileSink(path, 200)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 10; i++ {
+		doc := FailedDocument{
+			DocumentID:  "doc",
+			SourceTopic: "transaction-events",
+			ErrorType:   "mapper_parsing_exception",
+			Reason:      "field amount of type keyword",
+			FailedAt:    time.Now().UTC(),
+		}
+		if err := sink.SendToDeadLetter(context.Background(), doc); err != nil {
+			t.Fatalf("SendToDeadLetter %d: %v", i, err)
+		}
+	}
+
+	if sink.SentCount() != 10 {
+		t.Fatalf("expected SentCount 10, got %d", sink.SentCount())
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected a rotated file %s.1 to exist: %v", path, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open active file: %v", err)
+	}
+	defer f.Close()
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+	}
+	if lines == 0 {
+		t.Fatal("expected at least one line in the active file after rotation")
+	}
+}
@@ -0,0 +1,197 @@
+// Package dlq sends documents the audit service couldn't index into
+// Elasticsearch to a dead-letter Kafka topic for later inspection/replay.
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+)
+
+// FailedDocument describes one document that failed to index, with enough
+// context to diagnose and potentially replay it.
+type FailedDocument struct {
+	DocumentID  string `json:"document_id"`
+	SourceTopic string `json:"source_topic"`
+	// Partition and Offset locate the original record on SourceTopic, so a
+	// DLQ entry can be traced back to the exact source record (e.g. to
+	// inspect it with a Kafka console consumer).
+	Partition int   `json:"partition"`
+	Offset    int64 `json:"offset"`
+	// Headers carries the original record's Kafka headers (e.g. trace
+	// context), keyed by header key. kafka.Message allows repeated header
+	// keys; a repeat here is collapsed to its last value, which is
+	// acceptable for this diagnostic use.
+	Headers   map[string][]byte `json:"headers,omitempty"`
+	ErrorType string            `json:"error_type"`
+	Reason    string            `json:"reason"`
+	// ErrorHistory accumulates every failure recorded for this document
+	// across retries, oldest first, via the headers RetryCountFromHeaders
+	// and HistoryFromHeaders read back out of a replayed message. It's in
+	// addition to ErrorType/Reason, which always describe only the latest
+	// failure.
+	ErrorHistory []FailureRecord `json:"error_history,omitempty"`
+	RetryCount   int             `json:"retry_count"`
+	FailedAt     time.Time       `json:"failed_at"`
+	Payload      []byte          `json:"payload"`
+}
+
+// HeadersToKafka converts a FailedDocument's Headers back into
+// kafka.Header form, so a DLQ record (or a replayed one) carries the same
+// headers as the record that produced it -- trace context, and since
+// WithRetryHeaders, the retry bookkeeping headers too.
+func HeadersToKafka(m map[string][]byte) []kafka.Header {
+	if len(m) == 0 {
+		return nil
+	}
+	headers := make([]kafka.Header, 0, len(m))
+	for key, value := range m {
+		headers = append(headers, kafka.Header{Key: key, Value: value})
+	}
+	return headers
+}
+
+var _ DeadLetterSink = (*Producer)(nil)
+
+// ProducerConfig configures Producer's underlying kafka.Writer beyond the
+// broker list, topic, and transport NewProducer already takes directly.
+//
+// kafka-go has no support for Kafka's idempotent-producer protocol (it
+// never negotiates a producer ID or tracks per-partition sequence
+// numbers), so there's no field here that makes retried writes dedupe at
+// the broker the way a real idempotent producer would. RequiredAcks of
+// kafka.RequireAll is the closest this client gets: it won't report a
+// write successful until every in-sync replica has it, which at least
+// rules out acknowledging a write the leader then loses. Keying by
+// document ID (see SendToDeadLetter) still does the rest of the work of
+// keeping repeated failures for the same document on one partition.
+type ProducerConfig struct {
+	// RequiredAcks is the ack level WriteMessages waits for. See the
+	// idempotence caveat above for why this, not an "idempotent" flag, is
+	// the lever this client exposes.
+	RequiredAcks kafka.RequiredAcks
+	// BatchSize and BatchTimeout bound how many messages, or how long,
+	// the writer batches before flushing to the broker. Zero for either
+	// falls back to kafka.Writer's own defaults (100 messages, 1s).
+	BatchSize    int
+	BatchTimeout time.Duration
+	// Metrics is where SendToDeadLetter reports the counters described on
+	// ProducerMetrics. Nil (the default) gets a ProducerMetrics that isn't
+	// registered anywhere -- a Producer built with a zero-value
+	// ProducerConfig, as every existing test does, still works, it just
+	// doesn't show up on /metrics. Production call sites should pass one
+	// built with NewProducerMetrics(prometheus.DefaultRegisterer).
+	Metrics *ProducerMetrics
+}
+
+// ProducerMetrics holds the Prometheus counters Producer.SendToDeadLetter
+// reports on. It exists as its own type, rather than package-level
+// promauto vars like this package's siblings use, so a test -- or a
+// second Producer instance, such as the one newQuarantineSink builds --
+// doesn't have to share (or fight over) the global default registerer.
+type ProducerMetrics struct {
+	// sentTotal counts documents successfully written to the DLQ topic,
+	// labeled by the error that put them there and the Kafka topic they
+	// originally failed to index from. It's the earliest warning of an ES
+	// mapping or connectivity problem: a sustained rise here, broken down
+	// by error_type, is usually enough to tell what's wrong before anyone
+	// reads a log.
+	sentTotal *prometheus.CounterVec
+	// sendFailuresTotal counts documents Producer itself failed to write
+	// to the DLQ topic (e.g. the DLQ broker is also unreachable), labeled
+	// by source topic. These are the worst case: a document that failed
+	// to index AND couldn't be dead-lettered, so it's only visible in
+	// logs unless this counter is alerted on directly.
+	sendFailuresTotal *prometheus.CounterVec
+}
+
+// NewProducerMetrics builds a ProducerMetrics, registering its counters
+// against reg. Passing nil registers them nowhere -- they still work, but
+// never appear on any /metrics endpoint -- which is what tests that don't
+// care about metrics, or a second Producer sharing a process with one
+// that already registered against the real default registerer, should
+// pass.
+func NewProducerMetrics(reg prometheus.Registerer) *ProducerMetrics {
+	factory := promauto.With(reg)
+	return &ProducerMetrics{
+		sentTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "audit_dlq_sent_total",
+			Help: "Total number of documents sent to the dead-letter topic, labeled by error type and source topic.",
+		}, []string{"error_type", "source_topic"}),
+		sendFailuresTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "audit_dlq_send_failures_total",
+			Help: "Total number of documents Producer failed to write to the dead-letter topic, labeled by source topic.",
+		}, []string{"source_topic"}),
+	}
+}
+
+// DefaultProducerConfig returns the ProducerConfig equivalent to
+// Producer's behavior before ProducerConfig existed: RequiredAcks of
+// kafka.RequireOne, synchronous writes, kafka-go's own batch defaults.
+func DefaultProducerConfig() ProducerConfig {
+	return ProducerConfig{RequiredAcks: kafka.RequireOne}
+}
+
+// Producer writes FailedDocuments to the configured DLQ topic.
+type Producer struct {
+	writer  *kafka.Writer
+	sent    atomic.Int64
+	metrics *ProducerMetrics
+}
+
+// NewProducer returns a DLQ Producer writing to topic on brokers, per cfg.
+// transport may be nil, in which case the writer uses kafka-go's default
+// (no SASL, no TLS) transport.
+func NewProducer(brokers []string, topic string, transport *kafka.Transport, cfg ProducerConfig) *Producer {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		RequiredAcks: cfg.RequiredAcks,
+		BatchSize:    cfg.BatchSize,
+		BatchTimeout: cfg.BatchTimeout,
+	}
+	if transport != nil {
+		writer.Transport = transport
+	}
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = NewProducerMetrics(nil)
+	}
+	return &Producer{writer: writer, metrics: metrics}
+}
+
+// SendToDeadLetter writes doc to the DLQ topic, keyed by its document ID so
+// repeated failures for the same document land on the same partition.
+func (p *Producer) SendToDeadLetter(ctx context.Context, doc FailedDocument) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := p.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(doc.DocumentID),
+		Value:   body,
+		Headers: HeadersToKafka(doc.Headers),
+	}); err != nil {
+		p.metrics.sendFailuresTotal.WithLabelValues(doc.SourceTopic).Inc()
+		return err
+	}
+	p.sent.Add(1)
+	p.metrics.sentTotal.WithLabelValues(doc.ErrorType, doc.SourceTopic).Inc()
+	return nil
+}
+
+// SentCount returns the number of documents successfully sent to the DLQ
+// since process start.
+func (p *Producer) SentCount() int64 {
+	return p.sent.Load()
+}
+
+// Close flushes and closes the underlying Kafka writer.
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
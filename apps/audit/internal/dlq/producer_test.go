@@ -0,0 +1,53 @@
+package dlq
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestDefaultProducerConfigRequiresOneAck(t *testing.T) {
+	if got := DefaultProducerConfig().RequiredAcks; got != kafka.RequireOne {
+		t.Fatalf("RequiredAcks = %v, want %v", got, kafka.RequireOne)
+	}
+}
+
+func TestHeadersToKafkaEmpty(t *testing.T) {
+	if headers := HeadersToKafka(nil); headers != nil {
+		t.Fatalf("expected nil headers for an empty map, got %v", headers)
+	}
+}
+
+func TestNewProducerMetricsRegistersAgainstGivenRegisterer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewProducerMetrics(reg)
+	metrics.sentTotal.WithLabelValues("mapping_error", "transaction-events").Inc()
+
+	if got := testutil.ToFloat64(metrics.sentTotal.WithLabelValues("mapping_error", "transaction-events")); got != 1 {
+		t.Fatalf("sentTotal = %v, want 1", got)
+	}
+	if count := testutil.CollectAndCount(metrics.sentTotal); count != 1 {
+		t.Fatalf("registered series count = %d, want 1", count)
+	}
+}
+
+func TestNewProducerMetricsNilRegistererDoesNotPanic(t *testing.T) {
+	// If these registered against prometheus.DefaultRegisterer, the second
+	// call would panic on a duplicate collector -- this is what lets
+	// every test (and a second Producer, e.g. the quarantine sink) build
+	// a Producer without coordinating a shared registry.
+	NewProducerMetrics(nil)
+	NewProducerMetrics(nil)
+}
+
+func TestHeadersToKafkaConverts(t *testing.T) {
+	headers := HeadersToKafka(map[string][]byte{"trace-id": []byte("abc123")})
+	if len(headers) != 1 {
+		t.Fatalf("expected 1 header, got %d", len(headers))
+	}
+	if headers[0].Key != "trace-id" || string(headers[0].Value) != "abc123" {
+		t.Fatalf("unexpected header %+v", headers[0])
+	}
+}
@@ -0,0 +1,60 @@
+package dlq
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/idempotency"
+)
+
+var _ DeadLetterSink = (*QuarantineSink)(nil)
+
+// QuarantineSink wraps another DeadLetterSink (typically a Producer
+// pointed at a separate quarantine topic, or a FileSink) as the terminal
+// destination for documents that have exhausted their retries. It exists
+// as its own type, rather than just reusing the regular DLQ sink, so a
+// quarantined document lands somewhere a reprocessor doesn't read from
+// and therefore never attempts again.
+//
+// SendToDeadLetter is deduplicated by DocumentID: a document already
+// quarantined within window is not written a second time, even if
+// onIndexFailure keeps retrying it with the same exhausted retry count.
+// This guards against the mundane case of a crash-and-redeliver landing
+// the same poison message here twice, not against a determined attacker,
+// so the dedup window matters less than its existence.
+type QuarantineSink struct {
+	sink  DeadLetterSink
+	seen  *idempotency.Cache
+	count atomic.Int64
+}
+
+// NewQuarantineSink returns a QuarantineSink writing through to sink,
+// deduplicating by DocumentID within window using a cache of up to
+// capacity entries.
+func NewQuarantineSink(sink DeadLetterSink, capacity int, window time.Duration) *QuarantineSink {
+	return &QuarantineSink{
+		sink: sink,
+		seen: idempotency.NewCache(capacity, window),
+	}
+}
+
+// SendToDeadLetter writes doc to the wrapped sink, unless a document with
+// the same DocumentID was already quarantined within this sink's dedup
+// window.
+func (q *QuarantineSink) SendToDeadLetter(ctx context.Context, doc FailedDocument) error {
+	if q.seen.SeenOrRemember(doc.DocumentID) {
+		return nil
+	}
+	if err := q.sink.SendToDeadLetter(ctx, doc); err != nil {
+		return err
+	}
+	q.count.Add(1)
+	return nil
+}
+
+// SentCount returns the number of documents actually written (i.e.
+// excluding deduplicated ones) since process start.
+func (q *QuarantineSink) SentCount() int64 {
+	return q.count.Load()
+}
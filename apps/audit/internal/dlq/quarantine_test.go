@@ -0,0 +1,51 @@
+package dlq
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	docs []FailedDocument
+}
+
+func (r *recordingSink) SendToDeadLetter(ctx context.Context, doc FailedDocument) error {
+	r.docs = append(r.docs, doc)
+	return nil
+}
+
+func TestQuarantineSinkDedupesByDocumentID(t *testing.T) {
+	inner := &recordingSink{}
+	q := NewQuarantineSink(inner, 16, time.Hour)
+
+	doc := FailedDocument{DocumentID: "tx-1"}
+	for i := 0; i < 3; i++ {
+		if err := q.SendToDeadLetter(context.Background(), doc); err != nil {
+			t.Fatalf("SendToDeadLetter: %v", err)
+		}
+	}
+
+	if len(inner.docs) != 1 {
+		t.Fatalf("inner sink got %d documents, want 1 after 3 sends of the same document ID", len(inner.docs))
+	}
+	if q.SentCount() != 1 {
+		t.Fatalf("SentCount() = %d, want 1", q.SentCount())
+	}
+}
+
+func TestQuarantineSinkDistinctDocumentsBothSent(t *testing.T) {
+	inner := &recordingSink{}
+	q := NewQuarantineSink(inner, 16, time.Hour)
+
+	if err := q.SendToDeadLetter(context.Background(), FailedDocument{DocumentID: "tx-1"}); err != nil {
+		t.Fatalf("SendToDeadLetter: %v", err)
+	}
+	if err := q.SendToDeadLetter(context.Background(), FailedDocument{DocumentID: "tx-2"}); err != nil {
+		t.Fatalf("SendToDeadLetter: %v", err)
+	}
+
+	if len(inner.docs) != 2 {
+		t.Fatalf("inner sink got %d documents, want 2 for distinct document IDs", len(inner.docs))
+	}
+}
@@ -0,0 +1,72 @@
+package dlq
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// RetryCountHeader and ErrorHistoryHeader carry a document's retry
+// bookkeeping across a dlqtool replay: replaying only republishes a
+// FailedDocument's original Payload to the source topic, so without these
+// headers riding along on the replayed message, RetryCount and
+// ErrorHistory would reset to zero every time a document round-trips
+// through the DLQ.
+const (
+	RetryCountHeader   = "x-dlq-retry-count"
+	ErrorHistoryHeader = "x-dlq-error-history"
+)
+
+// FailureRecord is one past failed indexing attempt for a document,
+// accumulated in FailedDocument.ErrorHistory across retries.
+type FailureRecord struct {
+	ErrorType string    `json:"error_type"`
+	Reason    string    `json:"reason"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// RetryCountFromHeaders returns the retry count a previous attempt
+// recorded in headers via WithRetryHeaders, or 0 if there isn't one --
+// i.e. this is the first attempt.
+func RetryCountFromHeaders(headers map[string][]byte) int {
+	raw, ok := headers[RetryCountHeader]
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// HistoryFromHeaders returns the FailureRecords a previous attempt
+// recorded in headers via WithRetryHeaders, or nil if there are none yet.
+func HistoryFromHeaders(headers map[string][]byte) []FailureRecord {
+	raw, ok := headers[ErrorHistoryHeader]
+	if !ok {
+		return nil
+	}
+	var history []FailureRecord
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+// WithRetryHeaders returns a copy of headers with RetryCountHeader and
+// ErrorHistoryHeader set from retryCount and history, so whatever next
+// reads headers (onIndexFailure, after a dlqtool replay) sees this
+// document's accumulated retry state rather than starting over. headers
+// itself is left untouched.
+func WithRetryHeaders(headers map[string][]byte, retryCount int, history []FailureRecord) map[string][]byte {
+	out := make(map[string][]byte, len(headers)+2)
+	for k, v := range headers {
+		out[k] = v
+	}
+	out[RetryCountHeader] = []byte(strconv.Itoa(retryCount))
+	if encoded, err := json.Marshal(history); err == nil {
+		out[ErrorHistoryHeader] = encoded
+	}
+	return out
+}
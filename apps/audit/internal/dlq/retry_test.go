@@ -0,0 +1,25 @@
+package dlq
+
+import "testing"
+
+func TestRetryCountFromHeadersMissingIsZero(t *testing.T) {
+	if n := RetryCountFromHeaders(nil); n != 0 {
+		t.Fatalf("RetryCountFromHeaders(nil) = %d, want 0", n)
+	}
+}
+
+func TestWithRetryHeadersRoundTrips(t *testing.T) {
+	history := []FailureRecord{{ErrorType: "mapper_parsing_exception", Reason: "bad field"}}
+	headers := WithRetryHeaders(map[string][]byte{"trace-id": []byte("abc")}, 2, history)
+
+	if n := RetryCountFromHeaders(headers); n != 2 {
+		t.Fatalf("RetryCountFromHeaders = %d, want 2", n)
+	}
+	got := HistoryFromHeaders(headers)
+	if len(got) != 1 || got[0].ErrorType != "mapper_parsing_exception" {
+		t.Fatalf("HistoryFromHeaders = %+v, want 1 entry matching the input", got)
+	}
+	if string(headers["trace-id"]) != "abc" {
+		t.Fatalf("WithRetryHeaders dropped an existing header")
+	}
+}
@@ -0,0 +1,21 @@
+package dlq
+
+import "context"
+
+// DeadLetterSink accepts documents the audit service failed to index
+// elsewhere, so they can be inspected or replayed later. Producer (Kafka)
+// and FileSink are the two implementations; callers that only need to send
+// failures should depend on this interface rather than a concrete type.
+type DeadLetterSink interface {
+	SendToDeadLetter(ctx context.Context, doc FailedDocument) error
+}
+
+// SourceMeta describes where a record that ended up in the DLQ came from,
+// so callers that index documents (e.g. elasticsearch.Client) can report
+// it on FailedDocument without depending on kafka-go themselves.
+type SourceMeta struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Headers   map[string][]byte
+}
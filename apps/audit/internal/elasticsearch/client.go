@@ -0,0 +1,706 @@
+// Package elasticsearch wraps the official Elasticsearch client with a
+// bulk indexer tuned for the audit service's write pattern: many small
+// transaction documents, with failures routed to a dead-letter topic
+// instead of blocking the consumer.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/model"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/tlsconfig"
+)
+
+// quarantinedTotal counts documents routed to QuarantineSink because
+// they'd hit DLQMaxRetries, rather than sent to DLQSink for another
+// attempt.
+var quarantinedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "audit_quarantined_documents_total",
+	Help: "Total number of documents routed to the quarantine sink after exhausting their DLQ retries.",
+})
+
+// Config configures the audit Elasticsearch client.
+type Config struct {
+	URL      string
+	Index    string
+	Username string
+	Password string
+	// SkipTLSVerify disables server certificate verification. It's meant
+	// for local/dev clusters with self-signed certs; CACertPath is the
+	// right tool for a real cluster.
+	SkipTLSVerify bool
+	// CACertPath, if set, is a PEM file added to the trusted root pool for
+	// verifying the cluster's certificate, instead of (or alongside) the
+	// system roots.
+	CACertPath string
+	// TLSMinVersion and TLSCipherSuites set the compliance baseline applied
+	// via tlsconfig.Build -- "1.2" or "1.3", and an allowlist of standard
+	// Go cipher suite names. Both are empty by default, which is
+	// tlsconfig.Build's own default (TLS 1.2, DefaultCipherSuites).
+	TLSMinVersion   string
+	TLSCipherSuites []string
+	DLQSink         dlq.DeadLetterSink
+
+	// DLQMaxRetries caps how many times a document may be sent to DLQSink
+	// before onIndexFailure routes it to QuarantineSink instead. Zero (the
+	// default) disables quarantining, so a deployment that hasn't set
+	// QuarantineSink keeps retrying forever, same as before this existed.
+	DLQMaxRetries  int
+	QuarantineSink dlq.DeadLetterSink
+
+	// MaxRetries and RetryOnStatus configure the underlying
+	// elasticsearch.Client's built-in transport retry behavior --
+	// connection resets and the listed HTTP statuses -- so operators can
+	// tune it during cluster maintenance without a rebuild. The bulk
+	// indexer has no equivalent per-item retry-on-status knob of its own;
+	// a rejected item is handed to onIndexFailure instead.
+	MaxRetries    int
+	RetryOnStatus []int
+	// RequestTimeout bounds each ES call the client makes directly
+	// (Flush, EraseAccount); the bulk indexer manages its own per-item
+	// lifecycle and isn't affected by it.
+	RequestTimeout time.Duration
+
+	// MaxDocumentBytes caps the marshaled size of a document IndexTransaction
+	// will send to ES. A document over the cap has its Description
+	// truncated to fit; if it's still over the cap afterward, it's sent
+	// straight to DLQSink instead of being added to the bulk indexer,
+	// where ES would otherwise reject it on every retry. Zero applies
+	// DefaultMaxDocumentBytes.
+	MaxDocumentBytes int
+
+	// RefreshInterval sets the index's settings.refresh_interval when it's
+	// created. Empty applies DefaultRefreshInterval. It only takes effect
+	// at creation time; SetRefreshInterval changes it on an index that
+	// already exists, which is what a bulk backfill uses to disable
+	// refresh for the run (see SetRefreshInterval and Refresh).
+	RefreshInterval string
+}
+
+// DefaultRefreshInterval is applied when Config leaves RefreshInterval at
+// its zero value, keeping newly indexed transactions searchable almost
+// immediately, at some cost to indexing throughput.
+const DefaultRefreshInterval = "1s"
+
+// indexMappingBody returns the body ensureIndex sends to create the index,
+// with refreshInterval as its settings.refresh_interval.
+func indexMappingBody(refreshInterval string) string {
+	return fmt.Sprintf(`{
+  "settings": {
+    "refresh_interval": %q
+  },
+  "mappings": {
+    "properties": {
+      "transaction_id": {"type": "keyword"},
+      "from_account_id": {"type": "keyword"},
+      "to_account_id": {"type": "keyword"},
+      "amount": {"type": "keyword"},
+      "currency": {"type": "keyword"},
+      "status": {"type": "keyword"},
+      "booked_at": {"type": "date"},
+      "from_account_owner_id": {"type": "keyword"},
+      "from_account_currency": {"type": "keyword"},
+      "to_account_owner_id": {"type": "keyword"},
+      "to_account_currency": {"type": "keyword"},
+      "description": {
+        "type": "text",
+        "fields": {
+          "keyword": {"type": "keyword", "ignore_above": 256}
+        }
+      }
+    }
+  }
+}`, refreshInterval)
+}
+
+// Client indexes transaction documents into Elasticsearch via a bulk
+// indexer. Failures are handed to cfg.DLQSink rather than surfaced to the
+// caller, so the Kafka consumer loop never blocks on ES availability.
+type Client struct {
+	es    *elasticsearch.Client
+	index string
+	dlq   dlq.DeadLetterSink
+
+	dlqMaxRetries int
+	quarantine    dlq.DeadLetterSink
+
+	requestTimeout time.Duration
+
+	maxDocumentBytes int
+
+	// mu guards bi: Flush swaps it out for a fresh indexer, while
+	// IndexTransaction and Stats read it concurrently from the consumer
+	// loop and the HTTP server.
+	mu sync.RWMutex
+	bi esutil.BulkIndexer
+}
+
+// DefaultMaxRetries and DefaultRetryOnStatus are applied when Config
+// leaves MaxRetries/RetryOnStatus at their zero value, preserving this
+// client's behavior from before those became configurable.
+var (
+	DefaultMaxRetries    = 3
+	DefaultRetryOnStatus = []int{502, 503, 504, 429}
+)
+
+// DefaultMaxDocumentBytes is applied when Config leaves MaxDocumentBytes
+// at its zero value. 1 MiB comfortably clears Elasticsearch's default
+// index.mapping.total_fields and http.max_content_length settings while
+// still catching the pathological case (a runaway description, a
+// malformed enrichment blob) this limit exists for.
+var DefaultMaxDocumentBytes = 1 << 20
+
+// NewClient connects to Elasticsearch, ensures the configured index exists
+// with indexMapping, and starts a bulk indexer. It fails fast with a clear
+// error if the cluster is unreachable or rejects cfg's credentials, rather
+// than deferring that discovery to the first index attempt.
+func NewClient(cfg Config) (*Client, error) {
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	retryOnStatus := cfg.RetryOnStatus
+	if len(retryOnStatus) == 0 {
+		retryOnStatus = DefaultRetryOnStatus
+	}
+	log.Printf("elasticsearch: retry config: max_retries=%d retry_on_status=%v request_timeout=%s", maxRetries, retryOnStatus, cfg.RequestTimeout)
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses:     []string{cfg.URL},
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		Transport:     transport,
+		MaxRetries:    maxRetries,
+		RetryOnStatus: retryOnStatus,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: new client: %w", err)
+	}
+
+	if err := ping(es); err != nil {
+		return nil, err
+	}
+
+	refreshInterval := cfg.RefreshInterval
+	if refreshInterval == "" {
+		refreshInterval = DefaultRefreshInterval
+	}
+	if err := ensureIndex(es, cfg.Index, refreshInterval); err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		es:               es,
+		index:            cfg.Index,
+		dlq:              cfg.DLQSink,
+		dlqMaxRetries:    cfg.DLQMaxRetries,
+		quarantine:       cfg.QuarantineSink,
+		requestTimeout:   cfg.RequestTimeout,
+		maxDocumentBytes: cfg.MaxDocumentBytes,
+	}
+
+	bi, err := client.newBulkIndexer()
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: new bulk indexer: %w", err)
+	}
+	client.bi = bi
+
+	return client, nil
+}
+
+// newBulkIndexer builds a bulk indexer against c.es/c.index with the
+// service's standard tuning, so NewClient and Flush (which needs to
+// recreate one after closing the old one) apply it identically.
+func (c *Client) newBulkIndexer() (esutil.BulkIndexer, error) {
+	return esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:        c.es,
+		Index:         c.index,
+		FlushInterval: 30 * time.Second,
+		NumWorkers:    2,
+		OnError: func(ctx context.Context, err error) {
+			log.Printf("elasticsearch: bulk indexer error: %v", err)
+		},
+	})
+}
+
+// withTimeout bounds ctx by c.requestTimeout, for calls the audit service
+// makes directly against Elasticsearch outside the bulk indexer. A
+// non-positive requestTimeout (the default) leaves ctx as-is.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.requestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.requestTimeout)
+}
+
+// newTransport builds the http.RoundTripper for cfg's TLS settings. It
+// returns nil (the elasticsearch client's default transport) when none of
+// SkipTLSVerify, CACertPath, TLSMinVersion, or TLSCipherSuites is set, so
+// the common case doesn't pay for a custom transport it doesn't need.
+func newTransport(cfg Config) (http.RoundTripper, error) {
+	if !cfg.SkipTLSVerify && cfg.CACertPath == "" && cfg.TLSMinVersion == "" && len(cfg.TLSCipherSuites) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig, err := tlsconfig.Build(tlsconfig.Config{
+		MinVersion:   cfg.TLSMinVersion,
+		CipherSuites: cfg.TLSCipherSuites,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: building TLS config: %w", err)
+	}
+	tlsConfig.InsecureSkipVerify = cfg.SkipTLSVerify
+
+	if cfg.CACertPath != "" {
+		pem, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("elasticsearch: reading CA cert %s: %w", cfg.CACertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("elasticsearch: no certificates found in CA cert %s", cfg.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// ping confirms the cluster is reachable and accepts es's credentials,
+// surfacing a clear startup error instead of letting a misconfiguration
+// surface later as an opaque bulk-indexer failure.
+func ping(es *elasticsearch.Client) error {
+	resp, err := es.Info()
+	if err != nil {
+		return fmt.Errorf("elasticsearch: connecting to cluster: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("elasticsearch: connecting to cluster: %s", resp.String())
+	}
+	return nil
+}
+
+func ensureIndex(es *elasticsearch.Client, index, refreshInterval string) error {
+	existsResp, err := es.Indices.Exists([]string{index})
+	if err != nil {
+		return fmt.Errorf("elasticsearch: checking index %s: %w", index, err)
+	}
+	defer existsResp.Body.Close()
+	if existsResp.StatusCode == 200 {
+		return nil
+	}
+
+	createResp, err := es.Indices.Create(index, es.Indices.Create.WithBody(bytes.NewReader([]byte(indexMappingBody(refreshInterval)))))
+	if err != nil {
+		return fmt.Errorf("elasticsearch: creating index %s: %w", index, err)
+	}
+	defer createResp.Body.Close()
+	if createResp.IsError() {
+		return fmt.Errorf("elasticsearch: creating index %s: %s", index, createResp.String())
+	}
+	return nil
+}
+
+// IndexTransaction queues doc for indexing, using ES external versioning
+// keyed off doc.Version so a redelivered or out-of-order event can't
+// overwrite a newer document. Indexing failures (after the bulk indexer's
+// own retries) are sent to the DLQ rather than returned, except version
+// conflicts: those mean ES already holds a version at least as new as
+// doc.Version, which is the outcome we want, so they're logged and
+// dropped rather than treated as a failure. meta identifies doc's source
+// Kafka record, so a DLQ entry can be traced back to it.
+//
+// onResolved, if non-nil, is called exactly once, from a bulk indexer
+// worker goroutine, once doc's fate is fully decided: indexed, skipped as
+// a superseded version, or sent to the DLQ. Callers use it to know when
+// it's finally safe to commit past this document's offset -- not merely
+// once this call returns, since the bulk indexer batches and sends doc
+// asynchronously.
+func (c *Client) IndexTransaction(ctx context.Context, doc model.TransactionDocument, meta dlq.SourceMeta, onResolved func()) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("elasticsearch: marshal document: %w", err)
+	}
+
+	maxBytes := c.maxDocumentBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDocumentBytes
+	}
+
+	if len(body) > maxBytes {
+		truncated, truncatedBody, ok := truncateForSize(doc, maxBytes)
+		if !ok {
+			log.Printf("elasticsearch: document %s is %d bytes after truncating its description, still over the %d byte limit, sending to DLQ", doc.TransactionID, len(truncatedBody), maxBytes)
+			c.deadLetter(ctx, doc.TransactionID, "document_too_large",
+				fmt.Sprintf("document is %d bytes after truncating its description, exceeding the %d byte limit", len(truncatedBody), maxBytes),
+				truncatedBody, meta)
+			if onResolved != nil {
+				onResolved()
+			}
+			return nil
+		}
+		log.Printf("elasticsearch: document %s truncated its description to fit the %d byte limit", doc.TransactionID, maxBytes)
+		doc, body = truncated, truncatedBody
+	}
+
+	c.mu.RLock()
+	bi := c.bi
+	c.mu.RUnlock()
+
+	return bi.Add(ctx, esutil.BulkIndexerItem{
+		Action:      "index",
+		DocumentID:  doc.TransactionID,
+		Version:     &doc.Version,
+		VersionType: "external",
+		Body:        bytes.NewReader(body),
+		OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, resp esutil.BulkIndexerResponseItem) {
+			if onResolved != nil {
+				onResolved()
+			}
+		},
+		OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, resp esutil.BulkIndexerResponseItem, err error) {
+			c.onIndexFailure(ctx, item, resp, err, body, meta)
+			if onResolved != nil {
+				onResolved()
+			}
+		},
+	})
+}
+
+func (c *Client) onIndexFailure(ctx context.Context, item esutil.BulkIndexerItem, resp esutil.BulkIndexerResponseItem, err error, body []byte, meta dlq.SourceMeta) {
+	if err == nil && resp.Status == http.StatusConflict {
+		log.Printf("elasticsearch: document %s superseded by a newer version, skipping", item.DocumentID)
+		return
+	}
+
+	errType, reason := "unknown", ""
+	if err != nil {
+		reason = err.Error()
+	} else {
+		errType = resp.Error.Type
+		reason = resp.Error.Reason
+	}
+
+	log.Printf("elasticsearch: failed to index document %s: %s: %s", item.DocumentID, errType, reason)
+
+	c.deadLetter(ctx, item.DocumentID, errType, reason, body, meta)
+}
+
+// deadLetter builds a dlq.FailedDocument for documentID and sends it to
+// c.dlq, or c.quarantine instead once documentID has exhausted
+// DLQMaxRetries: a document in that terminal state won't be seen by a
+// reprocessor reading the DLQ topic and so won't keep retrying on its
+// own. It's shared by onIndexFailure (a bulk indexer rejection) and
+// IndexTransaction (a document too large to even attempt indexing).
+func (c *Client) deadLetter(ctx context.Context, documentID, errType, reason string, body []byte, meta dlq.SourceMeta) {
+	if c.dlq == nil {
+		return
+	}
+
+	failedAt := time.Now().UTC()
+	retryCount := dlq.RetryCountFromHeaders(meta.Headers) + 1
+	history := append(dlq.HistoryFromHeaders(meta.Headers), dlq.FailureRecord{
+		ErrorType: errType,
+		Reason:    reason,
+		FailedAt:  failedAt,
+	})
+
+	doc := dlq.FailedDocument{
+		DocumentID: documentID,
+		// meta.Topic is the topic the failing message actually arrived on
+		// (see ingest.sourceMeta), not a fixed default, so a DLQ entry
+		// names its real origin even when the consumer is subscribed to
+		// several topics at once.
+		SourceTopic:  meta.Topic,
+		Partition:    meta.Partition,
+		Offset:       meta.Offset,
+		Headers:      dlq.WithRetryHeaders(meta.Headers, retryCount, history),
+		ErrorType:    errType,
+		Reason:       reason,
+		ErrorHistory: history,
+		RetryCount:   retryCount,
+		FailedAt:     failedAt,
+		Payload:      body,
+	}
+
+	sink := c.dlq
+	if c.dlqMaxRetries > 0 && retryCount >= c.dlqMaxRetries && c.quarantine != nil {
+		sink = c.quarantine
+		quarantinedTotal.Inc()
+		log.Printf("elasticsearch: document %s exceeded %d retries, quarantining", documentID, c.dlqMaxRetries)
+	}
+
+	if sendErr := sink.SendToDeadLetter(ctx, doc); sendErr != nil {
+		log.Printf("elasticsearch: failed to send document %s to DLQ: %v", documentID, sendErr)
+	}
+}
+
+// truncationSuffix is appended to Description when it's shortened to fit
+// a size limit, so a truncated document is distinguishable from one that
+// always had a short description.
+const truncationSuffix = " [truncated]"
+
+// truncateForSize returns doc with Description shortened enough that its
+// marshaled size is within maxBytes, along with those marshaled bytes.
+// ok is false if even clearing Description entirely doesn't bring the
+// document under the limit, meaning some other field is responsible for
+// the overage and truncation can't help; body is still the (oversized)
+// encoding of doc with Description cleared, for inclusion in a DLQ entry.
+func truncateForSize(doc model.TransactionDocument, maxBytes int) (truncated model.TransactionDocument, body []byte, ok bool) {
+	desc := doc.Description
+	for {
+		candidate := doc
+		candidate.Description = desc
+
+		encoded, err := json.Marshal(candidate)
+		if err != nil {
+			return candidate, nil, false
+		}
+		if len(encoded) <= maxBytes {
+			return candidate, encoded, true
+		}
+		if desc == "" {
+			return candidate, encoded, false
+		}
+
+		cut := (len(encoded) - maxBytes) + len(truncationSuffix)
+		if cut >= len(desc) {
+			desc = ""
+			continue
+		}
+		desc = desc[:len(desc)-cut] + truncationSuffix
+	}
+}
+
+// Stats returns the bulk indexer's cumulative statistics. A Flush resets
+// these to zero, since it replaces the indexer that was accumulating them.
+func (c *Client) Stats() esutil.BulkIndexerStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bi.Stats()
+}
+
+// InFlight returns how many documents have been added to the bulk indexer
+// but not yet flushed or failed -- i.e. still buffered in the indexer or
+// in a request ES hasn't answered yet. Callers use this to sense
+// backpressure: a rising InFlight means ES is indexing slower than
+// IndexTransaction is being called.
+func (c *Client) InFlight() int64 {
+	stats := c.Stats()
+	inFlight := int64(stats.NumAdded) - int64(stats.NumFlushed) - int64(stats.NumFailed)
+	if inFlight < 0 {
+		return 0
+	}
+	return inFlight
+}
+
+// Flush closes the current bulk indexer -- which blocks until every
+// pending item has been sent -- and replaces it with a fresh one, so
+// IndexTransaction keeps working once Flush returns. esutil.BulkIndexer
+// has no flush-without-closing operation, so this close-and-recreate is
+// the only way to force pending items out ahead of FlushInterval. It
+// returns the stats accumulated by the indexer that was just closed.
+func (c *Client) Flush(ctx context.Context) (esutil.BulkIndexerStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.bi.Stats()
+	if err := c.bi.Close(ctx); err != nil {
+		return stats, fmt.Errorf("elasticsearch: flush: closing bulk indexer: %w", err)
+	}
+
+	bi, err := c.newBulkIndexer()
+	if err != nil {
+		return stats, fmt.Errorf("elasticsearch: flush: recreating bulk indexer: %w", err)
+	}
+	c.bi = bi
+	return stats, nil
+}
+
+// SetRefreshInterval updates the index's settings.refresh_interval,
+// overriding whatever it was created with. A bulk backfill sets this to
+// "-1" (disabling automatic refresh) for the duration of the run, then
+// restores it to its normal value afterward and calls Refresh, so the
+// backfill isn't slowed by refreshing on every flush but newly indexed
+// documents still become searchable once it's done rather than waiting
+// out the restored interval.
+func (c *Client) SetRefreshInterval(ctx context.Context, interval string) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"index": map[string]interface{}{"refresh_interval": interval},
+	})
+	if err != nil {
+		return fmt.Errorf("elasticsearch: marshal refresh_interval settings: %w", err)
+	}
+
+	resp, err := c.es.Indices.PutSettings(bytes.NewReader(body),
+		c.es.Indices.PutSettings.WithContext(ctx), c.es.Indices.PutSettings.WithIndex(c.index))
+	if err != nil {
+		return fmt.Errorf("elasticsearch: set refresh_interval: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("elasticsearch: set refresh_interval: %s", resp.String())
+	}
+	return nil
+}
+
+// Refresh forces an Elasticsearch _refresh of the index, making every
+// document indexed so far searchable immediately. It's meant to be called
+// once SetRefreshInterval has restored normal refresh behavior at the end
+// of a bulk backfill, so the backfill's documents don't wait out the
+// restored interval to become searchable.
+func (c *Client) Refresh(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.es.Indices.Refresh(c.es.Indices.Refresh.WithContext(ctx), c.es.Indices.Refresh.WithIndex(c.index))
+	if err != nil {
+		return fmt.Errorf("elasticsearch: refresh index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("elasticsearch: refresh index: %s", resp.String())
+	}
+	return nil
+}
+
+// Close flushes any pending bulk indexer items and closes the client.
+func (c *Client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bi.Close(ctx)
+}
+
+// EraseMode selects how EraseAccount removes an account's trace from the
+// index.
+type EraseMode string
+
+const (
+	// EraseModeDelete removes every document where accountID appears on
+	// either side of the transaction.
+	EraseModeDelete EraseMode = "delete"
+	// EraseModeAnonymize keeps the document -- it still balances the
+	// ledger -- but replaces accountID with anonymizedAccountPlaceholder
+	// on either side, dropping the account's identity from it.
+	EraseModeAnonymize EraseMode = "anonymize"
+)
+
+// anonymizedAccountPlaceholder replaces an account ID when EraseAccount
+// runs in EraseModeAnonymize, so anonymized documents are still
+// distinguishable from documents naming a real account, without
+// retaining the original ID anywhere.
+const anonymizedAccountPlaceholder = "REDACTED"
+
+// accountQuery matches documents where either side of the transaction is
+// accountID.
+func accountQuery(accountID string) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []map[string]interface{}{
+					{"term": map[string]interface{}{"from_account_id": accountID}},
+					{"term": map[string]interface{}{"to_account_id": accountID}},
+				},
+				"minimum_should_match": 1,
+			},
+		},
+	}
+}
+
+// anonymizeQuery is accountQuery plus the painless script that performs
+// the in-place replacement.
+func anonymizeQuery(accountID, placeholder string) map[string]interface{} {
+	body := accountQuery(accountID)
+	body["script"] = map[string]interface{}{
+		"lang":   "painless",
+		"source": "if (ctx._source.from_account_id == params.accountId) { ctx._source.from_account_id = params.placeholder } if (ctx._source.to_account_id == params.accountId) { ctx._source.to_account_id = params.placeholder }",
+		"params": map[string]interface{}{
+			"accountId":   accountID,
+			"placeholder": placeholder,
+		},
+	}
+	return body
+}
+
+// EraseAccount removes accountID's trace from the index, to satisfy a
+// data-subject erasure request. It runs against ES's delete-by-query or
+// update-by-query with refresh enabled, so a repeat call against the same
+// accountID -- e.g. a retry after a client-side timeout -- finds nothing
+// left to affect and reports 0: both modes are idempotent. It returns the
+// number of documents deleted or anonymized.
+func (c *Client) EraseAccount(ctx context.Context, accountID string, mode EraseMode) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	var body map[string]interface{}
+	switch mode {
+	case EraseModeDelete:
+		body = accountQuery(accountID)
+	case EraseModeAnonymize:
+		body = anonymizeQuery(accountID, anonymizedAccountPlaceholder)
+	default:
+		return 0, fmt.Errorf("elasticsearch: unsupported erase mode %q", mode)
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("elasticsearch: marshal erase query: %w", err)
+	}
+
+	var resp *esapi.Response
+	switch mode {
+	case EraseModeDelete:
+		resp, err = c.es.DeleteByQuery([]string{c.index}, bytes.NewReader(encoded),
+			c.es.DeleteByQuery.WithContext(ctx), c.es.DeleteByQuery.WithRefresh(true))
+	case EraseModeAnonymize:
+		resp, err = c.es.UpdateByQuery([]string{c.index},
+			c.es.UpdateByQuery.WithBody(bytes.NewReader(encoded)),
+			c.es.UpdateByQuery.WithContext(ctx), c.es.UpdateByQuery.WithRefresh(true))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("elasticsearch: erase account %s: %w", accountID, err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return 0, fmt.Errorf("elasticsearch: erase account %s: %s", accountID, resp.String())
+	}
+
+	var result struct {
+		Deleted int64 `json:"deleted"`
+		Updated int64 `json:"updated"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("elasticsearch: decode erase response: %w", err)
+	}
+	if mode == EraseModeDelete {
+		return result.Deleted, nil
+	}
+	return result.Updated, nil
+}
@@ -0,0 +1,101 @@
+package elasticsearch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndexMappingBodyEmbedsRefreshInterval(t *testing.T) {
+	body := indexMappingBody("-1")
+	if !strings.Contains(body, `"refresh_interval": "-1"`) {
+		t.Fatalf("indexMappingBody(%q) = %s, want it to contain refresh_interval -1", "-1", body)
+	}
+}
+
+func TestNewTransportNoTLSSettings(t *testing.T) {
+	transport, err := newTransport(Config{URL: "http://localhost:9200"})
+	if err != nil {
+		t.Fatalf("newTransport: %v", err)
+	}
+	if transport != nil {
+		t.Fatalf("expected a nil transport when no TLS settings are configured, got %v", transport)
+	}
+}
+
+func TestNewTransportSkipTLSVerify(t *testing.T) {
+	transport, err := newTransport(Config{URL: "https://localhost:9200", SkipTLSVerify: true})
+	if err != nil {
+		t.Fatalf("newTransport: %v", err)
+	}
+	if transport == nil {
+		t.Fatal("expected a non-nil transport")
+	}
+}
+
+func TestNewTransportWithCACert(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(path, []byte(testCACertPEM), 0o600); err != nil {
+		t.Fatalf("writing test CA cert: %v", err)
+	}
+
+	transport, err := newTransport(Config{URL: "https://localhost:9200", CACertPath: path})
+	if err != nil {
+		t.Fatalf("newTransport: %v", err)
+	}
+	if transport == nil {
+		t.Fatal("expected a non-nil transport")
+	}
+}
+
+func TestNewTransportWithTLSMinVersion(t *testing.T) {
+	transport, err := newTransport(Config{URL: "https://localhost:9200", TLSMinVersion: "1.3"})
+	if err != nil {
+		t.Fatalf("newTransport: %v", err)
+	}
+	if transport == nil {
+		t.Fatal("expected a non-nil transport")
+	}
+}
+
+func TestNewTransportWithInvalidTLSMinVersion(t *testing.T) {
+	_, err := newTransport(Config{URL: "https://localhost:9200", TLSMinVersion: "1.0"})
+	if err == nil {
+		t.Fatal("expected an error for an unsupported minimum TLS version")
+	}
+}
+
+func TestNewTransportWithMissingCACert(t *testing.T) {
+	_, err := newTransport(Config{URL: "https://localhost:9200", CACertPath: filepath.Join(t.TempDir(), "missing.pem")})
+	if err == nil {
+		t.Fatal("expected an error for a missing CA cert file")
+	}
+}
+
+func TestNewTransportWithInvalidCACert(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("writing test CA cert: %v", err)
+	}
+
+	_, err := newTransport(Config{URL: "https://localhost:9200", CACertPath: path})
+	if err == nil {
+		t.Fatal("expected an error for a CA cert file with no certificates in it")
+	}
+}
+
+// testCACertPEM is a self-signed certificate used only to exercise
+// newTransport's PEM parsing; it isn't used to verify anything for real.
+const testCACertPEM = `-----BEGIN CERTIFICATE-----
+MIIBeTCCAR+gAwIBAgIUa/KBgIfHFhxOT/cvrO3A0kRiFlAwCgYIKoZIzj0EAwIw
+EjEQMA4GA1UECgwHQWNtZSBDbzAeFw0yNjA4MDgxNjU2MzhaFw0zNjA4MDUxNjU2
+MzhaMBIxEDAOBgNVBAoMB0FjbWUgQ28wWTATBgcqhkjOPQIBBggqhkjOPQMBBwNC
+AARyRPU7BPboUXITELSc5AGLH6ue53Bz0/dhSuuC1j1jTdD3ITGED9mLAX8VEeHg
+23Xr9CAy046EKW7fJkyhsKVHo1MwUTAdBgNVHQ4EFgQUNtcIKH9GF5E65aKLacAJ
+SqnJ+tEwHwYDVR0jBBgwFoAUNtcIKH9GF5E65aKLacAJSqnJ+tEwDwYDVR0TAQH/
+BAUwAwEB/zAKBggqhkjOPQQDAgNIADBFAiAvBCYyy4JUkIUzI/68pdMc6RtiPEQM
+gQRlrpdouDBTDgIhAJ6OgihLGa+iHSwbGTjFredkDfPBywbfJ4T6G0Cg91dN
+-----END CERTIFICATE-----`
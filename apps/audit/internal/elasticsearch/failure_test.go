@@ -0,0 +1,92 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+)
+
+// fakeSink records every FailedDocument it's sent, for asserting on
+// routing decisions without a live Kafka topic or file.
+type fakeSink struct {
+	docs []dlq.FailedDocument
+}
+
+func (f *fakeSink) SendToDeadLetter(ctx context.Context, doc dlq.FailedDocument) error {
+	f.docs = append(f.docs, doc)
+	return nil
+}
+
+func TestOnIndexFailure_BelowMaxRetriesGoesToDLQ(t *testing.T) {
+	dlqSink := &fakeSink{}
+	quarantine := &fakeSink{}
+	c := &Client{dlq: dlqSink, quarantine: quarantine, dlqMaxRetries: 3}
+
+	c.onIndexFailure(context.Background(), esutil.BulkIndexerItem{DocumentID: "tx-1"}, esutil.BulkIndexerResponseItem{}, errors.New("boom"), []byte("{}"), dlq.SourceMeta{})
+
+	if len(dlqSink.docs) != 1 {
+		t.Fatalf("dlq sink got %d documents, want 1", len(dlqSink.docs))
+	}
+	if len(quarantine.docs) != 0 {
+		t.Fatalf("quarantine sink got %d documents, want 0", len(quarantine.docs))
+	}
+	if dlqSink.docs[0].RetryCount != 1 {
+		t.Fatalf("RetryCount = %d, want 1", dlqSink.docs[0].RetryCount)
+	}
+}
+
+func TestOnIndexFailure_AtMaxRetriesGoesToQuarantine(t *testing.T) {
+	dlqSink := &fakeSink{}
+	quarantine := &fakeSink{}
+	c := &Client{dlq: dlqSink, quarantine: quarantine, dlqMaxRetries: 2}
+
+	headers := dlq.WithRetryHeaders(nil, 1, []dlq.FailureRecord{{ErrorType: "mapper_parsing_exception", Reason: "first failure"}})
+	meta := dlq.SourceMeta{Topic: "transaction-events", Headers: headers}
+
+	c.onIndexFailure(context.Background(), esutil.BulkIndexerItem{DocumentID: "tx-2"}, esutil.BulkIndexerResponseItem{}, errors.New("still broken"), []byte("{}"), meta)
+
+	if len(dlqSink.docs) != 0 {
+		t.Fatalf("dlq sink got %d documents, want 0", len(dlqSink.docs))
+	}
+	if len(quarantine.docs) != 1 {
+		t.Fatalf("quarantine sink got %d documents, want 1", len(quarantine.docs))
+	}
+	doc := quarantine.docs[0]
+	if doc.RetryCount != 2 {
+		t.Fatalf("RetryCount = %d, want 2", doc.RetryCount)
+	}
+	if len(doc.ErrorHistory) != 2 {
+		t.Fatalf("ErrorHistory has %d entries, want 2", len(doc.ErrorHistory))
+	}
+}
+
+func TestOnIndexFailure_ZeroMaxRetriesNeverQuarantines(t *testing.T) {
+	dlqSink := &fakeSink{}
+	quarantine := &fakeSink{}
+	c := &Client{dlq: dlqSink, quarantine: quarantine, dlqMaxRetries: 0}
+
+	headers := dlq.WithRetryHeaders(nil, 50, nil)
+	c.onIndexFailure(context.Background(), esutil.BulkIndexerItem{DocumentID: "tx-3"}, esutil.BulkIndexerResponseItem{}, errors.New("boom"), []byte("{}"), dlq.SourceMeta{Headers: headers})
+
+	if len(quarantine.docs) != 0 {
+		t.Fatalf("quarantine sink got %d documents, want 0 when DLQMaxRetries is disabled", len(quarantine.docs))
+	}
+	if len(dlqSink.docs) != 1 {
+		t.Fatalf("dlq sink got %d documents, want 1", len(dlqSink.docs))
+	}
+}
+
+func TestOnIndexFailure_VersionConflictSkipsDLQEntirely(t *testing.T) {
+	dlqSink := &fakeSink{}
+	c := &Client{dlq: dlqSink, dlqMaxRetries: 1}
+
+	c.onIndexFailure(context.Background(), esutil.BulkIndexerItem{DocumentID: "tx-4"}, esutil.BulkIndexerResponseItem{Status: 409}, nil, []byte("{}"), dlq.SourceMeta{})
+
+	if len(dlqSink.docs) != 0 {
+		t.Fatalf("dlq sink got %d documents, want 0 for a version conflict", len(dlqSink.docs))
+	}
+}
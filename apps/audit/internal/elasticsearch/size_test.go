@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/model"
+)
+
+func TestTruncateForSize_FitsAlready(t *testing.T) {
+	doc := model.TransactionDocument{TransactionID: "tx-1", Description: "short"}
+
+	got, body, ok := truncateForSize(doc, 1<<20)
+	if !ok {
+		t.Fatal("truncateForSize: ok = false, want true")
+	}
+	if got.Description != "short" {
+		t.Fatalf("Description = %q, want unchanged", got.Description)
+	}
+
+	var decoded model.TransactionDocument
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if decoded.TransactionID != "tx-1" {
+		t.Fatalf("TransactionID = %q, want %q", decoded.TransactionID, "tx-1")
+	}
+}
+
+func TestTruncateForSize_ShortensOversizedDescription(t *testing.T) {
+	doc := model.TransactionDocument{TransactionID: "tx-2", Description: strings.Repeat("x", 1000)}
+
+	got, body, ok := truncateForSize(doc, 200)
+	if !ok {
+		t.Fatal("truncateForSize: ok = false, want true")
+	}
+	if len(body) > 200 {
+		t.Fatalf("body is %d bytes, want <= 200", len(body))
+	}
+	if !strings.HasSuffix(got.Description, truncationSuffix) {
+		t.Fatalf("Description = %q, want it to end with %q", got.Description, truncationSuffix)
+	}
+	if len(got.Description) >= 1000 {
+		t.Fatalf("Description wasn't shortened, len = %d", len(got.Description))
+	}
+}
+
+func TestTruncateForSize_StillOversizedWithoutDescription(t *testing.T) {
+	doc := model.TransactionDocument{TransactionID: strings.Repeat("x", 1000)}
+
+	_, body, ok := truncateForSize(doc, 200)
+	if ok {
+		t.Fatal("truncateForSize: ok = true, want false when the overage isn't in Description")
+	}
+	if len(body) <= 200 {
+		t.Fatalf("body is %d bytes, want it to still exceed the 200 byte limit", len(body))
+	}
+}
+
+func TestIndexTransaction_StillOversizedAfterTruncationGoesToDLQ(t *testing.T) {
+	dlqSink := &fakeSink{}
+	c := &Client{dlq: dlqSink, maxDocumentBytes: 200}
+
+	doc := model.TransactionDocument{
+		TransactionID: strings.Repeat("x", 1000),
+		Description:   "a description that would normally just get truncated",
+	}
+
+	resolved := false
+	err := c.IndexTransaction(context.Background(), doc, dlq.SourceMeta{Topic: "transaction-events"}, func() { resolved = true })
+	if err != nil {
+		t.Fatalf("IndexTransaction: %v", err)
+	}
+	if !resolved {
+		t.Fatal("expected onResolved to be called")
+	}
+	if len(dlqSink.docs) != 1 {
+		t.Fatalf("dlq sink got %d documents, want 1", len(dlqSink.docs))
+	}
+	if dlqSink.docs[0].ErrorType != "document_too_large" {
+		t.Fatalf("ErrorType = %q, want %q", dlqSink.docs[0].ErrorType, "document_too_large")
+	}
+}
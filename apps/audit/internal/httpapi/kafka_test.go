@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeKafkaReader struct {
+	stats kafka.ReaderStats
+}
+
+func (f fakeKafkaReader) Stats() kafka.ReaderStats {
+	return f.stats
+}
+
+// fakeConn is the minimal net.Conn a successful dial needs to return;
+// only Close is ever called on it.
+type fakeConn struct {
+	net.Conn
+}
+
+func (fakeConn) Close() error { return nil }
+
+func TestCheckKafka_ReachableAndFetchedIsHealthy(t *testing.T) {
+	s := &Server{
+		kafkaReader:  fakeKafkaReader{stats: kafka.ReaderStats{Fetches: 3, Rebalances: 1}},
+		kafkaBrokers: []string{"broker-1:9092"},
+		kafkaDial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return fakeConn{}, nil
+		},
+	}
+
+	health := s.checkKafka(context.Background())
+
+	if !health.Reachable {
+		t.Fatal("expected Reachable = true")
+	}
+	if !health.PartitionsAssigned {
+		t.Fatal("expected PartitionsAssigned = true")
+	}
+	if health.Rebalances != 1 {
+		t.Fatalf("Rebalances = %d, want 1", health.Rebalances)
+	}
+}
+
+func TestCheckKafka_NoFetchesYetIsNotAssigned(t *testing.T) {
+	s := &Server{
+		kafkaReader:  fakeKafkaReader{stats: kafka.ReaderStats{Fetches: 0}},
+		kafkaBrokers: []string{"broker-1:9092"},
+		kafkaDial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return fakeConn{}, nil
+		},
+	}
+
+	health := s.checkKafka(context.Background())
+
+	if !health.Reachable {
+		t.Fatal("expected Reachable = true")
+	}
+	if health.PartitionsAssigned {
+		t.Fatal("expected PartitionsAssigned = false before any fetch")
+	}
+}
+
+func TestCheckKafka_UnreachableBrokerReportsError(t *testing.T) {
+	errDial := errors.New("connection refused")
+	s := &Server{
+		kafkaReader:  fakeKafkaReader{stats: kafka.ReaderStats{Fetches: 5}},
+		kafkaBrokers: []string{"broker-1:9092", "broker-2:9092"},
+		kafkaDial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errDial
+		},
+	}
+
+	health := s.checkKafka(context.Background())
+
+	if health.Reachable {
+		t.Fatal("expected Reachable = false")
+	}
+	if health.Error == "" {
+		t.Fatal("expected a non-empty Error describing the dial failure")
+	}
+}
+
+func TestCheckKafka_NilReaderReportsUnassigned(t *testing.T) {
+	s := &Server{
+		kafkaBrokers: []string{"broker-1:9092"},
+		kafkaDial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return fakeConn{}, nil
+		},
+	}
+
+	health := s.checkKafka(context.Background())
+
+	if health.PartitionsAssigned {
+		t.Fatal("expected PartitionsAssigned = false with no reader configured")
+	}
+}
@@ -0,0 +1,313 @@
+// Package httpapi serves the audit service's operational HTTP endpoints:
+// health/readiness, indexing stats, and Prometheus metrics. It is separate
+// from the Kafka consumer loop so a slow or stuck consumer doesn't also
+// take down observability.
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/elasticsearch"
+)
+
+// AdminTokenHeader carries the shared secret that authorizes destructive
+// admin endpoints, mirroring the gateway's admin auth (see
+// middleware.AdminTokenHeader there).
+const AdminTokenHeader = "X-Admin-Token"
+
+// sentCounter is implemented by DeadLetterSinks that track how many
+// documents they've successfully sent, for /stats reporting. Not every
+// DeadLetterSink needs to support this, so it's checked with a type
+// assertion rather than added to the interface itself.
+type sentCounter interface {
+	SentCount() int64
+}
+
+// Server exposes /health, /stats, and /metrics over HTTP.
+type Server struct {
+	httpServer *http.Server
+
+	esClient *elasticsearch.Client
+	dlq      dlq.DeadLetterSink
+
+	// FailureRateThreshold is the fraction (0-1) of indexing attempts that
+	// may fail before /health reports unready. A broken ES mapping or
+	// credential typically drives this to 1.0 quickly.
+	FailureRateThreshold float64
+
+	adminAllowedNets []*net.IPNet
+	adminToken       string
+
+	kafkaReader  kafkaReader
+	kafkaBrokers []string
+	kafkaDial    brokerDialer
+}
+
+// New builds a Server listening on addr. adminAllowedCIDRs gates every
+// admin endpoint (/admin/flush, /admin/erase); a caller whose IP doesn't
+// match any of them gets 403, and an empty list closes the endpoints to
+// everyone. adminToken additionally gates /admin/erase, since it's
+// destructive. It returns an error if any CIDR fails to parse.
+//
+// kafkaReader and kafkaDialer back /health's Kafka check: kafkaReader is
+// the consumer's own *kafka.Reader, and kafkaDialer should be the same
+// dialer the consumer uses to reach kafkaBrokers, so the check fails
+// exactly when the consumer itself would.
+//
+// pprofEnabled registers net/http/pprof's handlers under /debug/pprof,
+// gated by the same adminAllowedCIDRs as the other admin endpoints, so a
+// deployment that hasn't opted in never exposes them at all.
+func New(addr string, esClient *elasticsearch.Client, dlqSink dlq.DeadLetterSink, failureRateThreshold float64, adminAllowedCIDRs []string, adminToken string, pprofEnabled bool, reader *kafka.Reader, brokers []string, dialer *kafka.Dialer) (*Server, error) {
+	nets, err := parseCIDRs(adminAllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		esClient:             esClient,
+		dlq:                  dlqSink,
+		FailureRateThreshold: failureRateThreshold,
+		adminAllowedNets:     nets,
+		adminToken:           adminToken,
+		kafkaBrokers:         brokers,
+		kafkaDial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+	// Assigning a nil *kafka.Reader directly to the kafkaReader interface
+	// field would make it non-nil (a typed nil), so checkKafka's "reader
+	// not configured" check only works if the assignment is skipped
+	// outright.
+	if reader != nil {
+		s.kafkaReader = reader
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/stats", s.handleStats)
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/admin/flush", s.requireAllowedIP(s.handleFlush))
+	mux.HandleFunc("/admin/erase", s.requireAllowedIP(s.requireAdminToken(s.handleErase)))
+
+	if pprofEnabled {
+		mux.HandleFunc("/debug/pprof/", s.requireAllowedIP(pprof.Index))
+		mux.HandleFunc("/debug/pprof/cmdline", s.requireAllowedIP(pprof.Cmdline))
+		mux.HandleFunc("/debug/pprof/profile", s.requireAllowedIP(pprof.Profile))
+		mux.HandleFunc("/debug/pprof/symbol", s.requireAllowedIP(pprof.Symbol))
+		mux.HandleFunc("/debug/pprof/trace", s.requireAllowedIP(pprof.Trace))
+	}
+
+	s.httpServer = &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	return s, nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("httpapi: parse CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// requireAllowedIP wraps next so it's only reachable from a caller whose IP
+// matches one of the server's adminAllowedNets. An empty adminAllowedNets
+// rejects every caller, so the admin surface -- and, when enabled,
+// /debug/pprof -- is closed by default rather than open by default.
+func (s *Server) requireAllowedIP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+
+		allowed := false
+		if ip != nil {
+			for _, n := range s.adminAllowedNets {
+				if n.Contains(ip) {
+					allowed = true
+					break
+				}
+			}
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// statsResponse is the shape returned by /stats, and embedded in
+// healthResponse for /health.
+type statsResponse struct {
+	NumAdded    uint64  `json:"num_added"`
+	NumFlushed  uint64  `json:"num_flushed"`
+	NumFailed   uint64  `json:"num_failed"`
+	FailureRate float64 `json:"failure_rate"`
+	DLQSent     int64   `json:"dlq_sent"`
+}
+
+func (s *Server) snapshot() statsResponse {
+	stats := s.esClient.Stats()
+
+	var failureRate float64
+	if stats.NumAdded > 0 {
+		failureRate = float64(stats.NumFailed) / float64(stats.NumAdded)
+	}
+
+	var dlqSent int64
+	if counter, ok := s.dlq.(sentCounter); ok {
+		dlqSent = counter.SentCount()
+	}
+
+	return statsResponse{
+		NumAdded:    stats.NumAdded,
+		NumFlushed:  stats.NumFlushed,
+		NumFailed:   stats.NumFailed,
+		FailureRate: failureRate,
+		DLQSent:     dlqSent,
+	}
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.snapshot())
+}
+
+// requireAdminToken wraps next so it's only reachable with a valid
+// X-Admin-Token header. An empty s.adminToken rejects every caller, so an
+// unconfigured token fails closed rather than granting free access.
+func (s *Server) requireAdminToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get(AdminTokenHeader)), []byte(s.adminToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// eraseRequest is the body of POST /admin/erase.
+type eraseRequest struct {
+	AccountID string                  `json:"account_id"`
+	Mode      elasticsearch.EraseMode `json:"mode"`
+}
+
+// handleErase erases an account's trace from the index for a data-subject
+// erasure request, per eraseRequest.Mode. The operation is logged here
+// (account, mode, documents affected, caller) as the service's own audit
+// trail of a destructive admin action, separate from the documents it
+// just removed or anonymized.
+func (s *Server) handleErase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req eraseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.AccountID == "" {
+		http.Error(w, "account_id is required", http.StatusBadRequest)
+		return
+	}
+	switch req.Mode {
+	case elasticsearch.EraseModeDelete, elasticsearch.EraseModeAnonymize:
+	default:
+		http.Error(w, `mode must be "delete" or "anonymize"`, http.StatusBadRequest)
+		return
+	}
+
+	affected, err := s.esClient.EraseAccount(r.Context(), req.AccountID, req.Mode)
+	if err != nil {
+		log.Printf("httpapi: erase account %s failed: %v", req.AccountID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	log.Printf("audit-trail: erased account=%s mode=%s affected=%d caller=%s", req.AccountID, req.Mode, affected, r.RemoteAddr)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"account_id": req.AccountID,
+		"mode":       req.Mode,
+		"affected":   affected,
+	})
+}
+
+// handleFlush forces the bulk indexer to send its pending items
+// immediately, instead of waiting for its FlushInterval, and returns the
+// stats accumulated since the last flush. Intended for controlled
+// failovers, where pending documents need to be visible in ES right away.
+func (s *Server) handleFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := s.esClient.Flush(r.Context())
+	if err != nil {
+		log.Printf("httpapi: flush failed: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// healthResponse is /health's body: the ES/bulk-indexer snapshot also
+// returned by /stats, plus the Kafka consumer's own status, combined so a
+// single endpoint gates readiness on every dependency the service can't
+// function without.
+type healthResponse struct {
+	statsResponse
+	Kafka kafkaHealth `json:"kafka"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	snap := s.snapshot()
+	kafka := s.checkKafka(r.Context())
+
+	status := http.StatusOK
+	if s.FailureRateThreshold > 0 && snap.FailureRate > s.FailureRateThreshold {
+		status = http.StatusServiceUnavailable
+	}
+	if !kafka.Reachable || !kafka.PartitionsAssigned {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, healthResponse{statsResponse: snap, Kafka: kafka})
+}
+
+// Start runs the HTTP server until it errors or is shut down.
+func (s *Server) Start() error {
+	err := s.httpServer.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully stops the HTTP server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.httpServer.Shutdown(shutdownCtx)
+}
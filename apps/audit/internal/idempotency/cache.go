@@ -0,0 +1,73 @@
+// Package idempotency tracks recently seen message identifiers so the
+// audit consumer can recognize a redelivered event without relying on
+// Kafka offsets, which reset on reprocessing.
+package idempotency
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a fixed-size, in-process set of recently seen IDs, each
+// remembered for up to window before it's treated as unseen again. It
+// evicts the least recently seen entry once full, same as an LRU cache,
+// and also drops an entry early if it's outlived window. It's a
+// best-effort dedup layer: a restart, the capacity bound, or window both
+// mean a duplicate can be missed and reprocessed, which is safe since
+// indexing is already idempotent (see
+// elasticsearch.Client.IndexTransaction's external versioning) -- this
+// cache exists to skip redundant enrichment and side-effect work, not to
+// guarantee exactly-once delivery.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	window   time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type entry struct {
+	key       string
+	expiresAt time.Time
+}
+
+// NewCache returns a Cache holding at most capacity IDs, each remembered
+// for up to window.
+func NewCache(capacity int, window time.Duration) *Cache {
+	return &Cache{
+		capacity: capacity,
+		window:   window,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// SeenOrRemember reports whether id was already recorded within the last
+// window, and (re)starts its window in either case.
+func (c *Cache) SeenOrRemember(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if elem, ok := c.items[id]; ok {
+		e := elem.Value.(*entry)
+		seen := now.Before(e.expiresAt)
+		e.expiresAt = now.Add(c.window)
+		c.order.MoveToFront(elem)
+		return seen
+	}
+
+	elem := c.order.PushFront(&entry{key: id, expiresAt: now.Add(c.window)})
+	c.items[id] = elem
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*entry).key)
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheSeenOrRememberDetectsDuplicate(t *testing.T) {
+	c := NewCache(8, time.Minute)
+
+	if c.SeenOrRemember("id-1") {
+		t.Fatal("expected id-1 to be unseen on first sighting")
+	}
+	if !c.SeenOrRemember("id-1") {
+		t.Fatal("expected id-1 to be seen on second sighting")
+	}
+}
+
+func TestCacheSeenOrRememberEvictsOldest(t *testing.T) {
+	c := NewCache(2, time.Minute)
+
+	c.SeenOrRemember("id-1")
+	c.SeenOrRemember("id-2")
+	c.SeenOrRemember("id-3")
+
+	if c.SeenOrRemember("id-1") {
+		t.Fatal("expected id-1 to have been evicted")
+	}
+}
+
+func TestCacheSeenOrRememberExpiresAfterWindow(t *testing.T) {
+	c := NewCache(8, time.Millisecond)
+
+	if c.SeenOrRemember("id-1") {
+		t.Fatal("expected id-1 to be unseen on first sighting")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if c.SeenOrRemember("id-1") {
+		t.Fatal("expected id-1 to have fallen outside its window")
+	}
+}
@@ -0,0 +1,182 @@
+// Package ingest implements the audit service's message-to-document
+// pipeline: unwrapping a Kafka message's CloudEvents envelope, applying
+// idempotency dedup and optional account enrichment, and indexing the
+// result into Elasticsearch. It's shared by the live consumer (cmd/main.go)
+// and the disaster-recovery reindex tool (cmd/reindextool), so a reindex
+// runs through exactly the same logic as the live pipeline rather than a
+// reimplementation that can drift from it.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/accountlookup"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/dlq"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/elasticsearch"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/model"
+)
+
+// duplicatesSkippedTotal counts events skipped by Process because their
+// idempotency key was already seen within the configured window.
+var duplicatesSkippedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "audit_duplicate_events_skipped_total",
+	Help: "Total number of events skipped because their idempotency key was seen within the configured dedup window.",
+})
+
+// Processor indexes Kafka messages carrying transaction-created events
+// into Elasticsearch. Resolver and DLQ may be nil, disabling enrichment
+// and dead-lettering respectively.
+type Processor struct {
+	ES          *elasticsearch.Client
+	Resolver    accountlookup.Resolver
+	DLQ         dlq.DeadLetterSink
+	Idempotency *idempotency.Cache
+}
+
+// Process unwraps msg as either a CloudEvents envelope or a bare
+// TransactionCreatedEvent (see model.UnwrapTransactionEvent) and indexes
+// the result into Elasticsearch. When p.Resolver is non-nil, it's used to
+// enrich the document with each account's owner and currency; a lookup
+// failure is logged and the event is indexed without that enrichment
+// rather than dropped, since a ledger-core hiccup shouldn't stall the
+// audit trail.
+//
+// An envelope naming a type this service doesn't know how to unwrap, or
+// carrying a SchemaVersion newer than model.CurrentSchemaVersion, is sent
+// to p.DLQ instead of returned as an error, since retrying either won't
+// help. An event whose idempotency key (falling back to the CloudEvents ID
+// for events that don't set one) was seen recently by p.Idempotency is
+// assumed to be a redelivery of an event already processed and is
+// skipped, to avoid redoing enrichment and other side effects a harmless
+// re-index wouldn't otherwise need repeated.
+//
+// Process itself doesn't branch on which Kafka topic msg came from --
+// routing is by the CloudEvents envelope's Type, which is the more
+// specific of the two and already works the same way whether the
+// consumer is subscribed to one topic or several (see cmd/main.go's
+// KafkaTopics). A message from any subscribed topic whose type this
+// service doesn't recognize is DLQ'd the same way an unknown type on the
+// usual topic would be, with msg.Topic (via sourceMeta) recorded as the
+// FailedDocument's actual source rather than assumed.
+//
+// resolveOffset is called once msg's outcome is fully decided, so its
+// offset becomes safe to commit; for IndexTransaction that happens
+// asynchronously from a bulk indexer callback, not when this method
+// returns. Callers that don't commit offsets (e.g. cmd/reindextool) can
+// pass a no-op.
+func (p *Processor) Process(ctx context.Context, msg kafka.Message, resolveOffset func(partition int, offset int64)) error {
+	event, cloudEventID, err := model.UnwrapTransactionEvent(msg.Value)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUnknownEventType):
+			sendErr := p.sendToDLQ(ctx, cloudEventID, "unknown_event_type", err, msg)
+			resolveOffset(msg.Partition, msg.Offset)
+			return sendErr
+		case errors.Is(err, model.ErrUnsupportedSchemaVersion):
+			sendErr := p.sendToDLQ(ctx, cloudEventID, "schema_version_unsupported", err, msg)
+			resolveOffset(msg.Partition, msg.Offset)
+			return sendErr
+		}
+		return err
+	}
+
+	dedupKey := event.IdempotencyKey
+	if dedupKey == "" {
+		dedupKey = cloudEventID
+	}
+	if dedupKey != "" && p.Idempotency.SeenOrRemember(dedupKey) {
+		duplicatesSkippedTotal.Inc()
+		log.Printf("audit: skipping duplicate delivery of event with idempotency key %s", dedupKey)
+		resolveOffset(msg.Partition, msg.Offset)
+		return nil
+	}
+
+	doc := model.FromEvent(event)
+	if doc.Version == 0 {
+		// The publisher didn't set a version; the Kafka offset is itself
+		// monotonic within a partition and serves as a reasonable stand-in.
+		doc.Version = msg.Offset + 1
+	}
+
+	if p.Resolver != nil {
+		enrichDocument(ctx, p.Resolver, &doc)
+	}
+
+	return p.ES.IndexTransaction(ctx, doc, sourceMeta(msg), func() {
+		resolveOffset(msg.Partition, msg.Offset)
+	})
+}
+
+// sourceMeta builds the dlq.SourceMeta recorded alongside any
+// FailedDocument produced while processing msg, so a DLQ entry can be
+// traced back to its exact source record.
+func sourceMeta(msg kafka.Message) dlq.SourceMeta {
+	return dlq.SourceMeta{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Headers:   headersToMap(msg.Headers),
+	}
+}
+
+// headersToMap flattens kafka.Message's []Header into the map form
+// dlq.SourceMeta and FailedDocument use. A repeated header key is
+// collapsed to its last value.
+func headersToMap(headers []kafka.Header) map[string][]byte {
+	if len(headers) == 0 {
+		return nil
+	}
+	m := make(map[string][]byte, len(headers))
+	for _, h := range headers {
+		m[h.Key] = h.Value
+	}
+	return m
+}
+
+// sendToDLQ routes a message this service can't process to p.DLQ under
+// errorType (e.g. "unknown_event_type", "schema_version_unsupported"),
+// since there's no transaction to extract from it and retrying won't
+// help.
+func (p *Processor) sendToDLQ(ctx context.Context, cloudEventID, errorType string, cause error, msg kafka.Message) error {
+	if p.DLQ == nil {
+		return cause
+	}
+	meta := sourceMeta(msg)
+	return p.DLQ.SendToDeadLetter(ctx, dlq.FailedDocument{
+		DocumentID:  cloudEventID,
+		SourceTopic: meta.Topic,
+		Partition:   meta.Partition,
+		Offset:      meta.Offset,
+		Headers:     meta.Headers,
+		ErrorType:   errorType,
+		Reason:      cause.Error(),
+		FailedAt:    time.Now().UTC(),
+		Payload:     msg.Value,
+	})
+}
+
+// enrichDocument populates doc's owner/currency fields from resolver,
+// logging and leaving them unset on a lookup failure for either account.
+func enrichDocument(ctx context.Context, resolver accountlookup.Resolver, doc *model.TransactionDocument) {
+	if from, err := resolver.Resolve(ctx, doc.FromAccountID); err != nil {
+		log.Printf("audit: account lookup failed for %s: %v", doc.FromAccountID, err)
+	} else {
+		doc.FromAccountOwnerID = from.OwnerUserID
+		doc.FromAccountCurrency = from.Currency
+	}
+
+	if to, err := resolver.Resolve(ctx, doc.ToAccountID); err != nil {
+		log.Printf("audit: account lookup failed for %s: %v", doc.ToAccountID, err)
+	} else {
+		doc.ToAccountOwnerID = to.OwnerUserID
+		doc.ToAccountCurrency = to.Currency
+	}
+}
@@ -0,0 +1,170 @@
+// Package kafkaauth builds the Kafka dialer/transport the audit service's
+// reader and DLQ producer connect through, honoring SASL authentication
+// and TLS when configured. When SASL is disabled it behaves exactly like
+// the zero-value kafka-go defaults.
+package kafkaauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"github.com/ChungTau/double-entry-ledger/apps/audit/internal/tlsconfig"
+)
+
+// Config is the audit service's Kafka SASL/TLS configuration, driven by
+// env vars (see LoadFromEnv).
+type Config struct {
+	SASLMechanism string // "", "PLAIN", "SCRAM-SHA-256", or "SCRAM-SHA-512"
+	SASLUsername  string
+	SASLPassword  string
+	TLSEnabled    bool
+	CACertPath    string
+	// TLSMinVersion and TLSCipherSuites set the compliance baseline applied
+	// via tlsconfig.Build -- "1.2" or "1.3", and an allowlist of standard
+	// Go cipher suite names. Both are ignored unless TLSEnabled.
+	TLSMinVersion   string
+	TLSCipherSuites []string
+}
+
+// LoadFromEnv reads KAFKA_SASL_MECHANISM, KAFKA_SASL_USERNAME,
+// KAFKA_SASL_PASSWORD, KAFKA_TLS_ENABLED, KAFKA_TLS_CA_CERT_PATH,
+// KAFKA_TLS_MIN_VERSION, and KAFKA_TLS_CIPHER_SUITES.
+func LoadFromEnv() Config {
+	return Config{
+		SASLMechanism:   os.Getenv("KAFKA_SASL_MECHANISM"),
+		SASLUsername:    os.Getenv("KAFKA_SASL_USERNAME"),
+		SASLPassword:    os.Getenv("KAFKA_SASL_PASSWORD"),
+		TLSEnabled:      os.Getenv("KAFKA_TLS_ENABLED") == "true",
+		CACertPath:      os.Getenv("KAFKA_TLS_CA_CERT_PATH"),
+		TLSMinVersion:   os.Getenv("KAFKA_TLS_MIN_VERSION"),
+		TLSCipherSuites: splitCSV(os.Getenv("KAFKA_TLS_CIPHER_SUITES")),
+	}
+}
+
+// splitCSV splits a comma-separated env var into its entries, trimming
+// whitespace around each and dropping empty ones, so an unset var yields
+// nil rather than a slice holding one empty string.
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// Validate fails clearly when a SASL mechanism is set but credentials are
+// missing, instead of connecting and failing with an opaque broker error.
+func (c Config) Validate() error {
+	if c.SASLMechanism == "" {
+		return nil
+	}
+	if c.SASLUsername == "" || c.SASLPassword == "" {
+		return fmt.Errorf("kafkaauth: KAFKA_SASL_MECHANISM=%s requires KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD", c.SASLMechanism)
+	}
+	return nil
+}
+
+func (c Config) mechanism() (sasl.Mechanism, error) {
+	if c.SASLMechanism == "" {
+		return nil, nil
+	}
+	switch c.SASLMechanism {
+	case "PLAIN":
+		return plain.Mechanism{Username: c.SASLUsername, Password: c.SASLPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, c.SASLUsername, c.SASLPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, c.SASLUsername, c.SASLPassword)
+	default:
+		return nil, fmt.Errorf("kafkaauth: unsupported KAFKA_SASL_MECHANISM %q", c.SASLMechanism)
+	}
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLSEnabled {
+		return nil, nil
+	}
+	tlsCfg, err := tlsconfig.Build(tlsconfig.Config{
+		MinVersion:   c.TLSMinVersion,
+		CipherSuites: c.TLSCipherSuites,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafkaauth: building TLS config: %w", err)
+	}
+	if c.CACertPath == "" {
+		return tlsCfg, nil
+	}
+
+	caCert, err := os.ReadFile(c.CACertPath)
+	if err != nil {
+		return nil, fmt.Errorf("kafkaauth: reading CA cert %s: %w", c.CACertPath, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("kafkaauth: no valid certificates found in %s", c.CACertPath)
+	}
+	tlsCfg.RootCAs = pool
+	return tlsCfg, nil
+}
+
+// Dialer returns a kafka.Dialer configured for the reader connection. When
+// neither SASL nor TLS is configured, this is equivalent to kafka-go's
+// default dialer.
+func (c Config) Dialer() (*kafka.Dialer, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	mechanism, err := c.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		SASLMechanism: mechanism,
+		TLS:           tlsCfg,
+	}, nil
+}
+
+// Transport returns a kafka.Transport configured the same way as Dialer,
+// for use by writers (e.g. the DLQ producer) which don't take a Dialer.
+func (c Config) Transport() (*kafka.Transport, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	mechanism, err := c.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Transport{
+		SASL: mechanism,
+		TLS:  tlsCfg,
+	}, nil
+}
@@ -0,0 +1,21 @@
+// Code generated from api/proto/v1/ledger.proto. Regenerate with `make proto`.
+// Hand-maintained until the protoc toolchain is wired into CI — keep in sync
+// with the .proto source.
+
+// Package ledgerpb contains the request/response types and client stub for
+// the subset of ledger.v1.LedgerService the audit service calls. See
+// apps/gateway/internal/ledgerpb for the gateway's fuller client of the
+// same service.
+package ledgerpb
+
+type GetAccountRequest struct {
+	AccountId string
+}
+
+type AccountResponse struct {
+	Id       string
+	UserId   string
+	Currency string
+	Balance  string
+	Version  int64
+}
@@ -0,0 +1,37 @@
+// Code generated from api/proto/v1/ledger.proto. Regenerate with `make proto`.
+
+package ledgerpb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	ServiceName = "ledger.v1.LedgerService"
+
+	MethodGetAccount = "/ledger.v1.LedgerService/GetAccount"
+)
+
+// LedgerServiceClient is the client API for the subset of LedgerService the
+// audit service calls.
+type LedgerServiceClient interface {
+	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error)
+}
+
+type ledgerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLedgerServiceClient(cc grpc.ClientConnInterface) LedgerServiceClient {
+	return &ledgerServiceClient{cc: cc}
+}
+
+func (c *ledgerServiceClient) GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error) {
+	out := new(AccountResponse)
+	if err := c.cc.Invoke(ctx, MethodGetAccount, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// TransactionCreatedEventType is the CloudEvents "type" attribute used for a
+// transaction-created event. It's the only type UnwrapTransactionEvent
+// currently accepts.
+const TransactionCreatedEventType = "com.doubleentryledger.transaction.created"
+
+// ErrUnknownEventType is returned by UnwrapTransactionEvent when a
+// CloudEvents envelope names a type it doesn't know how to unwrap. Callers
+// should route the raw message to the DLQ rather than treat it as a
+// transient processing error.
+var ErrUnknownEventType = errors.New("model: unknown cloudevents type")
+
+// CurrentSchemaVersion is the highest TransactionCreatedEvent.SchemaVersion
+// this service knows how to map to TransactionDocument. Every version up
+// to and including this one maps to the current document shape as-is --
+// there's been no field removal or incompatible rename yet, just
+// additions with omitempty defaults -- so this is a ceiling check, not a
+// per-version dispatch table; the latter only becomes necessary the day a
+// new version changes a field's meaning instead of just adding one.
+const CurrentSchemaVersion = 1
+
+// ErrUnsupportedSchemaVersion is returned by UnwrapTransactionEvent when
+// an event's SchemaVersion is newer than CurrentSchemaVersion. Callers
+// should route the raw message to the DLQ rather than index it: a field
+// this service doesn't know about yet might change the meaning of fields
+// it does, e.g. a future "tenant" field splitting what was one global
+// ledger into several. Retrying won't help until this service is upgraded
+// to understand the version.
+var ErrUnsupportedSchemaVersion = errors.New("model: unsupported schema version")
+
+// cloudEventEnvelope is the subset of the CloudEvents JSON envelope this
+// service cares about: https://github.com/cloudevents/spec. Fields beyond
+// these (datacontenttype, time, subject, ...) are ignored.
+type cloudEventEnvelope struct {
+	SpecVersion string          `json:"specversion"`
+	ID          string          `json:"id"`
+	Source      string          `json:"source"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// UnwrapTransactionEvent parses raw as either a CloudEvents envelope or a
+// bare TransactionCreatedEvent, returning the event and the CloudEvents ID
+// (empty for the bare legacy format, which carries no such identifier).
+//
+// A message is treated as a CloudEvents envelope when it has a non-empty
+// "specversion"; this is what lets both formats coexist on the same topic
+// during the migration to CloudEvents. An envelope with a "type" other than
+// TransactionCreatedEventType returns ErrUnknownEventType, since there's
+// nothing in data this service knows how to interpret. Either format
+// returns ErrUnsupportedSchemaVersion if the unwrapped event's
+// SchemaVersion is newer than CurrentSchemaVersion.
+func UnwrapTransactionEvent(raw []byte) (TransactionCreatedEvent, string, error) {
+	var envelope cloudEventEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return TransactionCreatedEvent{}, "", fmt.Errorf("model: unmarshal message: %w", err)
+	}
+
+	if envelope.SpecVersion == "" {
+		var event TransactionCreatedEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return TransactionCreatedEvent{}, "", fmt.Errorf("model: unmarshal legacy event: %w", err)
+		}
+		if err := checkSchemaVersion(event); err != nil {
+			return TransactionCreatedEvent{}, "", err
+		}
+		return event, "", nil
+	}
+
+	if envelope.Type != TransactionCreatedEventType {
+		return TransactionCreatedEvent{}, envelope.ID, fmt.Errorf("%w: %q", ErrUnknownEventType, envelope.Type)
+	}
+
+	var event TransactionCreatedEvent
+	if err := json.Unmarshal(envelope.Data, &event); err != nil {
+		return TransactionCreatedEvent{}, envelope.ID, fmt.Errorf("model: unmarshal cloudevents data: %w", err)
+	}
+	if err := checkSchemaVersion(event); err != nil {
+		return TransactionCreatedEvent{}, envelope.ID, err
+	}
+	return event, envelope.ID, nil
+}
+
+// checkSchemaVersion rejects an event whose SchemaVersion is newer than
+// CurrentSchemaVersion. A zero SchemaVersion (a publisher that predates
+// the field) is always accepted.
+func checkSchemaVersion(event TransactionCreatedEvent) error {
+	if event.SchemaVersion > CurrentSchemaVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, event.SchemaVersion)
+	}
+	return nil
+}
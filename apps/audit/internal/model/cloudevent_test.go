@@ -0,0 +1,90 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnwrapTransactionEventLegacyBareFormat(t *testing.T) {
+	raw := []byte(`{"transaction_id":"txn-1","from_account_id":"a","to_account_id":"b","amount":"10.00","currency":"USD"}`)
+
+	event, id, err := UnwrapTransactionEvent(raw)
+	if err != nil {
+		t.Fatalf("UnwrapTransactionEvent: %v", err)
+	}
+	if id != "" {
+		t.Fatalf("expected no cloudevents id for the legacy format, got %q", id)
+	}
+	if event.TransactionID != "txn-1" {
+		t.Fatalf("expected transaction id txn-1, got %q", event.TransactionID)
+	}
+}
+
+func TestUnwrapTransactionEventCloudEventsEnvelope(t *testing.T) {
+	raw := []byte(`{
+		"specversion": "1.0",
+		"id": "ce-123",
+		"source": "ledger-core",
+		"type": "com.doubleentryledger.transaction.created",
+		"data": {"transaction_id":"txn-2","from_account_id":"a","to_account_id":"b","amount":"5.00","currency":"USD"}
+	}`)
+
+	event, id, err := UnwrapTransactionEvent(raw)
+	if err != nil {
+		t.Fatalf("UnwrapTransactionEvent: %v", err)
+	}
+	if id != "ce-123" {
+		t.Fatalf("expected cloudevents id ce-123, got %q", id)
+	}
+	if event.TransactionID != "txn-2" {
+		t.Fatalf("expected transaction id txn-2, got %q", event.TransactionID)
+	}
+}
+
+func TestUnwrapTransactionEventSupportedSchemaVersion(t *testing.T) {
+	raw := []byte(`{"transaction_id":"txn-3","from_account_id":"a","to_account_id":"b","amount":"10.00","currency":"USD","schema_version":1}`)
+
+	event, _, err := UnwrapTransactionEvent(raw)
+	if err != nil {
+		t.Fatalf("UnwrapTransactionEvent: %v", err)
+	}
+	if event.SchemaVersion != CurrentSchemaVersion {
+		t.Fatalf("expected schema version %d, got %d", CurrentSchemaVersion, event.SchemaVersion)
+	}
+}
+
+func TestUnwrapTransactionEventUnsupportedSchemaVersion(t *testing.T) {
+	raw := []byte(`{
+		"specversion": "1.0",
+		"id": "ce-789",
+		"source": "ledger-core",
+		"type": "com.doubleentryledger.transaction.created",
+		"data": {"transaction_id":"txn-4","from_account_id":"a","to_account_id":"b","amount":"5.00","currency":"USD","schema_version":99}
+	}`)
+
+	_, id, err := UnwrapTransactionEvent(raw)
+	if !errors.Is(err, ErrUnsupportedSchemaVersion) {
+		t.Fatalf("expected ErrUnsupportedSchemaVersion, got %v", err)
+	}
+	if id != "ce-789" {
+		t.Fatalf("expected cloudevents id ce-789 even on an unsupported schema version, got %q", id)
+	}
+}
+
+func TestUnwrapTransactionEventUnknownType(t *testing.T) {
+	raw := []byte(`{
+		"specversion": "1.0",
+		"id": "ce-456",
+		"source": "ledger-core",
+		"type": "com.doubleentryledger.account.closed",
+		"data": {}
+	}`)
+
+	_, id, err := UnwrapTransactionEvent(raw)
+	if !errors.Is(err, ErrUnknownEventType) {
+		t.Fatalf("expected ErrUnknownEventType, got %v", err)
+	}
+	if id != "ce-456" {
+		t.Fatalf("expected cloudevents id ce-456 even on an unknown type, got %q", id)
+	}
+}
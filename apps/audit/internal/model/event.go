@@ -0,0 +1,75 @@
+// Package model holds the event and document shapes shared across the
+// audit service's Kafka consumer and Elasticsearch indexer.
+package model
+
+// TransactionCreatedEvent is the event published by ledger-core (or the
+// gateway, on its behalf) to the transaction-events Kafka topic whenever a
+// transaction is booked.
+type TransactionCreatedEvent struct {
+	TransactionID  string `json:"transaction_id"`
+	FromAccountID  string `json:"from_account_id"`
+	ToAccountID    string `json:"to_account_id"`
+	Amount         string `json:"amount"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	BookedAt       string `json:"booked_at"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Description    string `json:"description"`
+	// Version is a monotonically increasing sequence for this transaction's
+	// state, used for ES external versioning so a redelivered or
+	// out-of-order event can't overwrite newer data. Zero means the
+	// publisher didn't set one; callers fall back to the Kafka offset.
+	Version int64 `json:"version"`
+	// SchemaVersion identifies the shape of this event, so the consumer
+	// can tell a producer it doesn't know how to handle apart from one
+	// whose fields it just hasn't been taught to read yet. Zero means the
+	// publisher predates this field and is treated as CurrentSchemaVersion
+	// -- see UnwrapTransactionEvent.
+	SchemaVersion int `json:"schema_version"`
+}
+
+// TransactionDocument is what the audit service indexes into Elasticsearch
+// for a transaction. It currently mirrors TransactionCreatedEvent field for
+// field but is kept as a distinct type since the indexed shape and the
+// wire event are expected to diverge (e.g. enrichment fields).
+type TransactionDocument struct {
+	TransactionID string `json:"transaction_id"`
+	FromAccountID string `json:"from_account_id"`
+	ToAccountID   string `json:"to_account_id"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	BookedAt      string `json:"booked_at"`
+	// Description is omitted for events published before this field
+	// existed; the zero value indexes fine as an empty text field.
+	Description string `json:"description,omitempty"`
+	// Version is the external version used when indexing this document;
+	// see TransactionCreatedEvent.Version.
+	Version int64 `json:"version"`
+
+	// FromAccountOwnerID, FromAccountCurrency, ToAccountOwnerID, and
+	// ToAccountCurrency are populated by an optional ledger-core lookup
+	// (see internal/accountlookup) when enrichment is enabled. All four
+	// are omitted when enrichment is disabled or the lookup failed, rather
+	// than indexed as empty strings, so their absence in a document is
+	// distinguishable from a resolved-but-empty value.
+	FromAccountOwnerID  string `json:"from_account_owner_id,omitempty"`
+	FromAccountCurrency string `json:"from_account_currency,omitempty"`
+	ToAccountOwnerID    string `json:"to_account_owner_id,omitempty"`
+	ToAccountCurrency   string `json:"to_account_currency,omitempty"`
+}
+
+// FromEvent builds the document indexed for a given event.
+func FromEvent(e TransactionCreatedEvent) TransactionDocument {
+	return TransactionDocument{
+		TransactionID: e.TransactionID,
+		FromAccountID: e.FromAccountID,
+		ToAccountID:   e.ToAccountID,
+		Amount:        e.Amount,
+		Currency:      e.Currency,
+		Status:        e.Status,
+		BookedAt:      e.BookedAt,
+		Description:   e.Description,
+		Version:       e.Version,
+	}
+}
@@ -0,0 +1,87 @@
+// Package offsettracker computes the highest Kafka offset per partition
+// that's safe to commit, given that messages can finish being processed
+// out of order.
+//
+// dispatch.Pool shards messages by their Kafka key, not by partition, so
+// two messages from the same partition can land on different worker
+// goroutines and have their Elasticsearch indexing confirmed in either
+// order. Committing the offset of whichever one finishes last would skip
+// past an earlier message that hasn't actually been confirmed yet, so a
+// crash before it's retried would lose it. Tracker holds back an offset
+// until every earlier offset on the same partition has also been
+// confirmed.
+//
+// The reader is expected to call Observe, in read order, before handing a
+// message to dispatch.Pool, so Confirm always has a true starting point
+// for the partition to measure gaps against -- not just whichever offset
+// happens to resolve first.
+package offsettracker
+
+import "sync"
+
+// Tracker is safe for concurrent use by multiple goroutines, since
+// Confirm is called from whichever worker goroutine's Elasticsearch
+// callback resolves a message.
+type Tracker struct {
+	mu        sync.Mutex
+	next      map[int]int64
+	confirmed map[int]map[int64]struct{}
+}
+
+// New returns an empty Tracker.
+func New() *Tracker {
+	return &Tracker{
+		next:      make(map[int]int64),
+		confirmed: make(map[int]map[int64]struct{}),
+	}
+}
+
+// Observe registers that offset on partition has been read from Kafka and
+// is about to be dispatched for processing, before any worker goroutine
+// can possibly call Confirm for it. Partitions are read strictly in
+// order, so the first Observe call for a partition names its true
+// earliest in-flight offset. Without this, Confirm would have no way to
+// tell a genuine gap from "haven't seen anything for this partition yet"
+// -- it would have to treat whichever offset resolves first as the
+// starting point, even though dispatched work finishes out of order.
+func (t *Tracker) Observe(partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.next[partition]; !ok {
+		t.next[partition] = offset
+	}
+}
+
+// Confirm records that offset on partition has been resolved -- indexed,
+// permanently failed and sent to the DLQ, or otherwise accounted for --
+// and is individually safe to commit past. It returns the highest offset
+// now safe to commit for partition, and whether that advanced at all; a
+// commit isn't due yet if offset leaves a gap before it (an earlier
+// offset on the same partition is still unresolved).
+func (t *Tracker) Confirm(partition int, offset int64) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.next[partition]; !ok {
+		t.next[partition] = offset
+	}
+	if t.confirmed[partition] == nil {
+		t.confirmed[partition] = make(map[int64]struct{})
+	}
+	t.confirmed[partition][offset] = struct{}{}
+
+	advanced := false
+	for {
+		if _, ok := t.confirmed[partition][t.next[partition]]; !ok {
+			break
+		}
+		delete(t.confirmed[partition], t.next[partition])
+		t.next[partition]++
+		advanced = true
+	}
+	if !advanced {
+		return 0, false
+	}
+	return t.next[partition] - 1, true
+}
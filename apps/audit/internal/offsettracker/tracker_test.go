@@ -0,0 +1,43 @@
+package offsettracker
+
+import "testing"
+
+func TestConfirmInOrderAdvancesImmediately(t *testing.T) {
+	tr := New()
+
+	if _, ok := tr.Confirm(0, 10); !ok {
+		t.Fatal("expected the first offset to advance the watermark")
+	}
+	watermark, ok := tr.Confirm(0, 11)
+	if !ok || watermark != 11 {
+		t.Fatalf("expected watermark 11, got %d (ok=%v)", watermark, ok)
+	}
+}
+
+func TestConfirmOutOfOrderHoldsBackGap(t *testing.T) {
+	tr := New()
+
+	tr.Confirm(0, 10)
+	if _, ok := tr.Confirm(0, 12); ok {
+		t.Fatal("expected offset 12 to not advance the watermark while 11 is unresolved")
+	}
+
+	watermark, ok := tr.Confirm(0, 11)
+	if !ok || watermark != 12 {
+		t.Fatalf("expected watermark to jump to 12 once the gap closes, got %d (ok=%v)", watermark, ok)
+	}
+}
+
+func TestConfirmTracksPartitionsIndependently(t *testing.T) {
+	tr := New()
+
+	tr.Confirm(0, 100)
+	if _, ok := tr.Confirm(1, 50); !ok {
+		t.Fatal("expected partition 1's first offset to advance independently of partition 0")
+	}
+
+	watermark, ok := tr.Confirm(1, 51)
+	if !ok || watermark != 51 {
+		t.Fatalf("expected partition 1's watermark 51, got %d (ok=%v)", watermark, ok)
+	}
+}
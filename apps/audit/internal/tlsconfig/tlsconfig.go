@@ -0,0 +1,97 @@
+// Package tlsconfig builds a *tls.Config enforcing the audit service's
+// TLS compliance baseline -- a minimum protocol version and a cipher
+// suite allowlist -- so the Elasticsearch and Kafka clients configure TLS
+// the same way instead of each picking its own defaults.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// Config names a minimum TLS version and an allowlist of cipher suites by
+// their standard Go names (e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"),
+// both driven by env vars so the compliance baseline can tighten without
+// a code change.
+type Config struct {
+	// MinVersion is "1.2" or "1.3". Empty defaults to "1.2", the
+	// compliance baseline's floor.
+	MinVersion string
+	// CipherSuites is an allowlist of cipher suite names. Empty means
+	// DefaultCipherSuites. Ignored once MinVersion negotiates TLS 1.3,
+	// which doesn't support configuring cipher suites -- see
+	// crypto/tls.Config.CipherSuites.
+	CipherSuites []string
+}
+
+var minVersionsByName = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// DefaultCipherSuites is the cipher suite allowlist applied when
+// Config.CipherSuites is empty: AEAD ciphers with forward secrecy only,
+// matching the compliance baseline's "modern ciphers" requirement.
+var DefaultCipherSuites = []string{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+}
+
+// Build returns a *tls.Config enforcing cfg's minimum version and cipher
+// suite allowlist, defaulting to TLS 1.2 and DefaultCipherSuites when
+// left unset. It fails on an unrecognized MinVersion or CipherSuites
+// entry rather than silently falling back to Go's permissive defaults --
+// a typo in either should fail startup, not quietly weaken the baseline.
+func Build(cfg Config) (*tls.Config, error) {
+	minVersion := cfg.MinVersion
+	if minVersion == "" {
+		minVersion = "1.2"
+	}
+	version, ok := minVersionsByName[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("tlsconfig: unsupported minimum TLS version %q (want \"1.2\" or \"1.3\")", minVersion)
+	}
+
+	names := cfg.CipherSuites
+	if len(names) == 0 {
+		names = DefaultCipherSuites
+	}
+	suites, err := cipherSuiteIDs(names)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:   version,
+		CipherSuites: suites,
+	}, nil
+}
+
+// cipherSuiteIDs resolves each name in names to its tls.CipherSuite ID via
+// tls.CipherSuites, the set Go itself is willing to negotiate (it
+// excludes suites Go only keeps around for parsing legacy certs, e.g.
+// ones using RC4 or 3DES).
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := cipherSuiteIDByName(name)
+		if !ok {
+			return nil, fmt.Errorf("tlsconfig: unrecognized cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func cipherSuiteIDByName(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,193 @@
+// Command gateway runs the API Gateway edge service: the Gin-based HTTP
+// front door that validates and forwards requests to ledger-core over
+// gRPC.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/handler"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/server"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/tlsconfig"
+)
+
+func main() {
+	cfg := config.Load()
+
+	client, err := newLedgerClient(cfg)
+	if err != nil {
+		log.Fatalf("gateway: failed to build ledger client: %v", err)
+	}
+	defer client.Close()
+
+	httpServer, health, err := server.New(cfg, client)
+	if err != nil {
+		log.Fatalf("gateway: failed to build HTTP server: %v", err)
+	}
+
+	go func() {
+		log.Printf("gateway: listening on %s", cfg.HTTPAddr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("gateway: server error: %v", err)
+		}
+	}()
+
+	waitForShutdown(httpServer, health, cfg.ShutdownDrainDelay)
+}
+
+// newLedgerClient builds the LedgerClient the gateway will use: the mock
+// when no core address is configured, a sharded client when
+// LedgerCoreShardAddrs is set, otherwise a single gRPC client (optionally
+// with a read replica) -- in every case wrapped with the standard
+// decorator stack.
+func newLedgerClient(cfg *config.Config) (ledgerclient.LedgerClient, error) {
+	if cfg.LedgerCoreAddr == "" && len(cfg.LedgerCoreShardAddrs) == 0 {
+		log.Printf("gateway: LEDGER_CORE_ADDR not set, using in-memory mock ledger client")
+		return ledgerclient.NewMockLedgerClient(), nil
+	}
+
+	base, err := newBaseLedgerClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.WarmupEnabled {
+		if err := warmup(base, cfg.WarmupTimeout); err != nil {
+			if cfg.WarmupFailOnError {
+				return nil, err
+			}
+			log.Printf("gateway: warmup failed, starting in a degraded state: %v", err)
+		}
+	}
+
+	client := ledgerclient.WithRetry(base, ledgerclient.DefaultRetryConfig())
+	client = ledgerclient.WithCircuitBreaker(client, ledgerclient.DefaultCircuitBreakerConfig())
+	client = ledgerclient.WithBulkhead(client, ledgerclient.BulkheadConfig{
+		MaxConcurrent: cfg.BulkheadMaxConcurrent,
+		QueueTimeout:  cfg.BulkheadQueueTimeout,
+	})
+	return client, nil
+}
+
+// newBaseLedgerClient dials ledger-core and returns the undecorated client:
+// a ShardedLedgerClient over LedgerCoreShardAddrs when set, otherwise a
+// single gRPC client against LedgerCoreAddr (with LedgerCoreReplicaAddr as
+// its optional read replica). The two aren't composed -- sharding takes
+// precedence, per LedgerCoreShardAddrs's doc comment.
+func newBaseLedgerClient(cfg *config.Config) (ledgerclient.LedgerClient, error) {
+	tlsCfg, err := grpcTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.LedgerCoreShardAddrs) == 0 {
+		return ledgerclient.NewGRPCLedgerClient(cfg.LedgerCoreAddr, cfg.LedgerCoreReplicaAddr, ledgerclient.TimeoutConfig{
+			Read:  cfg.GRPCReadTimeout,
+			Write: cfg.GRPCWriteTimeout,
+		}, tlsCfg)
+	}
+
+	shards := make(map[string]ledgerclient.LedgerClient, len(cfg.LedgerCoreShardAddrs))
+	for _, addr := range cfg.LedgerCoreShardAddrs {
+		shard, err := ledgerclient.NewGRPCLedgerClient(addr, "", ledgerclient.TimeoutConfig{
+			Read:  cfg.GRPCReadTimeout,
+			Write: cfg.GRPCWriteTimeout,
+		}, tlsCfg)
+		if err != nil {
+			return nil, fmt.Errorf("dialing shard %q: %w", addr, err)
+		}
+		shards[addr] = shard
+	}
+	return ledgerclient.NewShardedLedgerClient(shards), nil
+}
+
+// grpcTLSConfig builds the *tls.Config passed to NewGRPCLedgerClient, or
+// nil if cfg.GRPCTLSEnabled is false, preserving the gateway's original
+// insecure-by-default connection to ledger-core. The compliance baseline
+// (minimum version, cipher suite allowlist) comes from tlsconfig.Build;
+// GRPCTLSCACertPath is layered on top since it's specific to this one
+// connection, not part of the shared baseline.
+func grpcTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if !cfg.GRPCTLSEnabled {
+		return nil, nil
+	}
+
+	tlsCfg, err := tlsconfig.Build(tlsconfig.Config{
+		MinVersion:   cfg.GRPCTLSMinVersion,
+		CipherSuites: cfg.GRPCTLSCipherSuites,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gateway: building gRPC TLS config: %w", err)
+	}
+
+	if cfg.GRPCTLSCACertPath != "" {
+		pem, err := os.ReadFile(cfg.GRPCTLSCACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("gateway: reading gRPC TLS CA cert %s: %w", cfg.GRPCTLSCACertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("gateway: no certificates found in gRPC TLS CA cert %s", cfg.GRPCTLSCACertPath)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	return tlsCfg, nil
+}
+
+// warmup blocks until client's underlying connection is ready, bounded by
+// timeout, and logs how long that took. Clients that don't implement
+// ledgerclient.Warmer (e.g. the mock) are a no-op.
+func warmup(client ledgerclient.LedgerClient, timeout time.Duration) error {
+	warmer, ok := client.(ledgerclient.Warmer)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := warmer.Warmup(ctx)
+	elapsed := time.Since(start)
+	if err != nil {
+		return fmt.Errorf("warmup did not become ready within %s: %w", elapsed, err)
+	}
+	log.Printf("gateway: ledger-core connection ready after %s", elapsed)
+	return nil
+}
+
+// waitForShutdown blocks until SIGINT/SIGTERM, then drains before shutting
+// httpServer down. health.SetShuttingDown is called first, so /readyz
+// starts failing immediately; the process then waits drainDelay before
+// calling Shutdown, giving a load balancer time to act on that failed
+// probe and stop routing new connections here. drainDelay <= 0 skips the
+// wait, matching the gateway's behavior before this delay existed.
+func waitForShutdown(httpServer *http.Server, health *handler.HealthHandler, drainDelay time.Duration) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+
+	log.Printf("gateway: draining, marking not ready")
+	health.SetShuttingDown()
+	if drainDelay > 0 {
+		time.Sleep(drainDelay)
+	}
+
+	log.Printf("gateway: shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("gateway: graceful shutdown failed: %v", err)
+	}
+}
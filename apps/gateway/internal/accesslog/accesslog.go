@@ -0,0 +1,104 @@
+// Package accesslog provides a small rotating file writer for the
+// gateway's HTTP access log, as an alternative to stdout for deployments
+// that run the binary directly instead of under a platform (Kubernetes)
+// that already handles log rotation.
+package accesslog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Config controls where access log lines go and, for a file destination,
+// how rotation behaves.
+type Config struct {
+	// Path is the access log file. Empty means stdout (the default), and
+	// disables rotation entirely.
+	Path string
+	// MaxSizeBytes rotates the file once appending to it would exceed
+	// this size.
+	MaxSizeBytes int64
+	// MaxBackups is how many rotated files are kept; older ones are
+	// removed on rotation. Zero keeps every rotated file forever.
+	MaxBackups int
+}
+
+// DefaultConfig logs to stdout.
+func DefaultConfig() Config {
+	return Config{MaxSizeBytes: 100 * 1024 * 1024, MaxBackups: 5}
+}
+
+// NewWriter returns an io.Writer for cfg: os.Stdout if cfg.Path is empty,
+// or a size-rotating file writer otherwise. A file that can't be opened is
+// returned as an error so startup fails clearly instead of silently
+// falling back to stdout.
+func NewWriter(cfg Config) (io.Writer, error) {
+	if cfg.Path == "" {
+		return os.Stdout, nil
+	}
+
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("accesslog: open %s: %w", cfg.Path, err)
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("accesslog: seek %s: %w", cfg.Path, err)
+	}
+
+	return &rotatingWriter{cfg: cfg, file: f, size: size}, nil
+}
+
+// rotatingWriter appends to a single file, rotating it to a numbered
+// backup once it would exceed cfg.MaxSizeBytes and trimming backups beyond
+// cfg.MaxBackups. It is safe for concurrent use, since multiple request
+// goroutines log through the same Logging middleware.
+type rotatingWriter struct {
+	mu   sync.Mutex
+	cfg  Config
+	file *os.File
+	size int64
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cfg.MaxSizeBytes > 0 && w.size+int64(len(p)) > w.cfg.MaxSizeBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate closes the current file, shifts existing numbered backups up by
+// one (dropping anything beyond cfg.MaxBackups), and reopens a fresh file
+// at the original path.
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("accesslog: close %s: %w", w.cfg.Path, err)
+	}
+
+	if w.cfg.MaxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", w.cfg.Path, w.cfg.MaxBackups))
+		for i := w.cfg.MaxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", w.cfg.Path, i), fmt.Sprintf("%s.%d", w.cfg.Path, i+1))
+		}
+		os.Rename(w.cfg.Path, fmt.Sprintf("%s.1", w.cfg.Path))
+	}
+
+	f, err := os.OpenFile(w.cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("accesslog: reopen %s: %w", w.cfg.Path, err)
+	}
+	w.file = f
+	w.size = 0
+	return nil
+}
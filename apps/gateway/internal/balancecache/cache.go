@@ -0,0 +1,55 @@
+// Package balancecache holds the last known balance for an account so
+// BalanceHandler can serve a stale read during a ledger-core outage
+// instead of failing closed. The interface is intentionally storage-
+// agnostic: the in-memory implementation here is a single-instance
+// stand-in for a shared Redis-backed cache.
+package balancecache
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is a cached balance snapshot.
+type Entry struct {
+	Currency string
+	Balance  string
+	// CachedAt is when this snapshot was stored, used to compute the
+	// staleness reported alongside a fallback read.
+	CachedAt time.Time
+}
+
+// Cache stores the most recent balance seen for each account.
+type Cache interface {
+	// Get returns the cached entry for accountID, if any.
+	Get(accountID string) (Entry, bool)
+	// Set records entry as the latest known balance for accountID.
+	Set(accountID string, entry Entry)
+}
+
+// memoryCache is a process-local Cache. It's unbounded, which is fine for
+// the gateway's working set (one entry per account ever read), matching
+// the scale of the in-memory mock ledger client it's typically paired
+// with.
+type memoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewMemoryCache returns an empty in-memory Cache.
+func NewMemoryCache() Cache {
+	return &memoryCache{entries: make(map[string]Entry)}
+}
+
+func (c *memoryCache) Get(accountID string) (Entry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[accountID]
+	return entry, ok
+}
+
+func (c *memoryCache) Set(accountID string, entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[accountID] = entry
+}
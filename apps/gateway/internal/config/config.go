@@ -0,0 +1,585 @@
+// Package config loads the gateway's runtime configuration from the
+// environment, applying sane defaults so the service is runnable with no
+// env vars set (against the mock ledger client).
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds every environment-tunable setting for the gateway. Fields
+// are added here as features need them; keep defaults backward compatible.
+type Config struct {
+	HTTPAddr string
+	// HTTPWriteTimeout bounds how long a normal request has to write its
+	// response before net/http aborts the connection. It does not apply
+	// to streaming routes (SSE, WebSocket), which disable it per request
+	// via middleware.DisableWriteTimeout -- see server.SetupRouter.
+	HTTPWriteTimeout time.Duration
+
+	// LedgerCoreAddr is the gRPC address of ledger-core. Empty means "use
+	// the in-memory mock", which is the default for local development.
+	LedgerCoreAddr string
+	// LedgerCoreReplicaAddr, if set, routes GetBalance/ListAccounts/
+	// ListTransactions to a read replica instead of the primary at
+	// LedgerCoreAddr. Empty means everything goes to the primary. See
+	// ledgerclient.WithReadReplica.
+	LedgerCoreReplicaAddr string
+	// LedgerCoreShardAddrs, if non-empty, splits accounts across several
+	// ledger-core backends instead of one (see ledgerclient.
+	// ShardedLedgerClient), and takes precedence over LedgerCoreAddr /
+	// LedgerCoreReplicaAddr -- sharding and the read-replica path aren't
+	// composed together yet.
+	LedgerCoreShardAddrs []string
+	// GRPCTimeout is the fallback deadline for any RPC whose category
+	// (read/write) doesn't have its own override below.
+	GRPCTimeout time.Duration
+	// GRPCReadTimeout and GRPCWriteTimeout let read RPCs (e.g.
+	// ListTransactions over a long history) have a longer deadline than
+	// writes, which should stay snappy. Each falls back to GRPCTimeout
+	// when its env var isn't set.
+	GRPCReadTimeout  time.Duration
+	GRPCWriteTimeout time.Duration
+
+	// GRPCTLSEnabled enables TLS on the connection to ledger-core, built
+	// via tlsconfig.Build from GRPCTLSMinVersion/GRPCTLSCipherSuites. Off
+	// by default: a deployment that reaches ledger-core over a trusted
+	// network (same pod, a service mesh that already terminates TLS) has
+	// nothing to gain from it.
+	GRPCTLSEnabled bool
+	// GRPCTLSCACertPath, if set, is a PEM file trusted when verifying
+	// ledger-core's certificate, in addition to the system pool -- for a
+	// self-signed or internal CA.
+	GRPCTLSCACertPath string
+	// GRPCTLSMinVersion and GRPCTLSCipherSuites are passed straight
+	// through to tlsconfig.Config; see its doc comment for the accepted
+	// values and defaults.
+	GRPCTLSMinVersion   string
+	GRPCTLSCipherSuites []string
+
+	BulkheadMaxConcurrent int
+	BulkheadQueueTimeout  time.Duration
+
+	// MaxPageSize caps the page_size a client can request from
+	// AccountHandler.List and TransactionHandler.List. A request over the
+	// cap is clamped rather than rejected, with the X-Page-Size-Clamped
+	// response header set; this keeps one client's oversized page_size
+	// from being forwarded straight through to ledger-core.
+	MaxPageSize int
+
+	// WarmupEnabled blocks startup on establishing the gRPC connection to
+	// ledger-core before the HTTP server accepts traffic, so the first
+	// real request doesn't pay connection-establishment cost.
+	WarmupEnabled bool
+	WarmupTimeout time.Duration
+	// WarmupFailOnError fails gateway startup entirely if warmup doesn't
+	// succeed within WarmupTimeout. When false, the gateway logs a
+	// warning and starts anyway (a degraded start), relying on the
+	// existing retry/circuit-breaker decorators for the first requests.
+	WarmupFailOnError bool
+
+	// StaleBalanceFallbackEnabled lets BalanceHandler serve the last known
+	// balance from balancecache when ledger-core is unavailable, rather
+	// than failing the read. Opt-in since it trades consistency for
+	// availability.
+	StaleBalanceFallbackEnabled bool
+
+	// SlowRequestThreshold is how long a request may take before Logging
+	// emits an extra WARN-level line for it, as an early signal on latency
+	// regressions. Non-positive disables the warning.
+	SlowRequestThreshold time.Duration
+
+	// TransactionEventsPollInterval is how often TransactionHandler.Events
+	// polls GetTransaction while streaming a transaction's status over
+	// SSE, absent a core streaming RPC to push changes instead.
+	TransactionEventsPollInterval time.Duration
+
+	// WSBalancePollInterval is how often WSHandler polls GetBalance for
+	// each subscribed account while pushing updates over a WebSocket
+	// connection, absent a core streaming RPC to push changes instead.
+	WSBalancePollInterval time.Duration
+
+	// AccessLogPath is the file Logging writes to. Empty (the default)
+	// means stdout, leaving current behavior unchanged; this only matters
+	// when running the binary directly instead of under a platform that
+	// already handles log rotation.
+	AccessLogPath string
+	// AccessLogMaxSizeBytes and AccessLogMaxBackups control AccessLogPath's
+	// rotation. See accesslog.Config.
+	AccessLogMaxSizeBytes int64
+	AccessLogMaxBackups   int
+
+	// LogRequestHeaders enables a DEBUG-level line per request with its
+	// (redacted) headers, as a stepping stone toward full request-body
+	// debug logging. Off by default.
+	LogRequestHeaders bool
+	// LogRedactHeaders and LogRedactJSONFields are the header names and
+	// JSON field names Logging and Recovery treat as sensitive. See
+	// redact.Config.
+	LogRedactHeaders    []string
+	LogRedactJSONFields []string
+
+	// CurrencyAllowlist restricts which ISO currency codes TransactionHandler
+	// and AccountHandler will accept, on top of currency.Lookup's own
+	// validity check. An empty allowlist means any valid ISO code is
+	// accepted.
+	CurrencyAllowlist []string
+
+	// MaxTransactionAmount caps, per currency code, the amount a single
+	// CreateTransaction call may move through the gateway -- a policy gate
+	// to limit blast radius from a bug or compromised token, independent
+	// of the account's actual balance. MaxTransactionAmountDefault applies
+	// to currencies with no entry here. Both are decimal strings, parsed
+	// the same way request amounts are; an absent cap means no limit.
+	MaxTransactionAmount        map[string]string
+	MaxTransactionAmountDefault string
+
+	// RateLimitRedisAddr is the Redis instance backing the rate limiter's
+	// counters.
+	RateLimitRedisAddr string
+	// RateLimitAllowlistUserIDs and RateLimitAllowlistCIDRs exempt trusted
+	// identities (internal service accounts, monitoring) from the rate
+	// limiter entirely. See ratelimit.NewAllowlist.
+	RateLimitAllowlistUserIDs []string
+	RateLimitAllowlistCIDRs   []string
+
+	// IdempotencyRedisAddr, if set, backs TransactionHandler's idempotency
+	// store with Redis instead of an in-process map, so duplicate-submit
+	// protection -- including the short-lived lock that keeps concurrent
+	// duplicates from a thundering herd into ledger-core -- works across
+	// every gateway instance rather than just within one process. Empty
+	// (the default) uses an in-memory store.
+	IdempotencyRedisAddr string
+	// IdempotencyLockTTL, IdempotencyLockWaitTimeout, and
+	// IdempotencyLockPollInterval tune the Redis store's lock; see
+	// idempotency.RedisStoreConfig. Unused when IdempotencyRedisAddr is
+	// empty.
+	IdempotencyLockTTL          time.Duration
+	IdempotencyLockWaitTimeout  time.Duration
+	IdempotencyLockPollInterval time.Duration
+	// IdempotencyRecordTTL bounds how long an idempotency key is honored
+	// after it's first used. A repeat request with the same key after its
+	// record expires is treated as a brand new request -- re-executed and
+	// re-booked -- rather than replaying a response that may no longer
+	// reflect current account state. Zero disables expiry, matching this
+	// store's original behavior of honoring a key forever.
+	IdempotencyRecordTTL time.Duration
+	// IdempotencyMaxRecords caps how many records the in-memory idempotency
+	// store holds at once, evicting the least recently used once exceeded.
+	// Unused when IdempotencyRedisAddr is set -- Redis has its own memory
+	// limits and eviction policy. Zero disables the cap, leaving the store
+	// unbounded.
+	IdempotencyMaxRecords int
+
+	// Middleware is the ordered list of named middleware SetupRouter
+	// applies after Recovery, which is always installed first and isn't
+	// itself a name in this list. Recognized names are "request_id",
+	// "logging", "auth", "rate_limit", "rate_limit_ip", and
+	// "concurrency_limit"; an unrecognized name fails startup rather than
+	// silently skipping it. Defaults to "request_id, logging", matching
+	// the gateway's behavior before this list existed.
+	Middleware []string
+
+	// ConcurrencyLimitMax is the maximum number of requests
+	// middleware.ConcurrencyLimit admits at once, rejecting the rest with
+	// 503, once "concurrency_limit" is in Middleware. It's a hard ceiling
+	// on the whole process independent of per-user/IP rate limiting --
+	// sized to protect against memory exhaustion during a flood, not to
+	// shape traffic. A non-positive value disables the limit even if
+	// "concurrency_limit" is listed, so a deployment can keep the name in
+	// Middleware and flip this on/off without editing the list.
+	ConcurrencyLimitMax int
+
+	// AdminRateLimitEnabled gates the admin endpoints for inspecting and
+	// clearing a user's rate-limit window. Off by default since it's an
+	// operational escape hatch, not something every deployment needs
+	// exposed.
+	AdminRateLimitEnabled bool
+	// AdminToken is the shared secret middleware.RequireAdmin checks
+	// against. An empty token fails every admin request closed.
+	AdminToken string
+
+	// ReplayProtectionEnabled gates jti-based replay protection (see
+	// middleware.ReplayProtection) on transaction creation -- the
+	// gateway's highest-value write. Off by default: it requires every
+	// caller's token to carry a jti and exp claim, which not every
+	// deployment's auth setup provides yet.
+	ReplayProtectionEnabled bool
+	// ReplayProtectionRedisAddr is the Redis instance ReplayProtection
+	// records used jtis in. Unused when ReplayProtectionEnabled is false.
+	ReplayProtectionRedisAddr string
+
+	// AuthAcceptedAudiences, if non-empty, restricts middleware.Auth to
+	// tokens whose X-Token-Aud header contains at least one of these
+	// values. Empty (the default) accepts any audience.
+	AuthAcceptedAudiences []string
+	// AuthClockSkewLeeway widens middleware.Auth's exp/nbf validation by
+	// this much, absorbing clock skew between the IdP and the gateway.
+	// Zero (the default) preserves strict validation.
+	AuthClockSkewLeeway time.Duration
+	// AuthRequiredClaims names additional claims middleware.Auth requires
+	// beyond sub, keyed by claim name with the expected value type
+	// ("string", "int", or "bool") as the value, e.g.
+	// {"tenant_id": "string"}. Empty (the default) requires nothing
+	// beyond sub.
+	AuthRequiredClaims map[string]string
+	// AuthTenantClaim names the claim in AuthRequiredClaims that carries
+	// the caller's tenant ID, if any. When set, handlers that list
+	// accounts/transactions read it via middleware.ClaimsFromContext and
+	// attach it to the request context so ledgerclient forwards it to
+	// ledger-core as gRPC metadata (see ledgerclient.ContextWithTenantID).
+	// Empty (the default) disables tenant scoping entirely, preserving
+	// today's single-tenant behavior.
+	AuthTenantClaim string
+
+	// AuthMode selects middleware.Auth's validation mode: "jwt" (the
+	// default) trusts the X-User-ID/X-Token-* headers as before; "opaque"
+	// validates the Authorization bearer token via an OAuth2 introspection
+	// endpoint instead, using the AuthIntrospection* settings below.
+	AuthMode string
+	// AuthIntrospectionURL is the RFC 7662 introspection endpoint Auth
+	// posts opaque tokens to in AuthMode "opaque". Required for that mode.
+	AuthIntrospectionURL string
+	// AuthIntrospectionClientID and AuthIntrospectionClientSecret
+	// authenticate the gateway itself to AuthIntrospectionURL via HTTP
+	// Basic auth.
+	AuthIntrospectionClientID     string
+	AuthIntrospectionClientSecret string
+	// AuthIntrospectionTimeout bounds each introspection call. Zero falls
+	// back to introspection.DefaultTimeout.
+	AuthIntrospectionTimeout time.Duration
+	// AuthIntrospectionCacheTTL bounds how long a token's introspection
+	// result is reused before introspecting it again. Zero disables
+	// caching, so every request with Mode "opaque" pays the round trip.
+	AuthIntrospectionCacheTTL time.Duration
+	// AuthIntrospectionCircuitBreakerFailureThreshold and
+	// AuthIntrospectionCircuitBreakerOpenDuration protect the gateway from
+	// a slow or unreachable introspection endpoint; see
+	// introspection.Config's same-named fields. Zero FailureThreshold (the
+	// default) disables the breaker.
+	AuthIntrospectionCircuitBreakerFailureThreshold int
+	AuthIntrospectionCircuitBreakerOpenDuration     time.Duration
+
+	// HealthLedgerCoreTimeout and HealthLedgerCoreRequired configure
+	// Readiness's ledger-core connectivity check (via ledgerclient.
+	// Warmer). Required defaults to false: a bad ledger-core connection
+	// already degrades gracefully to per-request errors via the retry/
+	// circuit breaker decorators, so Readiness staying "ok" regardless is
+	// today's behavior, preserved as the default.
+	HealthLedgerCoreTimeout  time.Duration
+	HealthLedgerCoreRequired bool
+	// HealthRedisTimeout and HealthRedisRequired configure Readiness's
+	// checks of the Redis instances backing rate limiting and
+	// idempotency (only registered when their respective address is
+	// configured). Required also defaults to false: Redis being down
+	// degrades those features rather than the gateway as a whole.
+	HealthRedisTimeout  time.Duration
+	HealthRedisRequired bool
+
+	// ShutdownDrainDelay is how long the gateway waits, after SIGINT/
+	// SIGTERM marks Readiness not-ready, before it calls Shutdown on the
+	// HTTP server. It exists to close a Kubernetes race: the pod is
+	// removed from Service endpoints only after its next failed readiness
+	// probe, which can land after the process has already stopped
+	// accepting connections, producing a handful of connection-refused
+	// errors on deploy. Zero (the default) skips the delay, preserving
+	// today's shutdown-immediately behavior.
+	ShutdownDrainDelay time.Duration
+
+	// TrustedProxies lists the CIDRs of proxies allowed to set
+	// X-Forwarded-For/X-Real-IP, passed to gin.Engine.SetTrustedProxies.
+	// Empty (the default) trusts none, so gin.Context.ClientIP -- and
+	// anything keying off it, like ratelimit.IPIdentity -- falls back to
+	// the TCP remote address instead of a header any caller can set
+	// themselves to evade IP-based rate limiting.
+	TrustedProxies []string
+
+	// PprofEnabled registers net/http/pprof's handlers under /debug/pprof,
+	// gated by PprofAllowedCIDRs. Default off: a profiling endpoint left
+	// reachable by accident in production leaks goroutine stacks, heap
+	// contents, and request timing to anyone who can reach it.
+	PprofEnabled bool
+	// PprofAllowedCIDRs further restricts /debug/pprof to callers whose IP
+	// matches one of these CIDRs once PprofEnabled is true. Empty closes
+	// the endpoint to everyone even when enabled, so turning the flag on
+	// without also configuring an allowlist fails closed rather than open.
+	PprofAllowedCIDRs []string
+}
+
+func Load() *Config {
+	grpcTimeout := getEnvDuration("GRPC_TIMEOUT_MS", 5*time.Second)
+
+	return &Config{
+		HTTPAddr:              getEnv("HTTP_ADDR", ":8080"),
+		HTTPWriteTimeout:      getEnvDuration("HTTP_WRITE_TIMEOUT_MS", 15*time.Second),
+		LedgerCoreAddr:        getEnv("LEDGER_CORE_ADDR", ""),
+		LedgerCoreReplicaAddr: getEnv("LEDGER_CORE_REPLICA_ADDR", ""),
+		LedgerCoreShardAddrs:  getEnvListRaw("LEDGER_CORE_SHARD_ADDRS"),
+		GRPCTimeout:           grpcTimeout,
+		GRPCReadTimeout:       getEnvDuration("GRPC_TIMEOUT_READ_MS", grpcTimeout),
+		GRPCWriteTimeout:      getEnvDuration("GRPC_TIMEOUT_WRITE_MS", grpcTimeout),
+
+		GRPCTLSEnabled:      getEnvBool("GRPC_TLS_ENABLED", false),
+		GRPCTLSCACertPath:   getEnv("GRPC_TLS_CA_CERT_PATH", ""),
+		GRPCTLSMinVersion:   getEnv("GRPC_TLS_MIN_VERSION", ""),
+		GRPCTLSCipherSuites: getEnvList("GRPC_TLS_CIPHER_SUITES"),
+
+		BulkheadMaxConcurrent: getEnvInt("BULKHEAD_MAX_CONCURRENT", 64),
+		BulkheadQueueTimeout:  getEnvDuration("BULKHEAD_QUEUE_TIMEOUT_MS", 100*time.Millisecond),
+		MaxPageSize:           getEnvInt("MAX_PAGE_SIZE", 100),
+		CurrencyAllowlist:     getEnvList("CURRENCY_ALLOWLIST"),
+		SlowRequestThreshold:  getEnvDuration("SLOW_REQUEST_THRESHOLD_MS", 1*time.Second),
+
+		MaxTransactionAmount:        getEnvMap("MAX_TRANSACTION_AMOUNT"),
+		MaxTransactionAmountDefault: getEnv("MAX_TRANSACTION_AMOUNT_DEFAULT", ""),
+
+		TransactionEventsPollInterval: getEnvDuration("TRANSACTION_EVENTS_POLL_INTERVAL_MS", 2*time.Second),
+		WSBalancePollInterval:         getEnvDuration("WS_BALANCE_POLL_INTERVAL_MS", 5*time.Second),
+
+		AccessLogPath:         getEnv("ACCESS_LOG_PATH", ""),
+		AccessLogMaxSizeBytes: getEnvInt64("ACCESS_LOG_MAX_SIZE_BYTES", 100*1024*1024),
+		AccessLogMaxBackups:   getEnvInt("ACCESS_LOG_MAX_BACKUPS", 5),
+
+		LogRequestHeaders:   getEnvBool("LOG_REQUEST_HEADERS", false),
+		LogRedactHeaders:    getEnvListRawDefault("LOG_REDACT_HEADERS", []string{"Authorization"}),
+		LogRedactJSONFields: getEnvListRawDefault("LOG_REDACT_JSON_FIELDS", []string{"account_id", "from_account_id", "to_account_id", "counterparty_id"}),
+
+		Middleware:          getEnvListRawDefault("MIDDLEWARE", []string{"request_id", "logging"}),
+		ConcurrencyLimitMax: getEnvInt("CONCURRENCY_LIMIT_MAX", 0),
+
+		RateLimitRedisAddr:        getEnv("RATE_LIMIT_REDIS_ADDR", "localhost:6379"),
+		RateLimitAllowlistUserIDs: getEnvListRaw("RATE_LIMIT_ALLOWLIST_USER_IDS"),
+		RateLimitAllowlistCIDRs:   getEnvListRaw("RATE_LIMIT_ALLOWLIST_CIDRS"),
+
+		IdempotencyRedisAddr:        getEnv("IDEMPOTENCY_REDIS_ADDR", ""),
+		IdempotencyLockTTL:          getEnvDuration("IDEMPOTENCY_LOCK_TTL_MS", 10*time.Second),
+		IdempotencyLockWaitTimeout:  getEnvDuration("IDEMPOTENCY_LOCK_WAIT_TIMEOUT_MS", 3*time.Second),
+		IdempotencyLockPollInterval: getEnvDuration("IDEMPOTENCY_LOCK_POLL_INTERVAL_MS", 50*time.Millisecond),
+		IdempotencyRecordTTL:        getEnvDuration("IDEMPOTENCY_RECORD_TTL_MS", 24*time.Hour),
+		IdempotencyMaxRecords:       getEnvInt("IDEMPOTENCY_MAX_RECORDS", 100000),
+
+		AdminRateLimitEnabled: getEnvBool("ADMIN_RATE_LIMIT_ENABLED", false),
+		AdminToken:            getEnv("ADMIN_TOKEN", ""),
+
+		ReplayProtectionEnabled:   getEnvBool("REPLAY_PROTECTION_ENABLED", false),
+		ReplayProtectionRedisAddr: getEnv("REPLAY_PROTECTION_REDIS_ADDR", "localhost:6379"),
+
+		AuthAcceptedAudiences: getEnvListRaw("AUTH_ACCEPTED_AUDIENCES"),
+		AuthClockSkewLeeway:   getEnvDuration("AUTH_CLOCK_SKEW_LEEWAY_MS", 0),
+		AuthRequiredClaims:    getEnvCaseSensitiveMap("AUTH_REQUIRED_CLAIMS"),
+		AuthTenantClaim:       getEnv("AUTH_TENANT_CLAIM", ""),
+
+		AuthMode:                      getEnv("AUTH_MODE", "jwt"),
+		AuthIntrospectionURL:          getEnv("AUTH_INTROSPECTION_URL", ""),
+		AuthIntrospectionClientID:     getEnv("AUTH_INTROSPECTION_CLIENT_ID", ""),
+		AuthIntrospectionClientSecret: getEnv("AUTH_INTROSPECTION_CLIENT_SECRET", ""),
+		AuthIntrospectionTimeout:      getEnvDuration("AUTH_INTROSPECTION_TIMEOUT_MS", 0),
+		AuthIntrospectionCacheTTL:     getEnvDuration("AUTH_INTROSPECTION_CACHE_TTL_MS", 30*time.Second),
+		AuthIntrospectionCircuitBreakerFailureThreshold: getEnvInt("AUTH_INTROSPECTION_CIRCUIT_BREAKER_FAILURE_THRESHOLD", 0),
+		AuthIntrospectionCircuitBreakerOpenDuration:     getEnvDuration("AUTH_INTROSPECTION_CIRCUIT_BREAKER_OPEN_DURATION_MS", 10*time.Second),
+
+		HealthLedgerCoreTimeout:  getEnvDuration("HEALTH_LEDGER_CORE_TIMEOUT_MS", 2*time.Second),
+		HealthLedgerCoreRequired: getEnvBool("HEALTH_LEDGER_CORE_REQUIRED", false),
+		HealthRedisTimeout:       getEnvDuration("HEALTH_REDIS_TIMEOUT_MS", 1*time.Second),
+		HealthRedisRequired:      getEnvBool("HEALTH_REDIS_REQUIRED", false),
+
+		ShutdownDrainDelay: getEnvDuration("SHUTDOWN_DRAIN_DELAY_MS", 0),
+
+		TrustedProxies: getEnvListRaw("TRUSTED_PROXIES"),
+
+		PprofEnabled:      getEnvBool("PPROF_ENABLED", false),
+		PprofAllowedCIDRs: getEnvListRaw("PPROF_ALLOWED_CIDRS"),
+
+		WarmupEnabled:     getEnvBool("WARMUP_ENABLED", true),
+		WarmupTimeout:     getEnvDuration("WARMUP_TIMEOUT_MS", 5*time.Second),
+		WarmupFailOnError: getEnvBool("WARMUP_FAIL_ON_ERROR", false),
+
+		StaleBalanceFallbackEnabled: getEnvBool("STALE_BALANCE_FALLBACK_ENABLED", false),
+	}
+}
+
+// IsCurrencyAllowed reports whether code is permitted by CurrencyAllowlist.
+// An empty allowlist permits any code (the allowlist check is a policy
+// layer on top of, not a replacement for, currency.Lookup's validity
+// check).
+func (c *Config) IsCurrencyAllowed(code string) bool {
+	if len(c.CurrencyAllowlist) == 0 {
+		return true
+	}
+	code = strings.ToUpper(code)
+	for _, allowed := range c.CurrencyAllowlist {
+		if allowed == code {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxAmountFor returns the configured maximum transaction amount for code,
+// falling back to MaxTransactionAmountDefault. ok is false when neither is
+// set, meaning no limit applies.
+func (c *Config) MaxAmountFor(code string) (string, bool) {
+	if v, ok := c.MaxTransactionAmount[strings.ToUpper(code)]; ok {
+		return v, true
+	}
+	if c.MaxTransactionAmountDefault != "" {
+		return c.MaxTransactionAmountDefault, true
+	}
+	return "", false
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getEnvInt64(key string, fallback int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// getEnvList reads a comma-separated list of uppercased, trimmed values.
+// An unset or empty env var yields a nil (empty) list.
+func getEnvList(key string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// getEnvListRaw reads a comma-separated list of trimmed values, preserving
+// case. Unlike getEnvList, it isn't specific to currency codes, so it
+// doesn't uppercase (a user ID or CIDR's case can be meaningful). An unset
+// or empty env var yields a nil (empty) list.
+func getEnvListRaw(key string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// getEnvListRawDefault is getEnvListRaw with a non-nil fallback for when
+// the env var is unset.
+func getEnvListRawDefault(key string, fallback []string) []string {
+	if v := getEnvListRaw(key); v != nil {
+		return v
+	}
+	return fallback
+}
+
+// getEnvMap reads a comma-separated list of key:value pairs (e.g.
+// "USD:10000,EUR:9000") into a map with uppercased keys. An unset, empty,
+// or entirely malformed env var yields a nil (empty) map.
+func getEnvMap(key string) map[string]string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	out := map[string]string{}
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, val, found := strings.Cut(part, ":")
+		if !found {
+			continue
+		}
+		out[strings.ToUpper(strings.TrimSpace(k))] = strings.TrimSpace(val)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// getEnvCaseSensitiveMap is getEnvMap without uppercasing the key, for
+// values (like AuthRequiredClaims' claim names) where key case is
+// meaningful rather than an identifier like a currency code.
+func getEnvCaseSensitiveMap(key string) map[string]string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	out := map[string]string{}
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, val, found := strings.Cut(part, ":")
+		if !found {
+			continue
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(val)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// getEnvDuration reads an integer number of milliseconds from key.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	ms, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,66 @@
+// Package currency centralizes ISO 4217 currency metadata — minor-unit
+// decimal places and display names — so amount validation and formatting
+// rules live in one place instead of being implicit in handler code.
+package currency
+
+import "strings"
+
+// Metadata describes a single ISO 4217 currency.
+type Metadata struct {
+	Code       string `json:"code"`
+	MinorUnits int    `json:"minor_units"`
+	Name       string `json:"name"`
+}
+
+// defaultMinorUnits is used for any valid-looking code not present in the
+// table below, matching the common case of two decimal places.
+const defaultMinorUnits = 2
+
+// table is embedded in the binary (no runtime file dependency) and covers
+// the currencies we actually operate in plus common exceptions to the
+// two-decimal default. Extend as new currencies are supported.
+var table = map[string]Metadata{
+	"USD": {Code: "USD", MinorUnits: 2, Name: "US Dollar"},
+	"EUR": {Code: "EUR", MinorUnits: 2, Name: "Euro"},
+	"GBP": {Code: "GBP", MinorUnits: 2, Name: "Pound Sterling"},
+	"JPY": {Code: "JPY", MinorUnits: 0, Name: "Japanese Yen"},
+	"KRW": {Code: "KRW", MinorUnits: 0, Name: "South Korean Won"},
+	"BHD": {Code: "BHD", MinorUnits: 3, Name: "Bahraini Dinar"},
+	"KWD": {Code: "KWD", MinorUnits: 3, Name: "Kuwaiti Dinar"},
+	"OMR": {Code: "OMR", MinorUnits: 3, Name: "Omani Rial"},
+}
+
+// Lookup returns the metadata for code (case-insensitive). ok is false for
+// codes we don't recognize; callers should treat unknown codes as invalid
+// rather than falling back to a default.
+func Lookup(code string) (Metadata, bool) {
+	m, ok := table[strings.ToUpper(code)]
+	return m, ok
+}
+
+// MinorUnits returns the number of minor-unit decimal places for code, or
+// defaultMinorUnits if code isn't in the table.
+func MinorUnits(code string) int {
+	if m, ok := Lookup(code); ok {
+		return m.MinorUnits
+	}
+	return defaultMinorUnits
+}
+
+// All returns every supported currency's metadata, sorted by code.
+func All() []Metadata {
+	out := make([]Metadata, 0, len(table))
+	for _, m := range table {
+		out = append(out, m)
+	}
+	sortByCode(out)
+	return out
+}
+
+func sortByCode(m []Metadata) {
+	for i := 1; i < len(m); i++ {
+		for j := i; j > 0 && m[j-1].Code > m[j].Code; j-- {
+			m[j-1], m[j] = m[j], m[j-1]
+		}
+	}
+}
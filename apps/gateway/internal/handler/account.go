@@ -0,0 +1,565 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/currency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+const defaultPageSize = 20
+
+// accountSortFields are the values List's sort query param accepts,
+// besides the default of ordering by account ID.
+var accountSortFields = map[string]bool{
+	"created_at": true,
+	"currency":   true,
+	"balance":    true,
+}
+
+// accountStatusFilters are the values List's status query param accepts.
+var accountStatusFilters = map[string]bool{
+	"active": true,
+	"closed": true,
+}
+
+// listBalanceConcurrency bounds how many GetBalance calls List issues at
+// once when asked to include balances, mirroring
+// batchBalanceConcurrency's reasoning: a single request shouldn't be able
+// to eat the whole ledger client's bulkhead budget on its own.
+const listBalanceConcurrency = 8
+
+// maxBatchCreateAccounts caps how many account specs CreateBatch will
+// accept in one request, so a client can't force an unbounded fan-out.
+const maxBatchCreateAccounts = 50
+
+// batchCreateConcurrency bounds how many CreateAccount calls CreateBatch
+// issues at once, same reasoning as batchBalanceConcurrency.
+const batchCreateConcurrency = 8
+
+// maxAccountLabelLength bounds Update's label field; it's display-only,
+// so there's no ledger-derived reason it needs to be any longer.
+const maxAccountLabelLength = 128
+
+// CreateAccountRequest is the gateway's wire format for account creation.
+type CreateAccountRequest struct {
+	Currency       string `json:"currency" binding:"required,len=3"`
+	InitialBalance string `json:"initial_balance"`
+}
+
+// UpdateAccountRequest is the gateway's wire format for PATCH
+// /v1/accounts/:id. Label and Metadata are both optional and only the
+// ones present are changed; Currency and Balance are accepted only so
+// Update can reject attempts to set them, since ledger state isn't
+// editable through this endpoint.
+type UpdateAccountRequest struct {
+	Label    *string           `json:"label"`
+	Metadata map[string]string `json:"metadata"`
+	Currency string            `json:"currency"`
+	Balance  string            `json:"balance"`
+}
+
+// AccountResponse is the gateway's wire format for an account.
+type AccountResponse struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Currency  string            `json:"currency"`
+	Balance   string            `json:"balance"`
+	Version   int64             `json:"version"`
+	CreatedAt string            `json:"created_at"`
+	Status    string            `json:"status"`
+	Label     string            `json:"label,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	// BalanceError is set instead of a refreshed Balance when List was
+	// called with include=balance and this account's GetBalance call
+	// failed. Balance keeps the value ListAccounts itself returned.
+	BalanceError *errorBody `json:"balance_error,omitempty"`
+}
+
+// ListAccountsResponse is the gateway's wire format for a page of accounts.
+type ListAccountsResponse struct {
+	Accounts   []AccountResponse `json:"accounts"`
+	TotalCount int32             `json:"total_count"`
+	Page       int32             `json:"page"`
+	PageSize   int32             `json:"page_size"`
+	Pagination PageMeta          `json:"pagination"`
+}
+
+// AccountHandler implements the /v1/accounts endpoints.
+type AccountHandler struct {
+	Client ledgerclient.LedgerClient
+	Config *config.Config
+}
+
+func NewAccountHandler(client ledgerclient.LedgerClient, cfg *config.Config) *AccountHandler {
+	return &AccountHandler{Client: client, Config: cfg}
+}
+
+// Create handles POST /v1/accounts.
+func (h *AccountHandler) Create(c *gin.Context) {
+	var req CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	if !h.Config.IsCurrencyAllowed(req.Currency) {
+		writeError(c, http.StatusUnprocessableEntity, "UNSUPPORTED_CURRENCY", "currency "+req.Currency+" is not supported by this deployment")
+		return
+	}
+
+	canonicalBalance, err := parseInitialBalance(req.InitialBalance, req.Currency)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	userID := userIDFromContext(c)
+	ctx := ledgerclient.ContextWithTenantID(c.Request.Context(), tenantIDFromContext(c, h.Config.AuthTenantClaim))
+	resp, err := h.Client.CreateAccount(ctx, &ledgerpb.CreateAccountRequest{
+		UserId:         userID,
+		Currency:       req.Currency,
+		InitialBalance: canonicalBalance,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.Header("Location", "/v1/accounts/"+resp.Id)
+	c.JSON(http.StatusCreated, toAccountResponse(resp))
+}
+
+// BatchCreateAccountsRequest is the request body for POST
+// /v1/accounts/batch.
+type BatchCreateAccountsRequest struct {
+	Accounts []CreateAccountRequest `json:"accounts" binding:"required,min=1"`
+}
+
+// AccountCreateResult is one spec's outcome within a batch account
+// creation: either the created account on success, or Error on a
+// per-spec failure (e.g. an unsupported currency, or a ledger-core
+// error). Exactly one is populated.
+type AccountCreateResult struct {
+	Account *AccountResponse `json:"account,omitempty"`
+	Error   *errorBody       `json:"error,omitempty"`
+}
+
+// BatchCreateAccountsResponse is the response body for POST
+// /v1/accounts/batch.
+type BatchCreateAccountsResponse struct {
+	Accounts []AccountCreateResult `json:"accounts"`
+}
+
+// CreateBatch handles POST /v1/accounts/batch: creating several accounts
+// in one call, for onboarding a customer that needs one account per
+// currency or product at once. Each spec is validated on its own (currency
+// allowed, initial_balance well-formed) before it is sent to CreateAccount,
+// so a bad currency never reaches ledger-core; a spec that fails validation
+// or CreateAccount itself comes back as a per-item error rather than
+// failing the whole batch. The user ID for every created account comes
+// from the caller's JWT, same as Create.
+func (h *AccountHandler) CreateBatch(c *gin.Context) {
+	var req BatchCreateAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	if len(req.Accounts) > maxBatchCreateAccounts {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "at most "+strconv.Itoa(maxBatchCreateAccounts)+" accounts are allowed per request")
+		return
+	}
+
+	userID := userIDFromContext(c)
+	ctx := ledgerclient.ContextWithTenantID(c.Request.Context(), tenantIDFromContext(c, h.Config.AuthTenantClaim))
+	results := h.createAccounts(ctx, req.Accounts, userID)
+	c.JSON(http.StatusOK, BatchCreateAccountsResponse{Accounts: results})
+}
+
+// createAccounts validates and creates each spec, fanning out the
+// CreateAccount calls with bounded concurrency and aggregating per-item
+// successes and failures. Results preserve the order of specs.
+func (h *AccountHandler) createAccounts(ctx context.Context, specs []CreateAccountRequest, userID string) []AccountCreateResult {
+	results := make([]AccountCreateResult, len(specs))
+	sem := make(chan struct{}, batchCreateConcurrency)
+
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec CreateAccountRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = h.createOneAccount(ctx, spec, userID)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (h *AccountHandler) createOneAccount(ctx context.Context, spec CreateAccountRequest, userID string) AccountCreateResult {
+	if !h.Config.IsCurrencyAllowed(spec.Currency) {
+		return AccountCreateResult{Error: &errorBody{Code: "UNSUPPORTED_CURRENCY", Message: "currency " + spec.Currency + " is not supported by this deployment"}}
+	}
+
+	canonicalBalance, err := parseInitialBalance(spec.InitialBalance, spec.Currency)
+	if err != nil {
+		return AccountCreateResult{Error: &errorBody{Code: "INVALID_ARGUMENT", Message: err.Error()}}
+	}
+
+	resp, err := h.Client.CreateAccount(ctx, &ledgerpb.CreateAccountRequest{
+		UserId:         userID,
+		Currency:       spec.Currency,
+		InitialBalance: canonicalBalance,
+	})
+	if err != nil {
+		_, code, message := grpcToHTTPError(err)
+		return AccountCreateResult{Error: &errorBody{Code: code, Message: message}}
+	}
+
+	acct := toAccountResponse(resp)
+	return AccountCreateResult{Account: &acct}
+}
+
+// Get handles GET /v1/accounts/:id. The response carries the account's
+// version as an ETag header, so a client can round-trip it back as
+// If-Match on a later Update or Close for optimistic concurrency.
+func (h *AccountHandler) Get(c *gin.Context) {
+	resp, err := h.Client.GetAccount(c.Request.Context(), &ledgerpb.GetAccountRequest{
+		AccountId: c.Param("id"),
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+	c.Header("ETag", strconv.FormatInt(resp.Version, 10))
+	c.JSON(http.StatusOK, toAccountResponse(resp))
+}
+
+// ifMatchVersion parses the If-Match header as the numeric account
+// version from an ETag. present is false if the header was absent, in
+// which case callers skip the optimistic-concurrency check entirely
+// (ExpectedVersion's zero value already means "don't check"). valid is
+// false if the header was present but not a version this gateway issued.
+func ifMatchVersion(c *gin.Context) (version int64, present, valid bool) {
+	h := c.GetHeader("If-Match")
+	if h == "" {
+		return 0, false, true
+	}
+	v, err := strconv.ParseInt(h, 10, 64)
+	if err != nil {
+		return 0, true, false
+	}
+	return v, true, true
+}
+
+// Close handles DELETE /v1/accounts/:id: a soft close. The account is
+// marked closed rather than removed, and stays visible via Get/List. It
+// is rejected (FailedPrecondition -> 422, via ledger-core's CloseAccount)
+// if the account's balance isn't zero, or (AlreadyExists -> 409) if it's
+// already closed. An optional If-Match header pins the close to the
+// version last seen via Get's ETag, rejected with 412 (Aborted from
+// ledger-core) if the account changed in the meantime.
+func (h *AccountHandler) Close(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "id must be a valid UUID")
+		return
+	}
+	expectedVersion, present, valid := ifMatchVersion(c)
+	if present && !valid {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "If-Match must be an account version previously returned as an ETag")
+		return
+	}
+
+	acct, err := h.Client.GetAccount(c.Request.Context(), &ledgerpb.GetAccountRequest{AccountId: id})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+	if acct.UserId != userIDFromContext(c) {
+		writeError(c, http.StatusForbidden, "FORBIDDEN", "account is not owned by the caller")
+		return
+	}
+
+	resp, err := h.Client.CloseAccount(c.Request.Context(), &ledgerpb.CloseAccountRequest{AccountId: id, ExpectedVersion: expectedVersion})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAccountResponse(resp))
+}
+
+// Update handles PATCH /v1/accounts/:id: updating the caller-supplied
+// label and/or metadata on an account. Currency and Balance are rejected
+// if present in the body -- ledger state isn't editable through this
+// endpoint, only through CreateTransaction. An optional If-Match header
+// pins the update to the version last seen via Get's ETag; see Close.
+func (h *AccountHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "id must be a valid UUID")
+		return
+	}
+	expectedVersion, present, valid := ifMatchVersion(c)
+	if present && !valid {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "If-Match must be an account version previously returned as an ETag")
+		return
+	}
+
+	var req UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	if req.Currency != "" || req.Balance != "" {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "currency and balance cannot be changed via update")
+		return
+	}
+	if req.Label != nil && len(*req.Label) > maxAccountLabelLength {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "label must be at most "+strconv.Itoa(maxAccountLabelLength)+" characters")
+		return
+	}
+
+	acct, err := h.Client.GetAccount(c.Request.Context(), &ledgerpb.GetAccountRequest{AccountId: id})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+	if acct.UserId != userIDFromContext(c) {
+		writeError(c, http.StatusForbidden, "FORBIDDEN", "account is not owned by the caller")
+		return
+	}
+
+	resp, err := h.Client.UpdateAccount(c.Request.Context(), &ledgerpb.UpdateAccountRequest{
+		AccountId:       id,
+		Label:           req.Label,
+		Metadata:        req.Metadata,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAccountResponse(resp))
+}
+
+// List handles GET /v1/accounts. With ?include=balance, each account's
+// Balance is refreshed with a live GetBalance call instead of the
+// snapshot ListAccounts itself returns, fanned out with bounded
+// concurrency; a per-account failure populates BalanceError rather than
+// failing the whole list.
+//
+// ?sort=created_at|currency|balance (default: account ID) and
+// ?order=asc|desc (default: asc) control ordering, so pagination is
+// stable across requests instead of depending on map iteration order.
+// ?currency and ?status=active|closed filter the results.
+func (h *AccountHandler) List(c *gin.Context) {
+	page := parseIntParam(c.Query("page"), 1)
+	pageSize, ok := resolvePageSize(c, h.Config)
+	if !ok {
+		return
+	}
+
+	sortField := c.Query("sort")
+	if sortField != "" && !accountSortFields[sortField] {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "sort must be one of created_at, currency, balance")
+		return
+	}
+	order := c.Query("order")
+	if order != "" && order != "asc" && order != "desc" {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "order must be asc or desc")
+		return
+	}
+	currencyFilter := c.Query("currency")
+	if currencyFilter != "" {
+		if _, ok := currency.Lookup(currencyFilter); !ok {
+			writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "unsupported currency code "+currencyFilter)
+			return
+		}
+	}
+	statusFilter := c.Query("status")
+	if statusFilter != "" && !accountStatusFilters[statusFilter] {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "status must be active or closed")
+		return
+	}
+
+	ctx := ledgerclient.ContextWithTenantID(c.Request.Context(), tenantIDFromContext(c, h.Config.AuthTenantClaim))
+	resp, err := h.Client.ListAccounts(ctx, &ledgerpb.ListAccountsRequest{
+		UserId:   userIDFromContext(c),
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+		Sort:     sortField,
+		Order:    order,
+		Currency: currencyFilter,
+		Status:   statusFilter,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	out := ListAccountsResponse{
+		TotalCount: resp.TotalCount,
+		Page:       resp.Page,
+		PageSize:   resp.PageSize,
+	}
+	for _, acct := range resp.Accounts {
+		out.Accounts = append(out.Accounts, toAccountResponse(acct))
+	}
+
+	if includesBalance(c.Query("include")) {
+		h.enrichWithBalances(c.Request.Context(), out.Accounts)
+	}
+
+	out.Pagination = newPageMeta(out.TotalCount, out.Page, out.PageSize, len(out.Accounts))
+
+	c.JSON(http.StatusOK, out)
+}
+
+// includesBalance reports whether the comma-separated include query
+// param asks for balance enrichment.
+func includesBalance(include string) bool {
+	for _, part := range strings.Split(include, ",") {
+		if strings.TrimSpace(part) == "balance" {
+			return true
+		}
+	}
+	return false
+}
+
+// enrichWithBalances refreshes each account's Balance in place via
+// GetBalance, fanned out with bounded concurrency.
+func (h *AccountHandler) enrichWithBalances(ctx context.Context, accounts []AccountResponse) {
+	sem := make(chan struct{}, listBalanceConcurrency)
+
+	var wg sync.WaitGroup
+	for i := range accounts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resp, err := h.Client.GetBalance(ctx, &ledgerpb.GetBalanceRequest{AccountId: accounts[i].ID})
+			if err != nil {
+				_, code, message := grpcToHTTPError(err)
+				accounts[i].BalanceError = &errorBody{Code: code, Message: message}
+				return
+			}
+			accounts[i].Balance = resp.Balance
+		}(i)
+	}
+	wg.Wait()
+}
+
+func toAccountResponse(resp *ledgerpb.AccountResponse) AccountResponse {
+	return AccountResponse{
+		ID:        resp.Id,
+		UserID:    resp.UserId,
+		Currency:  resp.Currency,
+		Balance:   resp.Balance,
+		Version:   resp.Version,
+		CreatedAt: resp.CreatedAt,
+		Status:    resp.Status,
+		Label:     resp.Label,
+		Metadata:  resp.Metadata,
+	}
+}
+
+func parseIntParam(v string, fallback int) int {
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return fallback
+	}
+	return n
+}
+
+// defaultMaxPageSize is used in place of cfg.MaxPageSize when it's unset
+// (e.g. a handler built directly in a test rather than via config.Load).
+const defaultMaxPageSize = 100
+
+// resolvePageSize parses the page_size query parameter for a paginated
+// list endpoint, enforcing cfg.MaxPageSize (defaultMaxPageSize if unset)
+// as a cap. Unlike page -- parsed with parseIntParam, which treats any
+// non-positive or unparsable value as "use the default" -- page_size
+// rejects an explicit value <= 0 with a 400 instead of silently falling
+// back, since a client asking for zero or negative results almost
+// certainly made a mistake worth surfacing. A missing or unparsable
+// page_size still falls back to defaultPageSize, the same as page does.
+// A value over the cap is clamped rather than rejected, with the
+// X-Page-Size-Clamped response header set so the caller can tell its
+// request was adjusted. ok is false if resolvePageSize already wrote an
+// error response and the caller should return without proceeding.
+func resolvePageSize(c *gin.Context, cfg *config.Config) (pageSize int, ok bool) {
+	raw := c.Query("page_size")
+	if raw == "" {
+		return defaultPageSize, true
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return defaultPageSize, true
+	}
+	if n <= 0 {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "page_size must be greater than 0")
+		return 0, false
+	}
+
+	max := cfg.MaxPageSize
+	if max <= 0 {
+		max = defaultMaxPageSize
+	}
+	if n > max {
+		c.Header("X-Page-Size-Clamped", "true")
+		n = max
+	}
+	return n, true
+}
+
+// userIDFromContext returns the authenticated user ID. Until auth
+// middleware is wired in, it falls back to the demo user so the mock is
+// usable out of the box.
+func userIDFromContext(c *gin.Context) string {
+	if v, ok := c.Get("user_id"); ok {
+		if userID, ok := v.(string); ok {
+			return userID
+		}
+	}
+	return "demo-user"
+}
+
+// tenantIDFromContext returns the tenant ID from the claim named by
+// tenantClaim, if middleware.Auth validated one into context, or "" if
+// tenant scoping isn't configured (tenantClaim is empty) or the claim
+// wasn't present -- e.g. Auth isn't enabled, or RequiredClaims doesn't
+// name tenantClaim. Like userIDFromContext, it reads the context key
+// directly rather than importing middleware, so handler tests don't need
+// that package wired in.
+func tenantIDFromContext(c *gin.Context, tenantClaim string) string {
+	if tenantClaim == "" {
+		return ""
+	}
+	v, ok := c.Get("claims")
+	if !ok {
+		return ""
+	}
+	claims, ok := v.(map[string]string)
+	if !ok {
+		return ""
+	}
+	return claims[tenantClaim]
+}
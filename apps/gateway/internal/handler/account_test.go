@@ -0,0 +1,711 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+func newAccountTestRouter(client ledgerclient.LedgerClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAccountHandler(client, &config.Config{})
+	router.GET("/v1/accounts", h.List)
+	router.DELETE("/v1/accounts/:id", h.Close)
+	router.PATCH("/v1/accounts/:id", h.Update)
+	return router
+}
+
+func TestAccountHandlerCreateBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAccountHandler(ledgerclient.NewMockLedgerClient(), &config.Config{})
+	router.POST("/v1/accounts/batch", h.CreateBatch)
+
+	body, _ := json.Marshal(BatchCreateAccountsRequest{Accounts: []CreateAccountRequest{
+		{Currency: "USD"},
+		{Currency: "USD", InitialBalance: "100"},
+		{Currency: "XXX"}, // not a real currency, expected to fail
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/v1/accounts/batch", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp BatchCreateAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(resp.Accounts))
+	}
+	if resp.Accounts[0].Error != nil || resp.Accounts[0].Account == nil {
+		t.Fatalf("expected account 0 to succeed, got %+v", resp.Accounts[0])
+	}
+	if resp.Accounts[1].Error != nil || resp.Accounts[1].Account == nil {
+		t.Fatalf("expected account 1 to succeed, got %+v", resp.Accounts[1])
+	}
+	if resp.Accounts[2].Error == nil {
+		t.Fatalf("expected account 2 (bad currency) to fail, got %+v", resp.Accounts[2])
+	}
+}
+
+func TestAccountHandlerCreateBatch_RejectsTooManyAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAccountHandler(ledgerclient.NewMockLedgerClient(), &config.Config{})
+	router.POST("/v1/accounts/batch", h.CreateBatch)
+
+	specs := make([]CreateAccountRequest, maxBatchCreateAccounts+1)
+	for i := range specs {
+		specs[i] = CreateAccountRequest{Currency: "USD"}
+	}
+	body, _ := json.Marshal(BatchCreateAccountsRequest{Accounts: specs})
+	req := httptest.NewRequest(http.MethodPost, "/v1/accounts/batch", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_PlainListOmitsBalanceError(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	for _, acct := range resp.Accounts {
+		if acct.BalanceError != nil {
+			t.Fatalf("expected no balance_error without include=balance, got %+v", acct)
+		}
+	}
+}
+
+func TestAccountHandlerList_IncludeBalanceFetchesPerAccount(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?include=balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) == 0 {
+		t.Fatal("expected at least one account from the mock")
+	}
+	for _, acct := range resp.Accounts {
+		if acct.BalanceError != nil {
+			t.Fatalf("expected balance enrichment to succeed against the mock, got %+v", acct)
+		}
+		if acct.Balance == "" {
+			t.Fatalf("expected a refreshed balance, got %+v", acct)
+		}
+	}
+}
+
+// newTenantScopedTestRouter builds a router with tenant scoping enabled
+// and a stand-in for middleware.Auth that sets the "claims" context key
+// directly from the X-Test-Tenant header, so tests don't need to pull in
+// the middleware package to exercise tenantIDFromContext.
+func newTenantScopedTestRouter(client ledgerclient.LedgerClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if tenantID := c.GetHeader("X-Test-Tenant"); tenantID != "" {
+			c.Set("claims", map[string]string{"tenant_id": tenantID})
+		}
+		c.Next()
+	})
+	h := NewAccountHandler(client, &config.Config{AuthTenantClaim: "tenant_id"})
+	router.POST("/v1/accounts", h.Create)
+	router.GET("/v1/accounts", h.List)
+	return router
+}
+
+func TestAccountHandlerListCreate_TenantScopingFiltersAccountsByTenant(t *testing.T) {
+	router := newTenantScopedTestRouter(ledgerclient.NewMockLedgerClient())
+
+	createForTenant := func(tenantID string) {
+		body := strings.NewReader(`{"currency":"USD"}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/accounts", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Tenant", tenantID)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create for tenant %s: status = %d, want %d; body=%s", tenantID, rec.Code, http.StatusCreated, rec.Body.String())
+		}
+	}
+	createForTenant("tenant-a")
+	createForTenant("tenant-b")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	req.Header.Set("X-Test-Tenant", "tenant-a")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) != 1 {
+		t.Fatalf("expected exactly one account visible to tenant-a, got %d: %+v", len(resp.Accounts), resp.Accounts)
+	}
+}
+
+func TestAccountHandlerList_InvalidSortReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?sort=id", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_InvalidOrderReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?sort=balance&order=sideways", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_SortByBalanceDescending(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?sort=balance&order=desc", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) < 2 {
+		t.Fatalf("expected at least two seeded accounts, got %d", len(resp.Accounts))
+	}
+	for i := 1; i < len(resp.Accounts); i++ {
+		prev, err := strconv.ParseFloat(resp.Accounts[i-1].Balance, 64)
+		if err != nil {
+			t.Fatalf("parse balance %q: %v", resp.Accounts[i-1].Balance, err)
+		}
+		cur, err := strconv.ParseFloat(resp.Accounts[i].Balance, 64)
+		if err != nil {
+			t.Fatalf("parse balance %q: %v", resp.Accounts[i].Balance, err)
+		}
+		if prev < cur {
+			t.Fatalf("expected balances in descending order, got %+v", resp.Accounts)
+		}
+	}
+}
+
+func TestAccountHandlerList_InvalidCurrencyReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?currency=ZZZ", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_InvalidStatusReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?status=frozen", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_FilterByStatusClosedReturnsNone(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?status=closed", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) != 0 {
+		t.Fatalf("expected no closed accounts in the mock, got %+v", resp.Accounts)
+	}
+}
+
+func TestAccountHandlerList_FilterByCurrency(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?currency=USD", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) == 0 {
+		t.Fatal("expected at least one seeded USD account")
+	}
+}
+
+// failingBalanceClient's GetBalance always fails, to exercise the
+// per-account error path without a real ledger-core.
+type failingBalanceClient struct {
+	ledgerclient.LedgerClient
+}
+
+func (failingBalanceClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	return nil, status.Error(codes.Unavailable, "ledger-core unreachable")
+}
+
+func TestAccountHandlerList_IncludeBalancePerAccountErrorDoesNotFailList(t *testing.T) {
+	router := newAccountTestRouter(failingBalanceClient{LedgerClient: ledgerclient.NewMockLedgerClient()})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?include=balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ListAccountsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Accounts) == 0 {
+		t.Fatal("expected at least one account from the mock")
+	}
+	for _, acct := range resp.Accounts {
+		if acct.BalanceError == nil {
+			t.Fatalf("expected a balance_error when GetBalance fails, got %+v", acct)
+		}
+	}
+}
+
+func TestAccountHandlerClose_ZeroBalanceSucceeds(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/accounts/"+acct.Id, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp AccountResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Status != "closed" {
+		t.Fatalf("expected status closed, got %q", resp.Status)
+	}
+}
+
+func TestAccountHandlerClose_NonZeroBalanceReturns422(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/accounts/"+acct.Id, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerClose_AlreadyClosedReturns409(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if _, err := client.CloseAccount(context.Background(), &ledgerpb.CloseAccountRequest{AccountId: acct.Id}); err != nil {
+		t.Fatalf("CloseAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/accounts/"+acct.Id, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerClose_NotOwnedReturns403(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "someone-else", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/accounts/"+acct.Id, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerClose_InvalidUUIDReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/accounts/not-a-uuid", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerClose_ClosedAccountRejectsTransactions(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	closedAcct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	openAcct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD", InitialBalance: "50"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if _, err := client.CloseAccount(context.Background(), &ledgerpb.CloseAccountRequest{AccountId: closedAcct.Id}); err != nil {
+		t.Fatalf("CloseAccount: %v", err)
+	}
+
+	_, err = client.CreateTransaction(context.Background(), &ledgerpb.CreateTransactionRequest{
+		IdempotencyKey: "tx-against-closed",
+		FromAccountId:  openAcct.Id,
+		ToAccountId:    closedAcct.Id,
+		Amount:         "10",
+		Currency:       "USD",
+	})
+	if err == nil {
+		t.Fatal("expected CreateTransaction against a closed account to fail")
+	}
+}
+
+func TestAccountHandlerUpdate_SetsLabelAndMetadata(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	body := `{"label":"Savings","metadata":{"team":"payments"}}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp AccountResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Label != "Savings" || resp.Metadata["team"] != "payments" {
+		t.Fatalf("expected label/metadata to be set, got %+v", resp)
+	}
+}
+
+func TestAccountHandlerUpdate_RejectsCurrencyChange(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	body := `{"currency":"EUR"}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerUpdate_LabelTooLongReturns400(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	body := `{"label":"` + strings.Repeat("x", maxAccountLabelLength+1) + `"}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerUpdate_NotOwnedReturns403(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "someone-else", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	body := `{"label":"Savings"}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerUpdate_InvalidUUIDReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	body := `{"label":"Savings"}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/not-a-uuid", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerGet_ReturnsVersionETag(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := gin.New()
+	h := NewAccountHandler(client, &config.Config{})
+	router.GET("/v1/accounts/:id", h.Get)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("ETag") != strconv.FormatInt(acct.Version, 10) {
+		t.Fatalf("ETag = %q, want %q", rec.Header().Get("ETag"), strconv.FormatInt(acct.Version, 10))
+	}
+}
+
+func TestAccountHandlerUpdate_IfMatchMismatchReturns412(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(`{"label":"Savings"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", strconv.FormatInt(acct.Version+1, 10))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusPreconditionFailed, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerUpdate_IfMatchMatchSucceeds(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(`{"label":"Savings"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", strconv.FormatInt(acct.Version, 10))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerUpdate_MalformedIfMatchReturns400(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/"+acct.Id, strings.NewReader(`{"label":"Savings"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"not-a-version"`)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerClose_IfMatchMismatchReturns412(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newAccountTestRouter(client)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/accounts/"+acct.Id, nil)
+	req.Header.Set("If-Match", strconv.FormatInt(acct.Version+1, 10))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusPreconditionFailed, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_OversizedPageSizeIsClamped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAccountHandler(ledgerclient.NewMockLedgerClient(), &config.Config{MaxPageSize: 5})
+	router.GET("/v1/accounts", h.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?page_size=500", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("X-Page-Size-Clamped") != "true" {
+		t.Fatalf("X-Page-Size-Clamped header = %q, want %q", rec.Header().Get("X-Page-Size-Clamped"), "true")
+	}
+}
+
+func TestAccountHandlerList_WithinCapPageSizeIsNotClamped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAccountHandler(ledgerclient.NewMockLedgerClient(), &config.Config{MaxPageSize: 5})
+	router.GET("/v1/accounts", h.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?page_size=3", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("X-Page-Size-Clamped") != "" {
+		t.Fatalf("X-Page-Size-Clamped header = %q, want unset", rec.Header().Get("X-Page-Size-Clamped"))
+	}
+}
+
+func TestAccountHandlerList_ZeroPageSizeReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?page_size=0", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestAccountHandlerList_NegativePageSizeReturns400(t *testing.T) {
+	router := newAccountTestRouter(ledgerclient.NewMockLedgerClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts?page_size=-1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ratelimit"
+)
+
+// AdminRateLimitHandler implements the admin endpoints for inspecting and
+// clearing a user's rate-limit window, plus reloading a limiter's Limit
+// and Window live. All three are gated behind
+// config.Config.AdminRateLimitEnabled and middleware.RequireAdmin at the
+// router level; this handler doesn't re-check authorization itself.
+type AdminRateLimitHandler struct {
+	limiter    *ratelimit.Limiter
+	reloadable map[string]*ratelimit.Limiter
+}
+
+// NewAdminRateLimitHandler builds a handler backed by limiter for
+// Status/Reset, and reloadable for ReloadLimits -- the set of Limiters
+// that may be tuned live, keyed by their Config.Scope ("global", "ip",
+// ...).
+func NewAdminRateLimitHandler(limiter *ratelimit.Limiter, reloadable map[string]*ratelimit.Limiter) *AdminRateLimitHandler {
+	return &AdminRateLimitHandler{limiter: limiter, reloadable: reloadable}
+}
+
+// RateLimitStatusResponse reports a user's current standing against the
+// per-user limit.
+type RateLimitStatusResponse struct {
+	UserID    string `json:"user_id"`
+	Count     int64  `json:"count"`
+	Limit     int    `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+// RateLimitResetResponse reports how many counters were actually cleared.
+type RateLimitResetResponse struct {
+	UserID      string `json:"user_id"`
+	KeysCleared int64  `json:"keys_cleared"`
+}
+
+// Status handles GET /admin/ratelimit/:user_id.
+func (h *AdminRateLimitHandler) Status(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	status, err := h.limiter.Status(c.Request.Context(), userID)
+	if err != nil {
+		writeError(c, http.StatusServiceUnavailable, "RATE_LIMITER_UNAVAILABLE", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, RateLimitStatusResponse{
+		UserID:    userID,
+		Count:     status.Count,
+		Limit:     status.Limit,
+		Remaining: status.Remaining,
+	})
+}
+
+// Reset handles DELETE /admin/ratelimit/:user_id: it clears the user's
+// current window so they can make a full Limit of requests again
+// immediately, rather than waiting out the rest of the window.
+func (h *AdminRateLimitHandler) Reset(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	cleared, err := h.limiter.Reset(c.Request.Context(), userID)
+	if err != nil {
+		writeError(c, http.StatusServiceUnavailable, "RATE_LIMITER_UNAVAILABLE", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, RateLimitResetResponse{UserID: userID, KeysCleared: cleared})
+}
+
+// ReloadLimitsRequest is the body of PATCH /admin/ratelimit/config. Scope
+// selects which of h.reloadable to update ("global" or "ip"); Limit and
+// WindowSeconds are its new values. These are the only two rate-limit
+// settings that can be changed without a restart -- everything else
+// (FailOpen, ExceededStatus, which identity a scope keys on) shapes the
+// limiter's behavior structurally and still requires a redeploy.
+type ReloadLimitsRequest struct {
+	Scope         string `json:"scope"`
+	Limit         int    `json:"limit"`
+	WindowSeconds int    `json:"window_seconds"`
+}
+
+// ReloadLimitsResponse echoes back the Config now in effect for Scope.
+type ReloadLimitsResponse struct {
+	Scope         string `json:"scope"`
+	Limit         int    `json:"limit"`
+	WindowSeconds int    `json:"window_seconds"`
+}
+
+// ReloadLimits handles PATCH /admin/ratelimit/config: it atomically swaps
+// in a new Limit and Window for the named scope, taking effect for every
+// request from that point on without restarting the process. Lets an
+// operator tighten a limit in response to an incident and have it apply
+// immediately.
+func (h *AdminRateLimitHandler) ReloadLimits(c *gin.Context) {
+	var req ReloadLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Limit <= 0 || req.WindowSeconds <= 0 {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "limit and window_seconds must be positive")
+		return
+	}
+	limiter, ok := h.reloadable[req.Scope]
+	if !ok {
+		writeError(c, http.StatusNotFound, "UNKNOWN_SCOPE", fmt.Sprintf("no hot-reloadable limiter for scope %q", req.Scope))
+		return
+	}
+
+	updated := limiter.UpdateLimits(req.Limit, time.Duration(req.WindowSeconds)*time.Second)
+	c.JSON(http.StatusOK, ReloadLimitsResponse{
+		Scope:         updated.Scope,
+		Limit:         updated.Limit,
+		WindowSeconds: int(updated.Window / time.Second),
+	})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ratelimit"
+)
+
+func newAdminRateLimitTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	allowlist, _ := ratelimit.NewAllowlist(nil, nil)
+	globalCfg := ratelimit.DefaultGlobalConfig()
+	globalLimiter := ratelimit.NewRateLimiter("127.0.0.1:0", globalCfg, allowlist)
+
+	h := NewAdminRateLimitHandler(globalLimiter, map[string]*ratelimit.Limiter{"global": globalLimiter})
+
+	router := gin.New()
+	router.PATCH("/admin/ratelimit/config", h.ReloadLimits)
+	return router
+}
+
+func TestAdminRateLimitHandlerReloadLimits_UpdatesLiveLimit(t *testing.T) {
+	router := newAdminRateLimitTestRouter()
+
+	body := bytes.NewBufferString(`{"scope":"global","limit":42,"window_seconds":60}`)
+	req := httptest.NewRequest(http.MethodPatch, "/admin/ratelimit/config", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestAdminRateLimitHandlerReloadLimits_UnknownScopeReturns404(t *testing.T) {
+	router := newAdminRateLimitTestRouter()
+
+	body := bytes.NewBufferString(`{"scope":"does-not-exist","limit":42,"window_seconds":60}`)
+	req := httptest.NewRequest(http.MethodPatch, "/admin/ratelimit/config", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminRateLimitHandlerReloadLimits_NonPositiveLimitReturns400(t *testing.T) {
+	router := newAdminRateLimitTestRouter()
+
+	body := bytes.NewBufferString(`{"scope":"global","limit":0,"window_seconds":60}`)
+	req := httptest.NewRequest(http.MethodPatch, "/admin/ratelimit/config", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/currency"
+)
+
+// decimalAmountPattern matches a plain, non-negative decimal number such as
+// "100" or "100.50". big.Rat.SetString also accepts scientific notation
+// ("1e10") and fractions ("1/2"), which are not acceptable client input, so
+// we validate the shape ourselves before parsing.
+var decimalAmountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// parseAmount validates amount as a positive, finite, currency-scaled
+// decimal and returns its canonical (trimmed, fixed-scale) string form. It
+// rejects non-numeric input, zero or negative values, amounts with more
+// decimal places than currencyCode allows, and unrecognized currency codes.
+func parseAmount(amount, currencyCode string) (string, error) {
+	if _, ok := currency.Lookup(currencyCode); !ok {
+		return "", fmt.Errorf("unsupported currency code %q", currencyCode)
+	}
+
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return "", fmt.Errorf("amount is required")
+	}
+	if !decimalAmountPattern.MatchString(amount) {
+		return "", fmt.Errorf("amount %q is not a valid decimal number", amount)
+	}
+
+	rat, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "", fmt.Errorf("amount %q is not a valid decimal number", amount)
+	}
+	if rat.Sign() <= 0 {
+		return "", fmt.Errorf("amount must be greater than zero")
+	}
+
+	decimals := currency.MinorUnits(currencyCode)
+	canonical := rat.FloatString(decimals)
+
+	// FloatString rounds; reject amounts that had more precision than the
+	// currency supports instead of silently truncating client money.
+	roundTripped, _ := new(big.Rat).SetString(canonical)
+	if roundTripped.Cmp(rat) != 0 {
+		return "", fmt.Errorf("amount %q has more decimal places than %s allows (%d)", amount, strings.ToUpper(currencyCode), decimals)
+	}
+
+	return canonical, nil
+}
+
+// exceedsMaxAmount reports whether amount (already canonical) is greater
+// than max, a policy cap expressed as a decimal string in the same
+// currency. Both are parsed as big.Rat rather than compared as strings,
+// since differently-lengthed decimals don't compare lexicographically the
+// way they compare numerically.
+func exceedsMaxAmount(amount, max string) bool {
+	a, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return false
+	}
+	m, ok := new(big.Rat).SetString(max)
+	if !ok {
+		return false
+	}
+	return a.Cmp(m) > 0
+}
+
+// parseInitialBalance validates an account's opening balance. Unlike
+// parseAmount it allows zero (a freshly opened account has no money yet)
+// and an empty string (defaulting to zero), but still enforces currency
+// scale and rejects negative values.
+func parseInitialBalance(balance, currencyCode string) (string, error) {
+	if _, ok := currency.Lookup(currencyCode); !ok {
+		return "", fmt.Errorf("unsupported currency code %q", currencyCode)
+	}
+
+	balance = strings.TrimSpace(balance)
+	if balance == "" {
+		return "", nil
+	}
+	if !decimalAmountPattern.MatchString(balance) {
+		return "", fmt.Errorf("initial_balance %q is not a valid decimal number", balance)
+	}
+
+	rat, ok := new(big.Rat).SetString(balance)
+	if !ok {
+		return "", fmt.Errorf("initial_balance %q is not a valid decimal number", balance)
+	}
+
+	decimals := currency.MinorUnits(currencyCode)
+	canonical := rat.FloatString(decimals)
+	roundTripped, _ := new(big.Rat).SetString(canonical)
+	if roundTripped.Cmp(rat) != 0 {
+		return "", fmt.Errorf("initial_balance %q has more decimal places than %s allows (%d)", balance, strings.ToUpper(currencyCode), decimals)
+	}
+
+	return canonical, nil
+}
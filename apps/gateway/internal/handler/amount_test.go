@@ -0,0 +1,43 @@
+package handler
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		want     string
+		wantErr  bool
+	}{
+		{name: "usd two decimals ok", amount: "100.99", currency: "USD", want: "100.99"},
+		{name: "usd three decimals rejected", amount: "1.005", currency: "USD", wantErr: true},
+		{name: "jpy whole number ok", amount: "1500", currency: "JPY", want: "1500"},
+		{name: "jpy fractional rejected", amount: "100.999", currency: "JPY", wantErr: true},
+		{name: "bhd three decimals ok", amount: "10.500", currency: "BHD", want: "10.500"},
+		{name: "bhd four decimals rejected", amount: "10.5001", currency: "BHD", wantErr: true},
+		{name: "unknown currency rejected", amount: "100.00", currency: "XXX", wantErr: true},
+		{name: "non-numeric rejected", amount: "abc", currency: "USD", wantErr: true},
+		{name: "negative rejected", amount: "-5", currency: "USD", wantErr: true},
+		{name: "zero rejected", amount: "0", currency: "USD", wantErr: true},
+		{name: "scientific notation rejected", amount: "1e10", currency: "USD", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseAmount(tc.amount, tc.currency)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseAmount(%q, %q) = %q, want error", tc.amount, tc.currency, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAmount(%q, %q) unexpected error: %v", tc.amount, tc.currency, err)
+			}
+			if got != tc.want {
+				t.Fatalf("parseAmount(%q, %q) = %q, want %q", tc.amount, tc.currency, got, tc.want)
+			}
+		})
+	}
+}
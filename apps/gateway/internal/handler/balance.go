@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/balancecache"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/localeformat"
+)
+
+// BalanceResponse is the gateway's wire format for an account balance.
+type BalanceResponse struct {
+	AccountID string `json:"account_id"`
+	Currency  string `json:"currency"`
+	Balance   string `json:"balance"`
+	// DisplayBalance is Balance re-rendered with locale-appropriate
+	// thousands and decimal separators (see localeformat.Format), set
+	// only when the request asked for it via ?format=locale. Balance
+	// itself is always the plain canonical string, unaffected by this
+	// field, so existing programmatic clients never need to change.
+	DisplayBalance string `json:"display_balance,omitempty"`
+	// Stale is true when Balance was served from balancecache rather than
+	// ledger-core, because the live call failed.
+	Stale bool `json:"stale"`
+	// StaleAgeSeconds is how long ago the cached value was recorded.
+	// Omitted when Stale is false.
+	StaleAgeSeconds float64 `json:"stale_age_seconds,omitempty"`
+}
+
+// BalanceHandler implements the /v1/accounts/:id/balance endpoint.
+type BalanceHandler struct {
+	Client ledgerclient.LedgerClient
+	Config *config.Config
+	Cache  balancecache.Cache
+}
+
+func NewBalanceHandler(client ledgerclient.LedgerClient, cfg *config.Config, cache balancecache.Cache) *BalanceHandler {
+	return &BalanceHandler{Client: client, Config: cfg, Cache: cache}
+}
+
+// Get handles GET /v1/accounts/:id/balance. When StaleBalanceFallbackEnabled
+// is set and the live call fails with Unavailable or DeadlineExceeded, it
+// falls back to the last balance seen for this account, if any, marking
+// the response stale rather than failing the read outright.
+func (h *BalanceHandler) Get(c *gin.Context) {
+	accountID := c.Param("id")
+
+	resp, err := h.Client.GetBalance(c.Request.Context(), &ledgerpb.GetBalanceRequest{
+		AccountId: accountID,
+	})
+	if err != nil {
+		if h.Config != nil && h.Config.StaleBalanceFallbackEnabled && isFallbackEligible(err) {
+			if entry, ok := h.Cache.Get(accountID); ok {
+				c.Header("Warning", `110 gateway "Response is stale"`)
+				out := BalanceResponse{
+					AccountID:       accountID,
+					Currency:        entry.Currency,
+					Balance:         entry.Balance,
+					Stale:           true,
+					StaleAgeSeconds: time.Since(entry.CachedAt).Seconds(),
+				}
+				h.setDisplayBalance(c, &out)
+				c.JSON(http.StatusOK, out)
+				return
+			}
+		}
+		writeGRPCError(c, err)
+		return
+	}
+
+	if h.Cache != nil {
+		h.Cache.Set(accountID, balancecache.Entry{
+			Currency: resp.Currency,
+			Balance:  resp.Balance,
+			CachedAt: time.Now(),
+		})
+	}
+
+	c.Header("ETag", strconv.FormatInt(resp.Version, 10))
+	out := BalanceResponse{
+		AccountID: resp.AccountId,
+		Currency:  resp.Currency,
+		Balance:   resp.Balance,
+	}
+	h.setDisplayBalance(c, &out)
+	c.JSON(http.StatusOK, out)
+}
+
+// setDisplayBalance sets out.DisplayBalance when the caller asked for
+// locale-formatted output via ?format=locale, leaving out.Balance -- the
+// canonical string every programmatic client relies on -- untouched
+// either way. The locale itself comes from an explicit ?locale= query
+// param if set, else the best match in the Accept-Language header, else
+// localeformat.DefaultLocale.
+func (h *BalanceHandler) setDisplayBalance(c *gin.Context, out *BalanceResponse) {
+	if c.Query("format") != "locale" {
+		return
+	}
+	locale := c.Query("locale")
+	if locale == "" {
+		locale = localeformat.LocaleFromAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+	formatted, err := localeformat.Format(out.Balance, out.Currency, locale)
+	if err != nil {
+		return
+	}
+	out.DisplayBalance = formatted
+}
+
+// maxBatchBalanceAccounts caps how many account IDs GetBatch will accept
+// in one request, so a client can't force an unbounded fan-out.
+const maxBatchBalanceAccounts = 50
+
+// batchBalanceConcurrency bounds how many GetAccount calls GetBatch issues
+// at once. It's independent of (and typically smaller than) the ledger
+// client's own bulkhead, since a single request shouldn't be able to eat
+// the whole bulkhead budget on its own.
+const batchBalanceConcurrency = 8
+
+// BatchBalancesRequest is the request body for POST /v1/accounts/balances.
+type BatchBalancesRequest struct {
+	AccountIDs []string `json:"account_ids" binding:"required,min=1"`
+}
+
+// BalanceResult is one account's outcome within a batch balance lookup:
+// either Currency/Balance on success, or Error on a per-account failure
+// (e.g. not found, or not owned by the caller). Exactly one is populated.
+type BalanceResult struct {
+	AccountID string     `json:"account_id"`
+	Currency  string     `json:"currency,omitempty"`
+	Balance   string     `json:"balance,omitempty"`
+	Error     *errorBody `json:"error,omitempty"`
+}
+
+// BatchBalancesResponse is the response body for POST /v1/accounts/balances.
+type BatchBalancesResponse struct {
+	Balances []BalanceResult `json:"balances"`
+}
+
+// GetBatch handles POST /v1/accounts/balances: balances for several
+// accounts in one call, so a dashboard doesn't need one round trip per
+// account. Every requested account must be owned by the caller; accounts
+// owned by someone else come back as a per-account FORBIDDEN error rather
+// than failing the whole batch.
+func (h *BalanceHandler) GetBatch(c *gin.Context) {
+	var req BatchBalancesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	if len(req.AccountIDs) > maxBatchBalanceAccounts {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "at most "+strconv.Itoa(maxBatchBalanceAccounts)+" account_ids are allowed per request")
+		return
+	}
+
+	callerID := userIDFromContext(c)
+	results := h.fetchBalances(c.Request.Context(), req.AccountIDs, callerID)
+	c.JSON(http.StatusOK, BatchBalancesResponse{Balances: results})
+}
+
+// fetchBalances looks up each account ID's balance via GetAccount (which
+// also carries ownership), fanning out with bounded concurrency and
+// aggregating per-account successes and failures. Results preserve the
+// order of accountIDs.
+func (h *BalanceHandler) fetchBalances(ctx context.Context, accountIDs []string, callerID string) []BalanceResult {
+	results := make([]BalanceResult, len(accountIDs))
+	sem := make(chan struct{}, batchBalanceConcurrency)
+
+	var wg sync.WaitGroup
+	for i, accountID := range accountIDs {
+		wg.Add(1)
+		go func(i int, accountID string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = h.fetchOneBalance(ctx, accountID, callerID)
+		}(i, accountID)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (h *BalanceHandler) fetchOneBalance(ctx context.Context, accountID, callerID string) BalanceResult {
+	resp, err := h.Client.GetAccount(ctx, &ledgerpb.GetAccountRequest{AccountId: accountID})
+	if err != nil {
+		_, code, message := grpcToHTTPError(err)
+		return BalanceResult{AccountID: accountID, Error: &errorBody{Code: code, Message: message}}
+	}
+	if resp.UserId != callerID {
+		return BalanceResult{AccountID: accountID, Error: &errorBody{Code: "FORBIDDEN", Message: "account is not owned by the caller"}}
+	}
+	return BalanceResult{AccountID: accountID, Currency: resp.Currency, Balance: resp.Balance}
+}
+
+// isFallbackEligible reports whether err is the kind of failure a stale
+// cached balance should cover: the core being down or too slow, not a
+// client error like an invalid account ID.
+func isFallbackEligible(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/balancecache"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// unavailableClient's GetBalance always fails with codes.Unavailable, to
+// exercise the stale-cache fallback path without a real ledger-core.
+type unavailableClient struct {
+	ledgerclient.LedgerClient
+}
+
+func (unavailableClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	return nil, status.Error(codes.Unavailable, "ledger-core unreachable")
+}
+
+func newBalanceTestRouter(cfg *config.Config, cache balancecache.Cache) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(unavailableClient{}, cfg, cache)
+	router.GET("/v1/accounts/:id/balance", h.Get)
+	return router
+}
+
+func TestBalanceHandlerGet_FallsBackToStaleCacheWhenEnabled(t *testing.T) {
+	cache := balancecache.NewMemoryCache()
+	cache.Set("acct-1", balancecache.Entry{Currency: "USD", Balance: "42.00"})
+
+	router := newBalanceTestRouter(&config.Config{StaleBalanceFallbackEnabled: true}, cache)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/acct-1/balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("Warning") == "" {
+		t.Fatal("expected a Warning header on a stale response")
+	}
+}
+
+func TestBalanceHandlerGet_FailsClosedWhenFallbackDisabled(t *testing.T) {
+	cache := balancecache.NewMemoryCache()
+	cache.Set("acct-1", balancecache.Entry{Currency: "USD", Balance: "42.00"})
+
+	router := newBalanceTestRouter(&config.Config{StaleBalanceFallbackEnabled: false}, cache)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/acct-1/balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+}
+
+func TestBalanceHandlerGetBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(ledgerclient.NewMockLedgerClient(), &config.Config{}, balancecache.NewMemoryCache())
+	router.POST("/v1/accounts/balances", h.GetBatch)
+
+	body, _ := json.Marshal(BatchBalancesRequest{AccountIDs: []string{
+		"00000000-0000-0000-0000-000000000001",
+		"00000000-0000-0000-0000-000000000002",
+		"00000000-0000-0000-0000-000000000099", // doesn't exist
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/v1/accounts/balances", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp BatchBalancesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Balances) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(resp.Balances))
+	}
+	if resp.Balances[0].Error != nil || resp.Balances[0].Balance == "" {
+		t.Fatalf("expected account 1 to succeed, got %+v", resp.Balances[0])
+	}
+	if resp.Balances[1].Error != nil || resp.Balances[1].Balance == "" {
+		t.Fatalf("expected account 2 to succeed, got %+v", resp.Balances[1])
+	}
+	if resp.Balances[2].Error == nil {
+		t.Fatalf("expected account 3 (nonexistent) to fail, got %+v", resp.Balances[2])
+	}
+}
+
+func TestBalanceHandlerGetBatch_RejectsTooManyAccountIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(ledgerclient.NewMockLedgerClient(), &config.Config{}, balancecache.NewMemoryCache())
+	router.POST("/v1/accounts/balances", h.GetBatch)
+
+	ids := make([]string, maxBatchBalanceAccounts+1)
+	for i := range ids {
+		ids[i] = "00000000-0000-0000-0000-000000000001"
+	}
+	body, _ := json.Marshal(BatchBalancesRequest{AccountIDs: ids})
+	req := httptest.NewRequest(http.MethodPost, "/v1/accounts/balances", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestBalanceHandlerGet_NoCacheStillFails(t *testing.T) {
+	router := newBalanceTestRouter(&config.Config{StaleBalanceFallbackEnabled: true}, balancecache.NewMemoryCache())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/unknown-acct/balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+}
+
+func TestBalanceHandlerGet_ReturnsVersionETag(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(client, &config.Config{}, nil)
+	router.GET("/v1/accounts/:id/balance", h.Get)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Fatal("expected an ETag header")
+	}
+}
+
+func TestBalanceHandlerGet_DefaultOmitsDisplayBalance(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(client, &config.Config{}, nil)
+	router.GET("/v1/accounts/:id/balance", h.Get)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/balance", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp BalanceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.DisplayBalance != "" {
+		t.Fatalf("display_balance = %q, want empty without ?format=locale", resp.DisplayBalance)
+	}
+}
+
+func TestBalanceHandlerGet_FormatLocaleUsesExplicitLocaleParam(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(client, &config.Config{}, nil)
+	router.GET("/v1/accounts/:id/balance", h.Get)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/balance?format=locale&locale=de-DE", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp BalanceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.DisplayBalance == "" {
+		t.Fatal("expected a non-empty display_balance with ?format=locale")
+	}
+	if resp.Balance == resp.DisplayBalance {
+		t.Fatalf("balance and display_balance both = %q; de-DE should use a comma decimal mark", resp.Balance)
+	}
+}
+
+func TestBalanceHandlerGet_FormatLocaleFallsBackToAcceptLanguage(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewBalanceHandler(client, &config.Config{}, nil)
+	router.GET("/v1/accounts/:id/balance", h.Get)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/balance?format=locale", nil)
+	req.Header.Set("Accept-Language", "de-DE,en;q=0.8")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp BalanceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.DisplayBalance == "" {
+		t.Fatal("expected a non-empty display_balance derived from Accept-Language")
+	}
+}
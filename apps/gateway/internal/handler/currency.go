@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/currency"
+)
+
+// CurrencyHandler implements the /v1/currencies endpoint.
+type CurrencyHandler struct{}
+
+func NewCurrencyHandler() *CurrencyHandler {
+	return &CurrencyHandler{}
+}
+
+// ListCurrenciesResponse is the gateway's wire format for the supported
+// currency table.
+type ListCurrenciesResponse struct {
+	Currencies []currency.Metadata `json:"currencies"`
+}
+
+// List handles GET /v1/currencies: every currency code this deployment
+// recognizes, independent of config.Config.CurrencyAllowlist (which further
+// restricts this set but isn't reflected here, since the allowlist is
+// per-deployment policy rather than a property of the currency itself).
+func (h *CurrencyHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, ListCurrenciesResponse{Currencies: currency.All()})
+}
@@ -0,0 +1,106 @@
+// Package handler implements the gateway's HTTP API on top of a
+// ledgerclient.LedgerClient.
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorResponse is the gateway's standard error envelope.
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError writes the standard error envelope with the given HTTP status.
+func writeError(c *gin.Context, httpStatus int, code, message string) {
+	c.AbortWithStatusJSON(httpStatus, errorResponse{Error: errorBody{Code: code, Message: message}})
+}
+
+// grpcToHTTPError maps an error returned by a LedgerClient call to an HTTP
+// status and error code. Errors that aren't gRPC statuses (e.g. the mock
+// client's plain errors) are treated as INTERNAL.
+func grpcToHTTPError(err error) (httpStatus int, code, message string) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusInternalServerError, "INTERNAL", err.Error()
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest, "INVALID_ARGUMENT", st.Message()
+	case codes.NotFound:
+		return http.StatusNotFound, "NOT_FOUND", st.Message()
+	case codes.AlreadyExists:
+		return http.StatusConflict, "ALREADY_EXISTS", st.Message()
+	case codes.PermissionDenied:
+		return http.StatusForbidden, "PERMISSION_DENIED", st.Message()
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized, "UNAUTHENTICATED", st.Message()
+	case codes.FailedPrecondition:
+		return http.StatusUnprocessableEntity, "FAILED_PRECONDITION", st.Message()
+	case codes.Aborted:
+		return http.StatusPreconditionFailed, "PRECONDITION_FAILED", st.Message()
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", st.Message()
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable, "UNAVAILABLE", st.Message()
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", st.Message()
+	default:
+		return http.StatusInternalServerError, "INTERNAL", st.Message()
+	}
+}
+
+// defaultRetryAfterSeconds is used for ResourceExhausted responses when
+// core's status carries no RetryInfo detail to size the backoff from.
+const defaultRetryAfterSeconds = "1"
+
+// writeGRPCError maps err via grpcToHTTPError and writes the envelope. The
+// caller's handler should return immediately afterward.
+func writeGRPCError(c *gin.Context, err error) {
+	httpStatus, code, message := grpcToHTTPError(err)
+	if httpStatus == http.StatusTooManyRequests {
+		c.Header("Retry-After", retryAfterSeconds(err))
+	}
+	writeError(c, httpStatus, code, message)
+}
+
+// retryAfterSeconds sizes a Retry-After value from err's gRPC RetryInfo
+// detail, if core attached one -- this is how core tells a well-behaved
+// client how long to back off, rather than it guessing. Retry-After is
+// specified in whole seconds, so a sub-second delay is rounded up to 1
+// rather than truncated to 0, which would tell the client not to wait at
+// all. Falls back to defaultRetryAfterSeconds when err carries no status,
+// or its status carries no RetryInfo.
+func retryAfterSeconds(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return defaultRetryAfterSeconds
+	}
+	for _, d := range st.Details() {
+		retryInfo, ok := d.(*errdetails.RetryInfo)
+		if !ok || retryInfo.RetryDelay == nil {
+			continue
+		}
+		seconds := retryInfo.RetryDelay.Seconds
+		if retryInfo.RetryDelay.Nanos > 0 {
+			seconds++
+		}
+		if seconds < 1 {
+			seconds = 1
+		}
+		return strconv.FormatInt(seconds, 10)
+	}
+	return defaultRetryAfterSeconds
+}
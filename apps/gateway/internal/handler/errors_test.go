@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRetryAfterSeconds_UsesRetryInfoDetailWhenPresent(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "rate limited").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(5 * time.Second),
+	})
+	if err != nil {
+		t.Fatalf("attach RetryInfo detail: %v", err)
+	}
+
+	if got := retryAfterSeconds(st.Err()); got != "5" {
+		t.Fatalf("retryAfterSeconds = %q, want %q", got, "5")
+	}
+}
+
+func TestRetryAfterSeconds_RoundsSubSecondDelayUpToOne(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "rate limited").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(200 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatalf("attach RetryInfo detail: %v", err)
+	}
+
+	if got := retryAfterSeconds(st.Err()); got != "1" {
+		t.Fatalf("retryAfterSeconds = %q, want %q", got, "1")
+	}
+}
+
+func TestRetryAfterSeconds_FallsBackToDefaultWithoutRetryInfo(t *testing.T) {
+	st := status.New(codes.ResourceExhausted, "rate limited")
+
+	if got := retryAfterSeconds(st.Err()); got != defaultRetryAfterSeconds {
+		t.Fatalf("retryAfterSeconds = %q, want %q", got, defaultRetryAfterSeconds)
+	}
+}
+
+func TestRetryAfterSeconds_FallsBackToDefaultForNonStatusError(t *testing.T) {
+	if got := retryAfterSeconds(errors.New("boom")); got != defaultRetryAfterSeconds {
+		t.Fatalf("retryAfterSeconds = %q, want %q", got, defaultRetryAfterSeconds)
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+)
+
+// HealthCheck is one dependency Readiness probes. Checks run concurrently
+// and independently, so a slow dependency only delays its own entry in
+// the response rather than every other check.
+type HealthCheck struct {
+	// Name identifies the dependency in the response, e.g. "ledger_core"
+	// or "redis_rate_limit".
+	Name string
+	// Timeout bounds how long Check may run before it's reported failed
+	// with a deadline-exceeded error. Zero means no timeout beyond the
+	// request's own context.
+	Timeout time.Duration
+	// Required marks whether this dependency failing should pull the
+	// gateway out of rotation (Readiness returns 503) or just be
+	// reported alongside an otherwise-200 response. Some dependencies --
+	// Redis backing rate limiting, say -- are optional: the gateway
+	// degrades that one feature rather than refusing all traffic.
+	Required bool
+	Check    func(ctx context.Context) error
+}
+
+// healthCheckResult is one HealthCheck's outcome, as reported in
+// Readiness's response body.
+type healthCheckResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Required  bool   `json:"required"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthHandler implements the gateway's liveness and readiness endpoints.
+type HealthHandler struct {
+	client ledgerclient.LedgerClient
+	checks []HealthCheck
+
+	// shuttingDown is set by SetShuttingDown once the process has started
+	// draining, so Readiness starts reporting not_ready immediately
+	// rather than waiting for a dependency check to fail.
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler builds a HealthHandler whose Readiness probes
+// ledger-core's connection (via ledgerclient.Warmer, when client
+// implements it) and, when configured, the Redis instances backing rate
+// limiting and idempotency. Every check defaults to optional (see
+// config.Config.HealthLedgerCoreRequired/HealthRedisRequired), so a
+// deployment that doesn't opt in sees Readiness behave exactly as it did
+// before these checks existed: always 200.
+func NewHealthHandler(client ledgerclient.LedgerClient, cfg *config.Config) *HealthHandler {
+	h := &HealthHandler{client: client}
+
+	if warmer, ok := client.(ledgerclient.Warmer); ok {
+		h.checks = append(h.checks, HealthCheck{
+			Name:     "ledger_core",
+			Timeout:  cfg.HealthLedgerCoreTimeout,
+			Required: cfg.HealthLedgerCoreRequired,
+			Check:    warmer.Warmup,
+		})
+	}
+	if cfg.RateLimitRedisAddr != "" {
+		h.checks = append(h.checks, HealthCheck{
+			Name:     "redis_rate_limit",
+			Timeout:  cfg.HealthRedisTimeout,
+			Required: cfg.HealthRedisRequired,
+			Check:    redisPingCheck(cfg.RateLimitRedisAddr),
+		})
+	}
+	if cfg.IdempotencyRedisAddr != "" {
+		h.checks = append(h.checks, HealthCheck{
+			Name:     "redis_idempotency",
+			Timeout:  cfg.HealthRedisTimeout,
+			Required: cfg.HealthRedisRequired,
+			Check:    redisPingCheck(cfg.IdempotencyRedisAddr),
+		})
+	}
+	return h
+}
+
+// redisPingCheck returns a HealthCheck.Check that dials addr fresh and
+// pings it. It's a throwaway client rather than one shared with
+// ratelimit/idempotency -- a dial-then-ping is cheap relative to a
+// readiness poll's own interval, and this keeps the health package from
+// depending on either package's internals.
+func redisPingCheck(addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		defer client.Close()
+		return client.Ping(ctx).Err()
+	}
+}
+
+// SetShuttingDown marks the gateway as draining, so every subsequent
+// Readiness call reports not_ready without running any dependency checks.
+// main calls this on receiving SIGINT/SIGTERM, before the drain delay
+// configured by config.Config.ShutdownDrainDelay, so a load balancer
+// polling /readyz sees the instance leave rotation before it stops
+// accepting connections.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Liveness handles GET /healthz. It reports healthy as long as the process
+// is running and able to handle HTTP requests at all.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness handles GET /readyz. It runs every registered HealthCheck
+// concurrently and reports each one's status and latency under
+// "dependencies". The overall response is 503 "not_ready" only if a
+// Required check failed; an optional check failing is reported but
+// doesn't change the 200. With no checks registered (the default: no
+// Warmer, no Redis addrs configured), the response is the same "ok" it
+// always was. When the LedgerClient exposes ledgerclient.ConnStater, its
+// connection state is also included so a flapping or down connection is
+// visible without digging through logs. Once SetShuttingDown has been
+// called, none of this runs -- Readiness reports not_ready straight
+// away.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": "shutting_down"})
+		return
+	}
+
+	results := make([]healthCheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(c.Request.Context(), check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	httpStatus := http.StatusOK
+	overallStatus := "ok"
+	for _, r := range results {
+		if r.Status != "ok" && r.Required {
+			httpStatus = http.StatusServiceUnavailable
+			overallStatus = "not_ready"
+		}
+	}
+
+	body := gin.H{"status": overallStatus}
+	if len(results) > 0 {
+		body["dependencies"] = results
+	}
+	if stater, ok := h.client.(ledgerclient.ConnStater); ok {
+		body["ledger_core_connections"] = stater.ConnState()
+	}
+	c.JSON(httpStatus, body)
+}
+
+// runHealthCheck runs check.Check under check.Timeout (unbounded beyond
+// ctx if Timeout is zero) and times it, regardless of outcome.
+func runHealthCheck(ctx context.Context, check HealthCheck) healthCheckResult {
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, check.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check.Check(ctx)
+	result := healthCheckResult{
+		Name:      check.Name,
+		Required:  check.Required,
+		Status:    "ok",
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newHealthTestRouter(checks []HealthCheck) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := &HealthHandler{checks: checks}
+	router.GET("/readyz", h.Readiness)
+	return router
+}
+
+func TestReadiness_NoChecksRegisteredReturnsOK(t *testing.T) {
+	router := newHealthTestRouter(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestReadiness_OptionalCheckFailingStaysOK(t *testing.T) {
+	router := newHealthTestRouter([]HealthCheck{
+		{Name: "redis_rate_limit", Required: false, Check: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	deps, ok := body["dependencies"].([]interface{})
+	if !ok || len(deps) != 1 {
+		t.Fatalf("expected one dependency entry, got %+v", body["dependencies"])
+	}
+}
+
+func TestReadiness_RequiredCheckFailingReturns503(t *testing.T) {
+	router := newHealthTestRouter([]HealthCheck{
+		{Name: "ledger_core", Required: true, Check: func(ctx context.Context) error {
+			return errors.New("unreachable")
+		}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+}
+
+func TestReadiness_AfterSetShuttingDownReturns503WithoutRunningChecks(t *testing.T) {
+	ran := false
+	h := &HealthHandler{checks: []HealthCheck{
+		{Name: "ledger_core", Required: true, Check: func(ctx context.Context) error {
+			ran = true
+			return nil
+		}},
+	}}
+	h.SetShuttingDown()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", h.Readiness)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+	if ran {
+		t.Fatal("expected the dependency check to be skipped once shutting down")
+	}
+}
+
+func TestReadiness_RequiredCheckPassingStaysOK(t *testing.T) {
+	router := newHealthTestRouter([]HealthCheck{
+		{Name: "ledger_core", Required: true, Check: func(ctx context.Context) error {
+			return nil
+		}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
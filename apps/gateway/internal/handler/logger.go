@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/middleware"
+)
+
+// Logger returns a request-scoped logger tagged with the request ID, user
+// ID, and route of c's request, for handlers to use instead of formatting
+// that correlation boilerplate by hand -- including around calls made
+// through the ledgerclient layer. When middleware.Logging wasn't
+// registered (e.g. a handler unit test built with gin.CreateTestContext),
+// it falls back to a plain, untagged logger instead of panicking.
+func Logger(c *gin.Context) *middleware.RequestLogger {
+	return middleware.RequestLoggerFromContext(c)
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLogger_NoOpWhenLoggingMiddlewareNotRegistered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	Logger(c).Printf("handler test ran without the middleware chain")
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIHandler serves the gateway's OpenAPI 3 spec, generated from the
+// same request/response structs the other handlers use, so it can't drift
+// from the wire format the way a hand-maintained spec file would.
+type OpenAPIHandler struct {
+	once sync.Once
+	doc  map[string]interface{}
+}
+
+func NewOpenAPIHandler() *OpenAPIHandler {
+	return &OpenAPIHandler{}
+}
+
+// Spec handles GET /openapi.json. Unauthenticated, like /healthz: the spec
+// describes the API's shape, not its data.
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	h.once.Do(func() { h.doc = buildOpenAPIDoc() })
+	c.JSON(http.StatusOK, h.doc)
+}
+
+// errorEnvelopeSchema mirrors errorResponse/errorBody by hand, since those
+// types are unexported (callers shouldn't construct error bodies directly;
+// writeError and writeGRPCError own that) and so aren't reachable by
+// reflection from here.
+var errorEnvelopeSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"error": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"code":    map[string]interface{}{"type": "string"},
+				"message": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"code", "message"},
+		},
+	},
+	"required": []string{"error"},
+}
+
+func buildOpenAPIDoc() map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error":                            errorEnvelopeSchema,
+		"CreateTransactionRequest":         reflectSchema(reflect.TypeOf(CreateTransactionRequest{})),
+		"TransactionResponse":              reflectSchema(reflect.TypeOf(TransactionResponse{})),
+		"CreateMultiLegTransactionRequest": reflectSchema(reflect.TypeOf(CreateMultiLegTransactionRequest{})),
+		"CreateInternalTransferRequest":    reflectSchema(reflect.TypeOf(CreateInternalTransferRequest{})),
+		"MultiLegTransactionResponse":      reflectSchema(reflect.TypeOf(MultiLegTransactionResponse{})),
+		"ListTransactionsResponse":         reflectSchema(reflect.TypeOf(ListTransactionsResponse{})),
+		"CreateAccountRequest":             reflectSchema(reflect.TypeOf(CreateAccountRequest{})),
+		"UpdateAccountRequest":             reflectSchema(reflect.TypeOf(UpdateAccountRequest{})),
+		"AccountResponse":                  reflectSchema(reflect.TypeOf(AccountResponse{})),
+		"ListAccountsResponse":             reflectSchema(reflect.TypeOf(ListAccountsResponse{})),
+		"BalanceResponse":                  reflectSchema(reflect.TypeOf(BalanceResponse{})),
+		"BatchBalancesRequest":             reflectSchema(reflect.TypeOf(BatchBalancesRequest{})),
+		"BatchBalancesResponse":            reflectSchema(reflect.TypeOf(BatchBalancesResponse{})),
+		"ListCurrenciesResponse":           reflectSchema(reflect.TypeOf(ListCurrenciesResponse{})),
+		"SummaryResponse":                  reflectSchema(reflect.TypeOf(SummaryResponse{})),
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "An error occurred",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": ref("Error"),
+			},
+		},
+	}
+
+	paths := map[string]interface{}{
+		"/v1/currencies": map[string]interface{}{
+			"get": op("List supported currencies", jsonResponse(http.StatusOK, "ListCurrenciesResponse")),
+		},
+		"/v1/transactions": map[string]interface{}{
+			"post": op("Create a transaction", jsonResponses(map[int]string{http.StatusCreated: "TransactionResponse"}), withRequestBody("CreateTransactionRequest"), withErrorResponses(errorResponse)),
+		},
+		"/v1/transactions/multi": map[string]interface{}{
+			"post": op("Create a multi-leg (split) transaction", jsonResponses(map[int]string{http.StatusCreated: "MultiLegTransactionResponse"}), withRequestBody("CreateMultiLegTransactionRequest"), withErrorResponses(errorResponse)),
+		},
+		"/v1/transfers/internal": map[string]interface{}{
+			"post": op("Transfer between two accounts owned by the caller", jsonResponses(map[int]string{http.StatusCreated: "TransactionResponse"}), withRequestBody("CreateInternalTransferRequest"), withErrorResponses(errorResponse)),
+		},
+		"/v1/accounts": map[string]interface{}{
+			"post": op("Create an account", jsonResponses(map[int]string{http.StatusCreated: "AccountResponse"}), withRequestBody("CreateAccountRequest"), withErrorResponses(errorResponse)),
+			"get":  op("List the caller's accounts", jsonResponse(http.StatusOK, "ListAccountsResponse"), withErrorResponses(errorResponse)),
+		},
+		"/v1/accounts/{id}": map[string]interface{}{
+			"get":    op("Get an account", jsonResponse(http.StatusOK, "AccountResponse"), withErrorResponses(errorResponse), withPathParam("id")),
+			"delete": op("Close an account", jsonResponse(http.StatusOK, "AccountResponse"), withErrorResponses(errorResponse), withPathParam("id")),
+			"patch":  op("Update an account's label or metadata", jsonResponse(http.StatusOK, "AccountResponse"), withRequestBody("UpdateAccountRequest"), withErrorResponses(errorResponse), withPathParam("id")),
+		},
+		"/v1/accounts/{id}/balance": map[string]interface{}{
+			"get": op("Get an account's balance", jsonResponse(http.StatusOK, "BalanceResponse"), withErrorResponses(errorResponse), withPathParam("id")),
+		},
+		"/v1/summary": map[string]interface{}{
+			"get": op("Summarize the caller's account balances by currency", jsonResponse(http.StatusOK, "SummaryResponse"), withErrorResponses(errorResponse)),
+		},
+		"/v1/accounts/balances": map[string]interface{}{
+			"post": op("Get balances for multiple accounts", jsonResponse(http.StatusOK, "BatchBalancesResponse"), withRequestBody("BatchBalancesRequest"), withErrorResponses(errorResponse)),
+		},
+		"/v1/accounts/{id}/transactions": map[string]interface{}{
+			"get": op("List an account's transaction history", jsonResponse(http.StatusOK, "ListTransactionsResponse"), withErrorResponses(errorResponse), withPathParam("id")),
+		},
+		"/v1/transactions/{id}/events": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Stream a transaction's status changes via Server-Sent Events",
+				"parameters": []interface{}{
+					pathParam("id"),
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "SSE stream of status events",
+						"content": map[string]interface{}{
+							"text/event-stream": map[string]interface{}{
+								"schema": map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"/v1/ws": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":     "Upgrade to a WebSocket for live balance updates on subscribed accounts",
+				"description": "Not a regular HTTP response: the connection upgrades to the WebSocket protocol, which OpenAPI 3.0 has no schema for. Documented here for discoverability only.",
+				"responses": map[string]interface{}{
+					"101": map[string]interface{}{
+						"description": "Switching Protocols",
+					},
+				},
+			},
+		},
+		"/v1/accounts/{id}/transactions.csv": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Export an account's transaction history as CSV",
+				"parameters": []interface{}{
+					pathParam("id"),
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "CSV export",
+						"content": map[string]interface{}{
+							"text/csv": map[string]interface{}{
+								"schema": map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "double-entry-ledger gateway API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+func ref(schemaName string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + schemaName}
+}
+
+func jsonResponse(status int, schemaName string) map[string]interface{} {
+	return jsonResponses(map[int]string{status: schemaName})
+}
+
+func jsonResponses(byStatus map[int]string) map[string]interface{} {
+	responses := map[string]interface{}{}
+	for status, schemaName := range byStatus {
+		responses[httpStatusText(status)] = map[string]interface{}{
+			"description": http.StatusText(status),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": ref(schemaName),
+				},
+			},
+		}
+	}
+	return map[string]interface{}{"responses": responses}
+}
+
+// op assembles a path item's operation object from a summary plus a set of
+// option funcs, each contributing (and possibly overwriting) keys.
+func op(summary string, base map[string]interface{}, opts ...func(map[string]interface{})) map[string]interface{} {
+	result := map[string]interface{}{"summary": summary}
+	for k, v := range base {
+		result[k] = v
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+func withRequestBody(schemaName string) func(map[string]interface{}) {
+	return func(m map[string]interface{}) {
+		m["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": ref(schemaName),
+				},
+			},
+		}
+	}
+}
+
+func withErrorResponses(errorResponse map[string]interface{}) func(map[string]interface{}) {
+	return func(m map[string]interface{}) {
+		responses, _ := m["responses"].(map[string]interface{})
+		if responses == nil {
+			responses = map[string]interface{}{}
+			m["responses"] = responses
+		}
+		responses["default"] = errorResponse
+	}
+}
+
+func withPathParam(name string) func(map[string]interface{}) {
+	return func(m map[string]interface{}) {
+		m["parameters"] = []interface{}{pathParam(name)}
+	}
+}
+
+func pathParam(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     name,
+		"in":       "path",
+		"required": true,
+		"schema":   map[string]interface{}{"type": "string"},
+	}
+}
+
+func httpStatusText(status int) string {
+	switch status {
+	case http.StatusOK:
+		return "200"
+	case http.StatusCreated:
+		return "201"
+	default:
+		return "200"
+	}
+}
+
+// reflectSchema builds an OpenAPI schema object for t by inspecting its
+// exported fields' json and binding tags. It only needs to understand the
+// shapes this package's request/response structs actually use: structs,
+// slices, pointers, and JSON-primitive types.
+func reflectSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return reflectStructSchema(t)
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": reflectSchema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func reflectStructSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+
+		name, omitempty := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+
+		properties[name] = reflectSchema(f.Type)
+
+		if strings.Contains(f.Tag.Get("binding"), "required") && !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func jsonFieldName(f reflect.StructField) (name string, omitempty bool) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
@@ -0,0 +1,29 @@
+package handler
+
+// PageMeta is the gateway's shared pagination metadata envelope, embedded
+// alongside the existing total_count/page/page_size fields on list
+// responses. It carries fields every paginating client ends up computing
+// for itself -- total_pages, has_next, has_prev -- plus the item count of
+// the current page, so the response is self-describing without a client
+// needing to know the page_size it asked for still applies.
+type PageMeta struct {
+	ItemCount  int32 `json:"item_count"`
+	TotalPages int32 `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+	HasPrev    bool  `json:"has_prev"`
+}
+
+// newPageMeta derives a PageMeta from a list response's totalCount, page,
+// and pageSize, plus the number of items actually returned on this page.
+func newPageMeta(totalCount, page, pageSize int32, itemCount int) PageMeta {
+	var totalPages int32
+	if pageSize > 0 {
+		totalPages = (totalCount + pageSize - 1) / pageSize
+	}
+	return PageMeta{
+		ItemCount:  int32(itemCount),
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import "testing"
+
+func TestNewPageMeta(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalCount int32
+		page       int32
+		pageSize   int32
+		itemCount  int
+		want       PageMeta
+	}{
+		{
+			name:       "first page of several",
+			totalCount: 45,
+			page:       1,
+			pageSize:   20,
+			itemCount:  20,
+			want:       PageMeta{ItemCount: 20, TotalPages: 3, HasNext: true, HasPrev: false},
+		},
+		{
+			name:       "last page partially filled",
+			totalCount: 45,
+			page:       3,
+			pageSize:   20,
+			itemCount:  5,
+			want:       PageMeta{ItemCount: 5, TotalPages: 3, HasNext: false, HasPrev: true},
+		},
+		{
+			name:       "only page",
+			totalCount: 3,
+			page:       1,
+			pageSize:   20,
+			itemCount:  3,
+			want:       PageMeta{ItemCount: 3, TotalPages: 1, HasNext: false, HasPrev: false},
+		},
+		{
+			name:       "no results",
+			totalCount: 0,
+			page:       1,
+			pageSize:   20,
+			itemCount:  0,
+			want:       PageMeta{ItemCount: 0, TotalPages: 0, HasNext: false, HasPrev: false},
+		},
+		{
+			name:       "zero page size never divides",
+			totalCount: 10,
+			page:       1,
+			pageSize:   0,
+			itemCount:  0,
+			want:       PageMeta{ItemCount: 0, TotalPages: 0, HasNext: false, HasPrev: false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newPageMeta(tc.totalCount, tc.page, tc.pageSize, tc.itemCount)
+			if got != tc.want {
+				t.Fatalf("newPageMeta(%d, %d, %d, %d) = %+v, want %+v",
+					tc.totalCount, tc.page, tc.pageSize, tc.itemCount, got, tc.want)
+			}
+		})
+	}
+}
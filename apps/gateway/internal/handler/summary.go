@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/money"
+)
+
+// summaryPageSize is how many accounts SummaryHandler requests per
+// ListAccounts call while paging through a user's full account list.
+const summaryPageSize = 100
+
+// SummaryResponse is the gateway's wire format for GET /v1/summary.
+type SummaryResponse struct {
+	ByCurrency   map[string]string `json:"by_currency"`
+	AccountCount int32             `json:"account_count"`
+}
+
+// SummaryHandler implements GET /v1/summary.
+type SummaryHandler struct {
+	Client ledgerclient.LedgerClient
+	Config *config.Config
+}
+
+func NewSummaryHandler(client ledgerclient.LedgerClient, cfg *config.Config) *SummaryHandler {
+	return &SummaryHandler{Client: client, Config: cfg}
+}
+
+// Get handles GET /v1/summary: every account owned by the caller (the same
+// JWT-derived identity List and Create use, via userIDFromContext),
+// grouped into a total balance per currency plus the account count. It
+// pages through ListAccounts internally so callers don't have to.
+func (h *SummaryHandler) Get(c *gin.Context) {
+	accounts, err := h.listAllAccounts(c.Request.Context(), userIDFromContext(c))
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	var totals money.Totals
+	for _, acct := range accounts {
+		// A malformed balance from ledger-core shouldn't corrupt the rest
+		// of the summary; skip it rather than failing the call.
+		_ = totals.Add(acct.Currency, acct.Balance)
+	}
+
+	c.JSON(http.StatusOK, SummaryResponse{
+		ByCurrency:   totals.Format(),
+		AccountCount: int32(len(accounts)),
+	})
+}
+
+// listAllAccounts pages through ListAccounts until every account owned by
+// userID has been collected.
+func (h *SummaryHandler) listAllAccounts(ctx context.Context, userID string) ([]*ledgerpb.AccountResponse, error) {
+	var all []*ledgerpb.AccountResponse
+	for page := int32(1); ; page++ {
+		resp, err := h.Client.ListAccounts(ctx, &ledgerpb.ListAccountsRequest{
+			UserId:   userID,
+			Page:     page,
+			PageSize: summaryPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Accounts...)
+		if len(all) >= int(resp.TotalCount) || len(resp.Accounts) == 0 {
+			return all, nil
+		}
+	}
+}
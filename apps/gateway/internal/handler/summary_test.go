@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+)
+
+func newSummaryTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewSummaryHandler(ledgerclient.NewMockLedgerClient(), &config.Config{})
+	router.GET("/v1/summary", h.Get)
+	return router
+}
+
+func TestSummaryHandlerGet_SumsSeededAccountsByCurrency(t *testing.T) {
+	router := newSummaryTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/summary", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp SummaryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.AccountCount == 0 {
+		t.Fatal("expected at least one seeded account")
+	}
+	if _, ok := resp.ByCurrency["USD"]; !ok {
+		t.Fatalf("expected a USD total from the seeded accounts, got %+v", resp.ByCurrency)
+	}
+}
@@ -0,0 +1,476 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// defaultTransactionEventsPollInterval is used by Events when
+// Config.TransactionEventsPollInterval isn't set (e.g. a handler built
+// directly in a test rather than via config.Load).
+const defaultTransactionEventsPollInterval = 2 * time.Second
+
+// maxExecuteAtHorizon bounds how far in the future a scheduled transaction
+// may be dated, so a typo years out doesn't silently park money
+// indefinitely.
+const maxExecuteAtHorizon = 90 * 24 * time.Hour
+
+// CreateTransactionRequest is the gateway's wire format for creating a
+// transaction. Amount is a string (not a float) to avoid precision loss;
+// validation of its contents happens in TransactionHandler.Create.
+//
+// ExecuteAt, if set, schedules the transfer for a future RFC3339 time
+// instead of executing it immediately; it must be in the future and within
+// maxExecuteAtHorizon.
+type CreateTransactionRequest struct {
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+	FromAccountID  string `json:"from_account_id" binding:"required,uuid"`
+	ToAccountID    string `json:"to_account_id" binding:"required,uuid"`
+	Amount         string `json:"amount" binding:"required"`
+	Currency       string `json:"currency" binding:"required,len=3"`
+	Description    string `json:"description"`
+	ExecuteAt      string `json:"execute_at"`
+}
+
+// TransactionResponse is the gateway's wire format for a transaction.
+type TransactionResponse struct {
+	ID            string `json:"id"`
+	FromAccountID string `json:"from_account_id"`
+	ToAccountID   string `json:"to_account_id"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+	Description   string `json:"description"`
+	// Status is "BOOKED" for an executed transfer, or "SCHEDULED" for one
+	// with a future ExecuteAt that ledger-core hasn't executed yet.
+	Status string `json:"status"`
+	// BookedAt is empty for a SCHEDULED transaction.
+	BookedAt string `json:"booked_at,omitempty"`
+	// ExecuteAt is set when this transaction was scheduled for the future.
+	ExecuteAt string `json:"execute_at,omitempty"`
+	// Replayed is true when this response is the original booking for an
+	// IdempotencyKey seen before, rather than a newly created transaction.
+	Replayed bool `json:"replayed,omitempty"`
+	// FromBalance and ToBalance are the post-transaction balances of
+	// FromAccountID and ToAccountID, included only when the request was
+	// made with ?return_balances=true and ledger-core provided them.
+	FromBalance string `json:"from_balance,omitempty"`
+	ToBalance   string `json:"to_balance,omitempty"`
+}
+
+// TransactionHandler implements the /v1/transactions endpoints.
+type TransactionHandler struct {
+	Client      ledgerclient.LedgerClient
+	Config      *config.Config
+	Idempotency idempotency.Store
+}
+
+func NewTransactionHandler(client ledgerclient.LedgerClient, cfg *config.Config, store idempotency.Store) *TransactionHandler {
+	return &TransactionHandler{Client: client, Config: cfg, Idempotency: store}
+}
+
+// Create handles POST /v1/transactions.
+func (h *TransactionHandler) Create(c *gin.Context) {
+	var req CreateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	if req.FromAccountID == req.ToAccountID {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "from_account_id and to_account_id must not be the same account")
+		return
+	}
+
+	if req.ExecuteAt != "" {
+		executeAt, err := time.Parse(time.RFC3339, req.ExecuteAt)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "execute_at must be an RFC3339 timestamp")
+			return
+		}
+		if !executeAt.After(time.Now()) {
+			writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "execute_at must be in the future")
+			return
+		}
+		if executeAt.After(time.Now().Add(maxExecuteAtHorizon)) {
+			writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("execute_at must be within %s from now", maxExecuteAtHorizon))
+			return
+		}
+	}
+
+	if !h.Config.IsCurrencyAllowed(req.Currency) {
+		writeError(c, http.StatusUnprocessableEntity, "UNSUPPORTED_CURRENCY", "currency "+req.Currency+" is not supported by this deployment")
+		return
+	}
+
+	canonicalAmount, err := parseAmount(req.Amount, req.Currency)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	if maxAmount, ok := h.Config.MaxAmountFor(req.Currency); ok && exceedsMaxAmount(canonicalAmount, maxAmount) {
+		writeError(c, http.StatusUnprocessableEntity, "AMOUNT_LIMIT_EXCEEDED",
+			fmt.Sprintf("amount %s exceeds the maximum transaction amount of %s %s", canonicalAmount, maxAmount, strings.ToUpper(req.Currency)))
+		return
+	}
+
+	bodyHash := hashTransactionBody(req.FromAccountID, req.ToAccountID, canonicalAmount, req.Currency, req.Description)
+
+	if record, ok := h.Idempotency.Get(req.IdempotencyKey); ok {
+		if record.BodyHash != bodyHash {
+			writeError(c, http.StatusConflict, "IDEMPOTENCY_KEY_CONFLICT",
+				"idempotency_key "+req.IdempotencyKey+" was already used with a different request body")
+			return
+		}
+		if cached, ok := decodeCachedTransactionResponse(record.Response); ok {
+			replayTransactionResponse(c, cached)
+			return
+		}
+	}
+
+	returnBalances := c.Query("return_balances") == "true"
+
+	resp, err := h.Client.CreateTransaction(c.Request.Context(), &ledgerpb.CreateTransactionRequest{
+		IdempotencyKey: req.IdempotencyKey,
+		FromAccountId:  req.FromAccountID,
+		ToAccountId:    req.ToAccountID,
+		Amount:         canonicalAmount,
+		Currency:       req.Currency,
+		Description:    req.Description,
+		ExecuteAt:      req.ExecuteAt,
+		ReturnBalances: returnBalances,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	out := toTransactionResponse(resp)
+
+	status := http.StatusCreated
+	if resp.Replayed {
+		status = http.StatusOK
+	} else {
+		c.Header("Location", "/v1/transactions/"+resp.Id)
+	}
+	h.Idempotency.Set(req.IdempotencyKey, idempotency.Record{BodyHash: bodyHash, Response: out, Status: status})
+	c.JSON(status, out)
+}
+
+// replayTransactionResponse writes cached back as the response to a
+// request reusing an idempotency key within its TTL: cached.Replayed is
+// set and the Idempotency-Replayed header is set, so a caller can detect
+// a replay without parsing the body. The status is always 200 -- a
+// replay is a successful read of an existing result, not a second
+// creation -- regardless of the original request's status, which is
+// kept on Record.Status for inspection/debugging rather than reproduced
+// here.
+func replayTransactionResponse(c *gin.Context, cached TransactionResponse) {
+	cached.Replayed = true
+	c.Header("Idempotency-Replayed", "true")
+	c.JSON(http.StatusOK, cached)
+}
+
+// decodeCachedTransactionResponse recovers a TransactionResponse from a
+// Record's Response field. An in-memory idempotency.Store hands back the
+// exact value Set was called with, so the type assertion succeeds
+// directly; a Redis-backed one (idempotency.NewRedisStore) can't preserve
+// a Go type across the round trip and hands it back as json.RawMessage
+// instead, so that case is decoded explicitly.
+func decodeCachedTransactionResponse(response any) (TransactionResponse, bool) {
+	switch v := response.(type) {
+	case TransactionResponse:
+		return v, true
+	case json.RawMessage:
+		var out TransactionResponse
+		if err := json.Unmarshal(v, &out); err != nil {
+			return TransactionResponse{}, false
+		}
+		return out, true
+	default:
+		return TransactionResponse{}, false
+	}
+}
+
+// hashTransactionBody hashes the fields of a transaction request that
+// determine its effect, so a retry with the same idempotency_key but a
+// changed amount or account can be distinguished from a genuine replay.
+func hashTransactionBody(fromAccountID, toAccountID, amount, currency, description string) string {
+	h := sha256.New()
+	for _, field := range []string{fromAccountID, toAccountID, amount, currency, description} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func toTransactionResponse(resp *ledgerpb.TransactionResponse) TransactionResponse {
+	return TransactionResponse{
+		ID:            resp.Id,
+		FromAccountID: resp.FromAccountId,
+		ToAccountID:   resp.ToAccountId,
+		Amount:        resp.Amount,
+		Currency:      resp.Currency,
+		Description:   resp.Description,
+		Status:        resp.Status,
+		BookedAt:      resp.BookedAt,
+		ExecuteAt:     resp.ExecuteAt,
+		Replayed:      resp.Replayed,
+		FromBalance:   resp.FromBalance,
+		ToBalance:     resp.ToBalance,
+	}
+}
+
+// transactionListPageSize is the default page size for List, and the fixed
+// page size ExportCSV requests on each round trip to ledger-core.
+const transactionListPageSize = 200
+
+// AccountTransactionResponse is the gateway's wire format for one entry in
+// an account's transaction history, from that account's point of view.
+type AccountTransactionResponse struct {
+	ID             string `json:"id"`
+	CounterpartyID string `json:"counterparty_id"`
+	Amount         string `json:"amount"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	Description    string `json:"description"`
+	BookedAt       string `json:"booked_at"`
+}
+
+// ListTransactionsResponse is the gateway's wire format for a page of an
+// account's transaction history.
+type ListTransactionsResponse struct {
+	Transactions []AccountTransactionResponse `json:"transactions"`
+	TotalCount   int32                        `json:"total_count"`
+	Page         int32                        `json:"page"`
+	PageSize     int32                        `json:"page_size"`
+	Pagination   PageMeta                     `json:"pagination"`
+}
+
+// List handles GET /v1/accounts/:id/transactions, optionally bounded to the
+// RFC3339 range [start_date, end_date].
+func (h *TransactionHandler) List(c *gin.Context) {
+	accountID := c.Param("id")
+	if !h.verifyOwnership(c, accountID) {
+		return
+	}
+
+	page := parseIntParam(c.Query("page"), 1)
+	pageSize, ok := resolvePageSize(c, h.Config)
+	if !ok {
+		return
+	}
+
+	ctx := ledgerclient.ContextWithTenantID(c.Request.Context(), tenantIDFromContext(c, h.Config.AuthTenantClaim))
+	resp, err := h.Client.ListTransactions(ctx, &ledgerpb.ListTransactionsRequest{
+		AccountId: accountID,
+		Page:      int32(page),
+		PageSize:  int32(pageSize),
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	out := ListTransactionsResponse{
+		TotalCount: resp.TotalCount,
+		Page:       resp.Page,
+		PageSize:   resp.PageSize,
+	}
+	for _, tx := range resp.Transactions {
+		out.Transactions = append(out.Transactions, toAccountTransactionResponse(tx))
+	}
+	out.Pagination = newPageMeta(out.TotalCount, out.Page, out.PageSize, len(out.Transactions))
+	c.JSON(http.StatusOK, out)
+}
+
+// ExportCSV handles GET /v1/accounts/:id/transactions.csv. It streams the
+// account's transaction history as CSV, paging through ledgerclient.
+// ListTransactions rather than buffering the whole history in memory, so
+// accounts with a long history don't blow up gateway memory.
+func (h *TransactionHandler) ExportCSV(c *gin.Context) {
+	accountID := c.Param("id")
+	if !h.verifyOwnership(c, accountID) {
+		return
+	}
+
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="transactions-`+accountID+`.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write([]string{"date", "counterparty", "amount", "currency", "status", "description"}); err != nil {
+		log.Printf("transactions.csv: write header for account %s: %v", accountID, err)
+		return
+	}
+
+	ctx := ledgerclient.ContextWithTenantID(c.Request.Context(), tenantIDFromContext(c, h.Config.AuthTenantClaim))
+	for page := int32(1); ; page++ {
+		resp, err := h.Client.ListTransactions(ctx, &ledgerpb.ListTransactionsRequest{
+			AccountId: accountID,
+			Page:      page,
+			PageSize:  transactionListPageSize,
+			StartDate: startDate,
+			EndDate:   endDate,
+		})
+		if err != nil {
+			// Headers are already flushed, so the client gets a truncated
+			// CSV rather than an error envelope; log so it's not silent.
+			log.Printf("transactions.csv: list page %d for account %s: %v", page, accountID, err)
+			return
+		}
+
+		for _, tx := range resp.Transactions {
+			if err := w.Write([]string{tx.BookedAt, tx.CounterpartyId, tx.Amount, tx.Currency, tx.Status, tx.Description}); err != nil {
+				log.Printf("transactions.csv: write row for account %s: %v", accountID, err)
+				return
+			}
+		}
+		w.Flush()
+		c.Writer.Flush()
+
+		if len(resp.Transactions) < transactionListPageSize {
+			return
+		}
+	}
+}
+
+// isTerminalTransactionStatus reports whether status is a final state a
+// transaction won't move on from. "PENDING" and "SCHEDULED" are the only
+// non-terminal states ledger-core produces today, so this is
+// forward-looking: anything else is treated as terminal, rather than
+// hardcoding an exhaustive list of final states that would need updating
+// every time ledger-core adds one.
+func isTerminalTransactionStatus(status string) bool {
+	return status != "PENDING" && status != "SCHEDULED"
+}
+
+// Events handles GET /v1/transactions/:id/events: a Server-Sent Events
+// stream of this transaction's status until it reaches a terminal state or
+// the client disconnects. Ledger-core has no streaming RPC for this yet, so
+// it polls GetTransaction at Config.TransactionEventsPollInterval and emits
+// an event only when the status changes.
+func (h *TransactionHandler) Events(c *gin.Context) {
+	txID := c.Param("id")
+
+	tx, err := h.Client.GetTransaction(c.Request.Context(), &ledgerpb.GetTransactionRequest{Id: txID})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+	if !h.verifyTransactionOwnership(c, tx) {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	writeTransactionEvent(c, tx)
+	c.Writer.Flush()
+	if isTerminalTransactionStatus(tx.Status) {
+		return
+	}
+
+	interval := h.Config.TransactionEventsPollInterval
+	if interval <= 0 {
+		interval = defaultTransactionEventsPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastStatus := tx.Status
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			tx, err := h.Client.GetTransaction(c.Request.Context(), &ledgerpb.GetTransactionRequest{Id: txID})
+			if err != nil {
+				log.Printf("transactions events: poll transaction %s: %v", txID, err)
+				continue
+			}
+			if tx.Status == lastStatus {
+				continue
+			}
+			lastStatus = tx.Status
+			writeTransactionEvent(c, tx)
+			c.Writer.Flush()
+			if isTerminalTransactionStatus(tx.Status) {
+				return
+			}
+		}
+	}
+}
+
+// writeTransactionEvent renders tx as a "status" SSE event. Callers must
+// flush c.Writer afterward.
+func writeTransactionEvent(c *gin.Context, tx *ledgerpb.TransactionResponse) {
+	c.SSEvent("status", toTransactionResponse(tx))
+}
+
+// verifyTransactionOwnership writes a response and returns false unless the
+// caller owns at least one side (from or to) of tx; otherwise it returns
+// true and writes nothing.
+func (h *TransactionHandler) verifyTransactionOwnership(c *gin.Context, tx *ledgerpb.TransactionResponse) bool {
+	callerID := userIDFromContext(c)
+	for _, accountID := range []string{tx.FromAccountId, tx.ToAccountId} {
+		acct, err := h.Client.GetAccount(c.Request.Context(), &ledgerpb.GetAccountRequest{AccountId: accountID})
+		if err != nil {
+			continue
+		}
+		if acct.UserId == callerID {
+			return true
+		}
+	}
+	writeError(c, http.StatusForbidden, "FORBIDDEN", "transaction is not associated with an account owned by the caller")
+	return false
+}
+
+// verifyOwnership writes a response and returns false if accountID doesn't
+// exist or isn't owned by the caller; otherwise it returns true and writes
+// nothing.
+func (h *TransactionHandler) verifyOwnership(c *gin.Context, accountID string) bool {
+	acct, err := h.Client.GetAccount(c.Request.Context(), &ledgerpb.GetAccountRequest{AccountId: accountID})
+	if err != nil {
+		writeGRPCError(c, err)
+		return false
+	}
+	if acct.UserId != userIDFromContext(c) {
+		writeError(c, http.StatusForbidden, "FORBIDDEN", "account is not owned by the caller")
+		return false
+	}
+	return true
+}
+
+func toAccountTransactionResponse(tx *ledgerpb.AccountTransaction) AccountTransactionResponse {
+	return AccountTransactionResponse{
+		ID:             tx.Id,
+		CounterpartyID: tx.CounterpartyId,
+		Amount:         tx.Amount,
+		Currency:       tx.Currency,
+		Status:         tx.Status,
+		Description:    tx.Description,
+		BookedAt:       tx.BookedAt,
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// CreateInternalTransferRequest is the gateway's wire format for a
+// transfer between two accounts the caller owns.
+type CreateInternalTransferRequest struct {
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+	FromAccountID  string `json:"from_account_id" binding:"required,uuid"`
+	ToAccountID    string `json:"to_account_id" binding:"required,uuid"`
+	Amount         string `json:"amount" binding:"required"`
+	Currency       string `json:"currency" binding:"required,len=3"`
+	Description    string `json:"description"`
+}
+
+// CreateInternalTransfer handles POST /v1/transfers/internal: a transfer
+// between two accounts both owned by the caller. It's a safer,
+// intent-revealing wrapper over the generic /v1/transactions endpoint --
+// same validation and idempotency handling as Create, plus a 403 unless
+// the caller owns both FromAccountID and ToAccountID. Create itself has
+// no ownership opinion, since the generic endpoint is also used for
+// transfers between accounts owned by different users.
+func (h *TransactionHandler) CreateInternalTransfer(c *gin.Context) {
+	var req CreateInternalTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	if req.FromAccountID == req.ToAccountID {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "from_account_id and to_account_id must not be the same account")
+		return
+	}
+
+	if !h.verifyOwnership(c, req.FromAccountID) {
+		return
+	}
+	if !h.verifyOwnership(c, req.ToAccountID) {
+		return
+	}
+
+	if !h.Config.IsCurrencyAllowed(req.Currency) {
+		writeError(c, http.StatusUnprocessableEntity, "UNSUPPORTED_CURRENCY", "currency "+req.Currency+" is not supported by this deployment")
+		return
+	}
+
+	canonicalAmount, err := parseAmount(req.Amount, req.Currency)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	if maxAmount, ok := h.Config.MaxAmountFor(req.Currency); ok && exceedsMaxAmount(canonicalAmount, maxAmount) {
+		writeError(c, http.StatusUnprocessableEntity, "AMOUNT_LIMIT_EXCEEDED",
+			"amount "+canonicalAmount+" exceeds the maximum transaction amount of "+maxAmount+" "+req.Currency)
+		return
+	}
+
+	bodyHash := hashTransactionBody(req.FromAccountID, req.ToAccountID, canonicalAmount, req.Currency, req.Description)
+
+	if record, ok := h.Idempotency.Get(req.IdempotencyKey); ok {
+		if record.BodyHash != bodyHash {
+			writeError(c, http.StatusConflict, "IDEMPOTENCY_KEY_CONFLICT",
+				"idempotency_key "+req.IdempotencyKey+" was already used with a different request body")
+			return
+		}
+		if cached, ok := decodeCachedTransactionResponse(record.Response); ok {
+			replayTransactionResponse(c, cached)
+			return
+		}
+	}
+
+	resp, err := h.Client.CreateTransaction(c.Request.Context(), &ledgerpb.CreateTransactionRequest{
+		IdempotencyKey: req.IdempotencyKey,
+		FromAccountId:  req.FromAccountID,
+		ToAccountId:    req.ToAccountID,
+		Amount:         canonicalAmount,
+		Currency:       req.Currency,
+		Description:    req.Description,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	out := toTransactionResponse(resp)
+
+	status := http.StatusCreated
+	if resp.Replayed {
+		status = http.StatusOK
+	} else {
+		c.Header("Location", "/v1/transactions/"+resp.Id)
+	}
+	h.Idempotency.Set(req.IdempotencyKey, idempotency.Record{BodyHash: bodyHash, Response: out, Status: status})
+	c.JSON(status, out)
+}
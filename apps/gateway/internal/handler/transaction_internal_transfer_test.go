@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+func newInternalTransferTestRouter(client ledgerclient.LedgerClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTransactionHandler(client, &config.Config{}, idempotency.NewMemoryStore(0, 0))
+	router.POST("/v1/transfers/internal", h.CreateInternalTransfer)
+	return router
+}
+
+func TestTransactionHandlerCreateInternalTransfer_OwnedAccountsReturns201(t *testing.T) {
+	router := newInternalTransferTestRouter(ledgerclient.NewMockLedgerClient())
+
+	body, _ := json.Marshal(CreateInternalTransferRequest{
+		IdempotencyKey: "internal-1",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transfers/internal", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	var resp TransactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Status != "BOOKED" {
+		t.Fatalf("status = %q, want %q", resp.Status, "BOOKED")
+	}
+}
+
+func TestTransactionHandlerCreateInternalTransfer_NotOwnedFromAccountReturns403(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "someone-else", Currency: "USD", InitialBalance: "100.00"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newInternalTransferTestRouter(client)
+
+	body, _ := json.Marshal(CreateInternalTransferRequest{
+		IdempotencyKey: "internal-2",
+		FromAccountID:  acct.Id,
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transfers/internal", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateInternalTransfer_NotOwnedToAccountReturns403(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "someone-else", Currency: "USD", InitialBalance: "100.00"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newInternalTransferTestRouter(client)
+
+	body, _ := json.Marshal(CreateInternalTransferRequest{
+		IdempotencyKey: "internal-3",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    acct.Id,
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transfers/internal", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateInternalTransfer_SelfTransferReturns400(t *testing.T) {
+	router := newInternalTransferTestRouter(ledgerclient.NewMockLedgerClient())
+
+	body, _ := json.Marshal(CreateInternalTransferRequest{
+		IdempotencyKey: "internal-4",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000001",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transfers/internal", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
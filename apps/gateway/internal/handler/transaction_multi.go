@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// TransactionLegRequest is one side of a multi-leg transaction request.
+// Direction must be "debit" or "credit".
+type TransactionLegRequest struct {
+	AccountID string `json:"account_id" binding:"required,uuid"`
+	Amount    string `json:"amount" binding:"required"`
+	Direction string `json:"direction" binding:"required,oneof=debit credit"`
+}
+
+// CreateMultiLegTransactionRequest is the gateway's wire format for
+// creating a transaction with more than two sides. Legs must balance to
+// zero in Currency: the sum of debit amounts must equal the sum of credit
+// amounts.
+type CreateMultiLegTransactionRequest struct {
+	IdempotencyKey string                  `json:"idempotency_key" binding:"required"`
+	Currency       string                  `json:"currency" binding:"required,len=3"`
+	Description    string                  `json:"description"`
+	Legs           []TransactionLegRequest `json:"legs" binding:"required,min=2"`
+}
+
+// TransactionLegResponse is one side of a multi-leg transaction response.
+type TransactionLegResponse struct {
+	AccountID string `json:"account_id"`
+	Amount    string `json:"amount"`
+	Direction string `json:"direction"`
+}
+
+// MultiLegTransactionResponse is the gateway's wire format for a booked
+// multi-leg transaction.
+type MultiLegTransactionResponse struct {
+	ID          string                   `json:"id"`
+	Currency    string                   `json:"currency"`
+	Description string                   `json:"description"`
+	Status      string                   `json:"status"`
+	BookedAt    string                   `json:"booked_at,omitempty"`
+	Legs        []TransactionLegResponse `json:"legs"`
+	Replayed    bool                     `json:"replayed,omitempty"`
+}
+
+// CreateMultiLeg handles POST /v1/transactions/multi.
+func (h *TransactionHandler) CreateMultiLeg(c *gin.Context) {
+	var req CreateMultiLegTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+
+	if !h.Config.IsCurrencyAllowed(req.Currency) {
+		writeError(c, http.StatusUnprocessableEntity, "UNSUPPORTED_CURRENCY", "currency "+req.Currency+" is not supported by this deployment")
+		return
+	}
+
+	debits := new(big.Rat)
+	credits := new(big.Rat)
+	legs := make([]*ledgerpb.TransactionLeg, len(req.Legs))
+
+	for i, leg := range req.Legs {
+		canonicalAmount, err := parseAmount(leg.Amount, req.Currency)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+			return
+		}
+
+		if maxAmount, ok := h.Config.MaxAmountFor(req.Currency); ok && exceedsMaxAmount(canonicalAmount, maxAmount) {
+			writeError(c, http.StatusUnprocessableEntity, "AMOUNT_LIMIT_EXCEEDED",
+				"leg amount "+canonicalAmount+" exceeds the maximum transaction amount of "+maxAmount+" "+req.Currency)
+			return
+		}
+
+		amount, ok := new(big.Rat).SetString(canonicalAmount)
+		if !ok {
+			writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT", "amount \""+leg.Amount+"\" is not a valid decimal number")
+			return
+		}
+		switch leg.Direction {
+		case "debit":
+			debits.Add(debits, amount)
+		case "credit":
+			credits.Add(credits, amount)
+		}
+
+		legs[i] = &ledgerpb.TransactionLeg{
+			AccountId: leg.AccountID,
+			Amount:    canonicalAmount,
+			Direction: leg.Direction,
+		}
+	}
+
+	if debits.Cmp(credits) != 0 {
+		writeError(c, http.StatusBadRequest, "INVALID_ARGUMENT",
+			"legs do not balance: debits "+debits.FloatString(2)+" != credits "+credits.FloatString(2))
+		return
+	}
+
+	for i, leg := range req.Legs {
+		if leg.Direction != "debit" {
+			continue
+		}
+		if !h.verifyOwnership(c, legs[i].AccountId) {
+			return
+		}
+	}
+
+	bodyHash := hashMultiLegTransactionBody(req.Currency, req.Description, legs)
+
+	if record, ok := h.Idempotency.Get(req.IdempotencyKey); ok {
+		if record.BodyHash != bodyHash {
+			writeError(c, http.StatusConflict, "IDEMPOTENCY_KEY_CONFLICT",
+				"idempotency_key "+req.IdempotencyKey+" was already used with a different request body")
+			return
+		}
+		if cached, ok := decodeCachedMultiLegTransactionResponse(record.Response); ok {
+			replayMultiLegTransactionResponse(c, cached)
+			return
+		}
+	}
+
+	resp, err := h.Client.CreateMultiLegTransaction(c.Request.Context(), &ledgerpb.CreateMultiLegTransactionRequest{
+		IdempotencyKey: req.IdempotencyKey,
+		Currency:       req.Currency,
+		Description:    req.Description,
+		Legs:           legs,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	out := toMultiLegTransactionResponse(resp)
+
+	status := http.StatusCreated
+	if resp.Replayed {
+		status = http.StatusOK
+	} else {
+		c.Header("Location", "/v1/transactions/"+resp.Id)
+	}
+	h.Idempotency.Set(req.IdempotencyKey, idempotency.Record{BodyHash: bodyHash, Response: out, Status: status})
+	c.JSON(status, out)
+}
+
+// replayMultiLegTransactionResponse writes cached back as the response to
+// a request reusing an idempotency key within its TTL, mirroring
+// replayTransactionResponse for the multi-leg endpoint.
+func replayMultiLegTransactionResponse(c *gin.Context, cached MultiLegTransactionResponse) {
+	cached.Replayed = true
+	c.Header("Idempotency-Replayed", "true")
+	c.JSON(http.StatusOK, cached)
+}
+
+// decodeCachedMultiLegTransactionResponse recovers a
+// MultiLegTransactionResponse from a Record's Response field, mirroring
+// decodeCachedTransactionResponse for the multi-leg endpoint.
+func decodeCachedMultiLegTransactionResponse(response any) (MultiLegTransactionResponse, bool) {
+	switch v := response.(type) {
+	case MultiLegTransactionResponse:
+		return v, true
+	case json.RawMessage:
+		var out MultiLegTransactionResponse
+		if err := json.Unmarshal(v, &out); err != nil {
+			return MultiLegTransactionResponse{}, false
+		}
+		return out, true
+	default:
+		return MultiLegTransactionResponse{}, false
+	}
+}
+
+// hashMultiLegTransactionBody hashes the fields of a multi-leg request
+// that determine its effect, so a retry with the same idempotency_key but
+// a changed leg set can be distinguished from a genuine replay, the same
+// way hashTransactionBody does for the two-party endpoints.
+func hashMultiLegTransactionBody(currency, description string, legs []*ledgerpb.TransactionLeg) string {
+	h := sha256.New()
+	for _, field := range []string{currency, description} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	for _, leg := range legs {
+		for _, field := range []string{leg.AccountId, leg.Amount, leg.Direction} {
+			h.Write([]byte(field))
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func toMultiLegTransactionResponse(resp *ledgerpb.MultiLegTransactionResponse) MultiLegTransactionResponse {
+	out := MultiLegTransactionResponse{
+		ID:          resp.Id,
+		Currency:    resp.Currency,
+		Description: resp.Description,
+		Status:      resp.Status,
+		BookedAt:    resp.BookedAt,
+		Replayed:    resp.Replayed,
+	}
+	for _, leg := range resp.Legs {
+		out.Legs = append(out.Legs, TransactionLegResponse{
+			AccountID: leg.AccountId,
+			Amount:    leg.Amount,
+			Direction: leg.Direction,
+		})
+	}
+	return out
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+func newMultiLegTestRouter(client ledgerclient.LedgerClient) *gin.Engine {
+	return newMultiLegTestRouterWithConfig(client, &config.Config{})
+}
+
+func newMultiLegTestRouterWithConfig(client ledgerclient.LedgerClient, cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTransactionHandler(client, cfg, idempotency.NewMemoryStore(0, 0))
+	router.POST("/v1/transactions/multi", h.CreateMultiLeg)
+	return router
+}
+
+func TestTransactionHandlerCreateMultiLeg_BalancedLegsReturns201(t *testing.T) {
+	router := newMultiLegTestRouter(ledgerclient.NewMockLedgerClient())
+
+	body, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-1",
+		Currency:       "USD",
+		Description:    "split payment",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "30.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.00", Direction: "credit"},
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "20.00", Direction: "credit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if loc := rec.Header().Get("Location"); loc == "" {
+		t.Fatalf("Location header not set")
+	}
+
+	var resp MultiLegTransactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Status != "BOOKED" {
+		t.Fatalf("status = %q, want %q", resp.Status, "BOOKED")
+	}
+	if len(resp.Legs) != 3 {
+		t.Fatalf("legs = %d, want 3", len(resp.Legs))
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_UnbalancedLegsReturns400(t *testing.T) {
+	router := newMultiLegTestRouter(ledgerclient.NewMockLedgerClient())
+
+	body, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-2",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "30.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.00", Direction: "credit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_NotOwnedDebitAccountReturns403(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "someone-else", Currency: "USD", InitialBalance: "100.00"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newMultiLegTestRouter(client)
+
+	body, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-3",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: acct.Id, Amount: "10.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.00", Direction: "credit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_TooFewLegsReturns400(t *testing.T) {
+	router := newMultiLegTestRouter(ledgerclient.NewMockLedgerClient())
+
+	body, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-4",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "10.00", Direction: "debit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_ReplayReturns200(t *testing.T) {
+	router := newMultiLegTestRouter(ledgerclient.NewMockLedgerClient())
+
+	reqBody := CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-5",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "10.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.00", Direction: "credit"},
+		},
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	req2 := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req2.Header.Set("Content-Type", "application/json")
+	rec2 := httptest.NewRecorder()
+	router.ServeHTTP(rec2, req2)
+	if rec2.Code != http.StatusOK {
+		t.Fatalf("replay status = %d, want %d; body=%s", rec2.Code, http.StatusOK, rec2.Body.String())
+	}
+
+	var resp MultiLegTransactionResponse
+	if err := json.Unmarshal(rec2.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if !resp.Replayed {
+		t.Fatalf("replayed = false, want true")
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_AtLimitSucceeds(t *testing.T) {
+	router := newMultiLegTestRouterWithConfig(ledgerclient.NewMockLedgerClient(), &config.Config{
+		MaxTransactionAmount: map[string]string{"USD": "10.00"},
+	})
+
+	body, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-at-limit",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "10.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.00", Direction: "credit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_OverLimitLegReturns422(t *testing.T) {
+	router := newMultiLegTestRouterWithConfig(ledgerclient.NewMockLedgerClient(), &config.Config{
+		MaxTransactionAmount: map[string]string{"USD": "10.00"},
+	})
+
+	body, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-over-limit",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "10.01", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.01", Direction: "credit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreateMultiLeg_IdempotencyKeyReuseWithDifferentLegsReturns409(t *testing.T) {
+	router := newMultiLegTestRouter(ledgerclient.NewMockLedgerClient())
+
+	firstBody, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-conflict",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "10.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "10.00", Direction: "credit"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(firstBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	secondBody, _ := json.Marshal(CreateMultiLegTransactionRequest{
+		IdempotencyKey: "multi-conflict",
+		Currency:       "USD",
+		Legs: []TransactionLegRequest{
+			{AccountID: "00000000-0000-0000-0000-000000000001", Amount: "20.00", Direction: "debit"},
+			{AccountID: "00000000-0000-0000-0000-000000000002", Amount: "20.00", Direction: "credit"},
+		},
+	})
+
+	req2 := httptest.NewRequest(http.MethodPost, "/v1/transactions/multi", bytes.NewReader(secondBody))
+	req2.Header.Set("Content-Type", "application/json")
+	rec2 := httptest.NewRecorder()
+	router.ServeHTTP(rec2, req2)
+
+	if rec2.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d; body=%s", rec2.Code, http.StatusConflict, rec2.Body.String())
+	}
+}
@@ -0,0 +1,650 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+func newTestRouter() *gin.Engine {
+	return newTestRouterWithConfig(&config.Config{})
+}
+
+func newTestRouterWithConfig(cfg *config.Config) *gin.Engine {
+	return newTestRouterWithStore(cfg, idempotency.NewMemoryStore(0, 0))
+}
+
+func newTestRouterWithStore(cfg *config.Config, store idempotency.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTransactionHandler(ledgerclient.NewMockLedgerClient(), cfg, store)
+	router.POST("/v1/transactions", h.Create)
+	router.GET("/v1/accounts/:id/transactions", h.List)
+	return router
+}
+
+func newListTestRouter(client ledgerclient.LedgerClient, cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTransactionHandler(client, cfg, idempotency.NewMemoryStore(0, 0))
+	router.GET("/v1/accounts/:id/transactions", h.List)
+	return router
+}
+
+func TestTransactionHandlerCreate_RejectsSelfTransfer(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-1",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000001",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_NewTransactionReturns201(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-new",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	var resp TransactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Replayed {
+		t.Fatal("expected a freshly booked transaction not to be marked replayed")
+	}
+}
+
+func TestTransactionHandlerCreate_ReturnBalancesPopulatesBothBalances(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-return-balances",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions?return_balances=true", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	var resp TransactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.FromBalance == "" || resp.ToBalance == "" {
+		t.Fatalf("expected from_balance and to_balance to be populated, got %+v", resp)
+	}
+}
+
+func TestTransactionHandlerCreate_WithoutReturnBalancesOmitsBalances(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-no-return-balances",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	if strings.Contains(rec.Body.String(), "from_balance") || strings.Contains(rec.Body.String(), "to_balance") {
+		t.Fatalf("expected from_balance/to_balance to be omitted, got %s", rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_ReplayReturns200(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-replay",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("replay: status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp TransactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if !resp.Replayed {
+		t.Fatal("expected the replayed transaction to be marked replayed")
+	}
+	if got := rec.Header().Get("Idempotency-Replayed"); got != "true" {
+		t.Fatalf("Idempotency-Replayed header = %q, want %q", got, "true")
+	}
+}
+
+func TestTransactionHandlerCreate_FreshRequestOmitsReplayHeader(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-fresh",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if got := rec.Header().Get("Idempotency-Replayed"); got != "" {
+		t.Fatalf("Idempotency-Replayed header = %q, want unset", got)
+	}
+}
+
+func TestTransactionHandlerCreate_ExpiredKeyIsReExecutedInsteadOfReplayed(t *testing.T) {
+	// A gateway-level TTL expiry means the *gateway's* cache no longer
+	// short-circuits the request -- it's forwarded to CreateTransaction
+	// again rather than served from decodeCachedTransactionResponse. The
+	// mock ledger-core still recognizes the idempotency key on its own
+	// (a second, independent dedup layer, same as a real ledger-core
+	// would), so the booking itself isn't duplicated; what this test
+	// verifies is that the gateway's own Idempotency-Replayed header --
+	// which only fires on a *gateway* cache hit -- is absent, proving the
+	// expired record wasn't served from the gateway's cache.
+	router := newTestRouterWithStore(&config.Config{}, idempotency.NewMemoryStore(time.Millisecond, 0))
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-expiring",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	req2 := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req2.Header.Set("Content-Type", "application/json")
+	rec2 := httptest.NewRecorder()
+	router.ServeHTTP(rec2, req2)
+
+	if got := rec2.Header().Get("Idempotency-Replayed"); got != "" {
+		t.Fatalf("Idempotency-Replayed header = %q, want unset after TTL expiry", got)
+	}
+}
+
+func TestTransactionHandlerCreate_KeyReuseWithDifferentBodyReturns409(t *testing.T) {
+	router := newTestRouter()
+
+	first, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-conflict",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(first))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	second, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-conflict",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "25.00",
+		Currency:       "USD",
+	})
+	req = httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(second))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerEvents_StreamsTerminalStatusAndOwnershipChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTransactionHandler(ledgerclient.NewMockLedgerClient(), &config.Config{}, idempotency.NewMemoryStore(0, 0))
+	router.POST("/v1/transactions", h.Create)
+	router.GET("/v1/transactions/:id/events", h.Events)
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "events-key-1",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+	createReq := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want %d; body=%s", createRec.Code, http.StatusCreated, createRec.Body.String())
+	}
+	var created TransactionResponse
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("unmarshal created transaction: %v", err)
+	}
+
+	eventsReq := httptest.NewRequest(http.MethodGet, "/v1/transactions/"+created.ID+"/events", nil)
+	eventsRec := httptest.NewRecorder()
+	router.ServeHTTP(eventsRec, eventsReq)
+
+	if eventsRec.Code != http.StatusOK {
+		t.Fatalf("events: status = %d, want %d; body=%s", eventsRec.Code, http.StatusOK, eventsRec.Body.String())
+	}
+	if ct := eventsRec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	if !bytes.Contains(eventsRec.Body.Bytes(), []byte(`"status":"BOOKED"`)) {
+		t.Fatalf("expected a BOOKED status event, got body=%s", eventsRec.Body.String())
+	}
+}
+
+func TestTransactionHandlerEvents_RejectsNonOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewTransactionHandler(ledgerclient.NewMockLedgerClient(), &config.Config{}, idempotency.NewMemoryStore(0, 0))
+	router.POST("/v1/transactions", h.Create)
+	router.GET("/v1/transactions/:id/events", func(c *gin.Context) {
+		c.Set("user_id", "someone-else")
+		h.Events(c)
+	})
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "events-key-2",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+	createReq := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	var created TransactionResponse
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("unmarshal created transaction: %v", err)
+	}
+
+	eventsReq := httptest.NewRequest(http.MethodGet, "/v1/transactions/"+created.ID+"/events", nil)
+	eventsRec := httptest.NewRecorder()
+	router.ServeHTTP(eventsRec, eventsReq)
+
+	if eventsRec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", eventsRec.Code, http.StatusForbidden, eventsRec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_AtLimitSucceeds(t *testing.T) {
+	router := newTestRouterWithConfig(&config.Config{
+		MaxTransactionAmount: map[string]string{"USD": "10.00"},
+	})
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-at-limit",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_OverLimitReturns422(t *testing.T) {
+	router := newTestRouterWithConfig(&config.Config{
+		MaxTransactionAmount: map[string]string{"USD": "10.00"},
+	})
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-over-limit",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.01",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_NoLimitConfiguredAllowsLargeAmount(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-no-limit",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "90000.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_DefaultLimitAppliesToUnlistedCurrency(t *testing.T) {
+	router := newTestRouterWithConfig(&config.Config{
+		MaxTransactionAmountDefault: "5.00",
+	})
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-default-limit",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "6.00",
+		Currency:       "USD",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_ScheduledTransactionReturnsScheduledStatus(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-scheduled",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+		ExecuteAt:      time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var resp TransactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Status != "SCHEDULED" {
+		t.Fatalf("status field = %q, want SCHEDULED", resp.Status)
+	}
+	if resp.BookedAt != "" {
+		t.Fatalf("expected no booked_at for a scheduled transaction, got %q", resp.BookedAt)
+	}
+	if resp.ExecuteAt == "" {
+		t.Fatal("expected execute_at to be echoed back")
+	}
+}
+
+func TestTransactionHandlerCreate_PastExecuteAtReturns400(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-past",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+		ExecuteAt:      time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerCreate_ExecuteAtBeyondHorizonReturns400(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-too-far",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+		ExecuteAt:      time.Now().Add(365 * 24 * time.Hour).UTC().Format(time.RFC3339),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestDecodeCachedTransactionResponse_ConcreteType(t *testing.T) {
+	want := TransactionResponse{ID: "tx-1", Status: "BOOKED"}
+
+	got, ok := decodeCachedTransactionResponse(want)
+	if !ok {
+		t.Fatal("decodeCachedTransactionResponse: ok = false, want true")
+	}
+	if got != want {
+		t.Fatalf("decodeCachedTransactionResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCachedTransactionResponse_JSONRawMessage(t *testing.T) {
+	want := TransactionResponse{ID: "tx-2", Status: "BOOKED"}
+	raw, _ := json.Marshal(want)
+
+	got, ok := decodeCachedTransactionResponse(json.RawMessage(raw))
+	if !ok {
+		t.Fatal("decodeCachedTransactionResponse: ok = false, want true")
+	}
+	if got != want {
+		t.Fatalf("decodeCachedTransactionResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCachedTransactionResponse_UnknownType(t *testing.T) {
+	if _, ok := decodeCachedTransactionResponse("not a transaction response"); ok {
+		t.Fatal("decodeCachedTransactionResponse: ok = true, want false for an unrecognized type")
+	}
+}
+
+func TestTransactionHandlerCreate_MalformedExecuteAtReturns400(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTransactionRequest{
+		IdempotencyKey: "key-malformed",
+		FromAccountID:  "00000000-0000-0000-0000-000000000001",
+		ToAccountID:    "00000000-0000-0000-0000-000000000002",
+		Amount:         "10.00",
+		Currency:       "USD",
+		ExecuteAt:      "not-a-timestamp",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
+
+func TestTransactionHandlerList_OversizedPageSizeIsClamped(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newListTestRouter(client, &config.Config{MaxPageSize: 50})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/transactions?page_size=500", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("X-Page-Size-Clamped") != "true" {
+		t.Fatalf("X-Page-Size-Clamped header = %q, want %q", rec.Header().Get("X-Page-Size-Clamped"), "true")
+	}
+
+	var out ListTransactionsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if out.PageSize != 50 {
+		t.Fatalf("PageSize = %d, want %d", out.PageSize, 50)
+	}
+}
+
+func TestTransactionHandlerList_WithinCapPageSizeIsNotClamped(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newListTestRouter(client, &config.Config{MaxPageSize: 50})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/transactions?page_size=10", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("X-Page-Size-Clamped") != "" {
+		t.Fatalf("X-Page-Size-Clamped header = %q, want unset", rec.Header().Get("X-Page-Size-Clamped"))
+	}
+}
+
+func TestTransactionHandlerList_ZeroPageSizeReturns400(t *testing.T) {
+	client := ledgerclient.NewMockLedgerClient()
+	acct, err := client.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{UserId: "demo-user", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	router := newListTestRouter(client, &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/"+acct.Id+"/transactions?page_size=0", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+const (
+	// maxWSAccountsPerConnection bounds how many accounts a single
+	// connection may subscribe to, so one client can't force unbounded
+	// polling fan-out per connection.
+	maxWSAccountsPerConnection = 20
+	// maxWSConnectionsPerUser bounds how many concurrent connections one
+	// user may hold open, so a misbehaving client can't exhaust server
+	// resources by opening connections in a loop.
+	maxWSConnectionsPerUser = 5
+
+	// defaultWSBalancePollInterval is used when Config.WSBalancePollInterval
+	// isn't set.
+	defaultWSBalancePollInterval = 5 * time.Second
+	wsPingInterval               = 30 * time.Second
+	wsPongWait                   = 60 * time.Second
+	wsWriteWait                  = 10 * time.Second
+)
+
+// wsUpgrader's CheckOrigin is left at the zero-value default (same-origin
+// only) deliberately: this gateway has no cross-origin WebSocket use case
+// yet.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsSubscribeMessage is the client->server message that selects which
+// accounts to receive balance updates for. Sending it again replaces the
+// previous subscription rather than adding to it.
+type wsSubscribeMessage struct {
+	Type       string   `json:"type"`
+	AccountIDs []string `json:"account_ids"`
+}
+
+// wsBalanceMessage is the server->client message pushed whenever a
+// subscribed account's balance changes.
+type wsBalanceMessage struct {
+	Type      string `json:"type"`
+	AccountID string `json:"account_id"`
+	Currency  string `json:"currency"`
+	Balance   string `json:"balance"`
+}
+
+// wsErrorMessage is the server->client message sent when a subscribe
+// request is rejected, in whole or in part.
+type wsErrorMessage struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsConnLimiter caps how many concurrent connections a single user may
+// hold, so one client can't exhaust server resources by opening
+// connections in a loop.
+type wsConnLimiter struct {
+	mu     sync.Mutex
+	byUser map[string]int
+	max    int
+}
+
+func newWSConnLimiter(max int) *wsConnLimiter {
+	return &wsConnLimiter{byUser: make(map[string]int), max: max}
+}
+
+func (l *wsConnLimiter) acquire(userID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.byUser[userID] >= l.max {
+		return false
+	}
+	l.byUser[userID]++
+	return true
+}
+
+func (l *wsConnLimiter) release(userID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.byUser[userID]--
+	if l.byUser[userID] <= 0 {
+		delete(l.byUser, userID)
+	}
+}
+
+// WSHandler implements GET /v1/ws: a WebSocket endpoint that pushes
+// balance updates for accounts the caller subscribes to after connecting.
+// Ledger-core has no streaming RPC for this yet, so updates are driven by
+// polling GetBalance; a core stream would be a drop-in replacement for
+// wsSession.pushBalanceUpdates.
+type WSHandler struct {
+	Client ledgerclient.LedgerClient
+	Config *config.Config
+
+	limiter *wsConnLimiter
+}
+
+func NewWSHandler(client ledgerclient.LedgerClient, cfg *config.Config) *WSHandler {
+	return &WSHandler{Client: client, Config: cfg, limiter: newWSConnLimiter(maxWSConnectionsPerUser)}
+}
+
+// Serve handles GET /v1/ws.
+func (h *WSHandler) Serve(c *gin.Context) {
+	userID := userIDFromContext(c)
+	if !h.limiter.acquire(userID) {
+		writeError(c, http.StatusTooManyRequests, "TOO_MANY_CONNECTIONS", "too many open WebSocket connections for this user")
+		return
+	}
+	defer h.limiter.release(userID)
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("ws: upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	session := &wsSession{
+		conn:          conn,
+		client:        h.Client,
+		userID:        userID,
+		pollInterval:  h.pollInterval(),
+		subscriptions: make(map[string]struct{}),
+		lastBalance:   make(map[string]string),
+	}
+	session.run(c.Request.Context())
+}
+
+func (h *WSHandler) pollInterval() time.Duration {
+	if h.Config != nil && h.Config.WSBalancePollInterval > 0 {
+		return h.Config.WSBalancePollInterval
+	}
+	return defaultWSBalancePollInterval
+}
+
+// wsSession owns one upgraded connection: a read loop watching for
+// subscribe messages, and a run loop that polls subscribed accounts'
+// balances and sends ping keepalives. writeMu serializes writes to conn
+// (gorilla/websocket permits at most one concurrent writer); mu guards
+// subscription state, kept separate so a write never has to happen while
+// mu is held.
+type wsSession struct {
+	conn         *websocket.Conn
+	client       ledgerclient.LedgerClient
+	userID       string
+	pollInterval time.Duration
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	subscriptions map[string]struct{}
+	lastBalance   map[string]string
+}
+
+func (s *wsSession) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		return nil
+	})
+
+	go s.readLoop(cancel)
+
+	balanceTicker := time.NewTicker(s.pollInterval)
+	defer balanceTicker.Stop()
+	pingTicker := time.NewTicker(wsPingInterval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-balanceTicker.C:
+			s.pushBalanceUpdates(ctx)
+		case <-pingTicker.C:
+			if err := s.writeControl(websocket.PingMessage); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop is the connection's sole reader (gorilla/websocket permits only
+// one). It exits, and cancels the session, as soon as ReadMessage errs --
+// client disconnect, protocol error, or the connection being closed by the
+// run loop's own teardown.
+func (s *wsSession) readLoop(cancel context.CancelFunc) {
+	defer cancel()
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg wsSubscribeMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.writeJSON(wsErrorMessage{Type: "error", Code: "INVALID_ARGUMENT", Message: "malformed message"})
+			continue
+		}
+		if msg.Type != "subscribe" {
+			s.writeJSON(wsErrorMessage{Type: "error", Code: "INVALID_ARGUMENT", Message: "unknown message type " + msg.Type})
+			continue
+		}
+		s.handleSubscribe(msg.AccountIDs)
+	}
+}
+
+// handleSubscribe replaces the session's subscription set with accountIDs
+// the caller owns, rejecting the rest individually rather than failing the
+// whole request.
+func (s *wsSession) handleSubscribe(accountIDs []string) {
+	if len(accountIDs) > maxWSAccountsPerConnection {
+		s.writeJSON(wsErrorMessage{
+			Type:    "error",
+			Code:    "TOO_MANY_ACCOUNTS",
+			Message: fmt.Sprintf("at most %d accounts may be subscribed per connection", maxWSAccountsPerConnection),
+		})
+		return
+	}
+
+	owned := make(map[string]struct{}, len(accountIDs))
+	for _, accountID := range accountIDs {
+		acct, err := s.client.GetAccount(context.Background(), &ledgerpb.GetAccountRequest{AccountId: accountID})
+		if err != nil || acct.UserId != s.userID {
+			s.writeJSON(wsErrorMessage{Type: "error", Code: "FORBIDDEN", Message: "account " + accountID + " is not owned by the caller"})
+			continue
+		}
+		owned[accountID] = struct{}{}
+	}
+
+	s.mu.Lock()
+	s.subscriptions = owned
+	s.mu.Unlock()
+}
+
+// pushBalanceUpdates sends a wsBalanceMessage for each subscribed account
+// whose balance has changed since the last poll.
+func (s *wsSession) pushBalanceUpdates(ctx context.Context) {
+	s.mu.Lock()
+	accountIDs := make([]string, 0, len(s.subscriptions))
+	for accountID := range s.subscriptions {
+		accountIDs = append(accountIDs, accountID)
+	}
+	s.mu.Unlock()
+
+	for _, accountID := range accountIDs {
+		resp, err := s.client.GetBalance(ctx, &ledgerpb.GetBalanceRequest{AccountId: accountID})
+		if err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		changed := s.lastBalance[accountID] != resp.Balance
+		s.lastBalance[accountID] = resp.Balance
+		s.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		s.writeJSON(wsBalanceMessage{Type: "balance", AccountID: resp.AccountId, Currency: resp.Currency, Balance: resp.Balance})
+	}
+}
+
+func (s *wsSession) writeJSON(v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return s.conn.WriteJSON(v)
+}
+
+func (s *wsSession) writeControl(messageType int) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteControl(messageType, nil, time.Now().Add(wsWriteWait))
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+)
+
+func newWSTestServer(cfg *config.Config) (*httptest.Server, *WSHandler) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewWSHandler(ledgerclient.NewMockLedgerClient(), cfg)
+	router.GET("/v1/ws", h.Serve)
+	return httptest.NewServer(router), h
+}
+
+func dialWS(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return conn
+}
+
+func TestWSHandlerServe_PushesBalanceForOwnedAccount(t *testing.T) {
+	server, _ := newWSTestServer(&config.Config{WSBalancePollInterval: 10 * time.Millisecond})
+	defer server.Close()
+
+	conn := dialWS(t, server)
+	defer conn.Close()
+
+	if err := conn.WriteJSON(wsSubscribeMessage{Type: "subscribe", AccountIDs: []string{"00000000-0000-0000-0000-000000000001"}}); err != nil {
+		t.Fatalf("write subscribe: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg wsBalanceMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read balance message: %v", err)
+	}
+	if msg.Type != "balance" || msg.AccountID != "00000000-0000-0000-0000-000000000001" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestWSHandlerServe_RejectsSubscribeToUnownedAccount(t *testing.T) {
+	server, _ := newWSTestServer(&config.Config{WSBalancePollInterval: 10 * time.Millisecond})
+	defer server.Close()
+
+	conn := dialWS(t, server)
+	defer conn.Close()
+
+	if err := conn.WriteJSON(wsSubscribeMessage{Type: "subscribe", AccountIDs: []string{"not-a-real-account"}}); err != nil {
+		t.Fatalf("write subscribe: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg wsErrorMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read error message: %v", err)
+	}
+	if msg.Type != "error" || msg.Code != "FORBIDDEN" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestWSHandlerServe_EnforcesPerUserConnectionLimit(t *testing.T) {
+	server, _ := newWSTestServer(&config.Config{WSBalancePollInterval: time.Second})
+	defer server.Close()
+
+	var conns []*websocket.Conn
+	for i := 0; i < maxWSConnectionsPerUser; i++ {
+		conns = append(conns, dialWS(t, server))
+	}
+	defer func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/ws"
+	resp, err := http.Get(strings.Replace(wsURL, "ws://", "http://", 1))
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+}
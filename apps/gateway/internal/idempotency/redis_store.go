@@ -0,0 +1,221 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lockScript is evaluated atomically so checking for an existing record
+// and, failing that, acquiring the lock can't race with another caller's
+// Get doing the same thing between two separate round trips. It returns
+// {1, <record JSON>} if a record already exists, {0, 1} if this caller
+// just acquired the lock (meaning it should go book the transaction and
+// call Set), or {0, 0} if someone else holds the lock already.
+const lockScript = `
+local record = redis.call("GET", KEYS[1])
+if record then
+	return {1, record}
+end
+local acquired = redis.call("SET", KEYS[2], ARGV[1], "NX", "PX", ARGV[2])
+if acquired then
+	return {0, 1}
+end
+return {0, 0}
+`
+
+// RedisStoreConfig controls the lock a redisStore takes while the first
+// request for a key is in flight.
+type RedisStoreConfig struct {
+	// LockTTL bounds how long the lock is held before it expires on its
+	// own, in case the holder crashes or hangs before calling Set. It
+	// should comfortably exceed how long a real request takes -- a lock
+	// that expires early lets two requests book the same key
+	// concurrently, which is exactly what it exists to prevent.
+	LockTTL time.Duration
+	// WaitTimeout bounds how long Get blocks a concurrent duplicate that
+	// found the lock held, waiting for the holder's Set to land. Once it
+	// elapses, Get gives up and reports a miss rather than waiting
+	// indefinitely for a holder that may never call Set.
+	WaitTimeout time.Duration
+	// PollInterval is how often Get re-checks for the record while
+	// waiting on WaitTimeout.
+	PollInterval time.Duration
+	// RecordTTL bounds how long a key's record is honored before Redis
+	// expires it on its own, at which point a repeat request with that
+	// key is treated as new rather than replayed -- see Record.CreatedAt.
+	// Zero means the record never expires, matching this store's
+	// original behavior.
+	RecordTTL time.Duration
+}
+
+// DefaultRedisStoreConfig returns a LockTTL that comfortably outlives a
+// ledger-core round trip and a WaitTimeout well under it, so a waiting
+// duplicate gives up and forwards to ledger-core itself (which has its
+// own idempotency dedup) rather than holding the client past its own
+// timeout. RecordTTL defaults to a day, long enough to cover a client's
+// retry window without holding every key forever.
+func DefaultRedisStoreConfig() RedisStoreConfig {
+	return RedisStoreConfig{
+		LockTTL:      10 * time.Second,
+		WaitTimeout:  3 * time.Second,
+		PollInterval: 50 * time.Millisecond,
+		RecordTTL:    24 * time.Hour,
+	}
+}
+
+// redisStore is a Store shared across gateway instances. Where memoryStore
+// can only ever see a duplicate request on the same process, redisStore's
+// Get takes a short-lived lock (SET NX PX) on a miss, so a concurrent
+// duplicate landing on a *different* instance sees the lock and waits
+// briefly for the first request's Set instead of also forwarding to
+// ledger-core -- the thundering-herd case a single-instance store can't
+// prevent. A lock-acquisition or wait failure (Redis unreachable, wait
+// timed out) degrades to reporting a miss, same as a key never seen
+// before, rather than failing the request.
+type redisStore struct {
+	redis *redis.Client
+	cfg   RedisStoreConfig
+}
+
+// NewRedisStore returns a Store backed by the Redis instance at addr.
+func NewRedisStore(addr string, cfg RedisStoreConfig) Store {
+	return &redisStore{
+		redis: redis.NewClient(&redis.Options{Addr: addr}),
+		cfg:   cfg,
+	}
+}
+
+// storedRecord is Record's wire format. Response is kept as raw JSON
+// rather than decoded into a concrete type, since the store has no way to
+// know what type was originally stored.
+type storedRecord struct {
+	BodyHash  string          `json:"body_hash"`
+	Response  json.RawMessage `json:"response"`
+	Status    int             `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (s *redisStore) Get(key string) (Record, bool) {
+	ctx := context.Background()
+
+	found, record, acquired, err := s.checkOrLock(ctx, key)
+	if err != nil {
+		log.Printf("idempotency: redis lock check for key %s: %v", key, err)
+		return Record{}, false
+	}
+	if found {
+		return record, true
+	}
+	if acquired {
+		// No one else is in flight for this key: report a miss so the
+		// caller proceeds to book the transaction and call Set.
+		return Record{}, false
+	}
+	return s.waitForRecord(ctx, key)
+}
+
+// checkOrLock evaluates lockScript. found reports whether a record already
+// existed (in which case record is populated); otherwise acquired reports
+// whether this call took the lock.
+func (s *redisStore) checkOrLock(ctx context.Context, key string) (found bool, record Record, acquired bool, err error) {
+	res, err := s.redis.Eval(ctx, lockScript, []string{recordKey(key), lockKey(key)}, "1", s.cfg.LockTTL.Milliseconds()).Result()
+	if err != nil {
+		return false, Record{}, false, err
+	}
+	reply, ok := res.([]interface{})
+	if !ok || len(reply) != 2 {
+		return false, Record{}, false, fmt.Errorf("idempotency: unexpected lock script reply %#v", res)
+	}
+	foundFlag, _ := reply[0].(int64)
+	if foundFlag == 1 {
+		raw, ok := reply[1].(string)
+		if !ok {
+			return false, Record{}, false, fmt.Errorf("idempotency: unexpected record reply %#v", reply[1])
+		}
+		record, err := decodeRecord([]byte(raw))
+		if err != nil {
+			return false, Record{}, false, err
+		}
+		return true, record, false, nil
+	}
+	acquiredFlag, _ := reply[1].(int64)
+	return false, Record{}, acquiredFlag == 1, nil
+}
+
+// waitForRecord polls for key's record until it appears or cfg.WaitTimeout
+// elapses, at which point it gives up and reports a miss.
+func (s *redisStore) waitForRecord(ctx context.Context, key string) (Record, bool) {
+	deadline := time.Now().Add(s.cfg.WaitTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(s.cfg.PollInterval)
+
+		raw, err := s.redis.Get(ctx, recordKey(key)).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			log.Printf("idempotency: redis get for key %s while waiting: %v", key, err)
+			return Record{}, false
+		}
+		record, err := decodeRecord(raw)
+		if err != nil {
+			log.Printf("idempotency: decode record for key %s while waiting: %v", key, err)
+			return Record{}, false
+		}
+		return record, true
+	}
+	return Record{}, false
+}
+
+func (s *redisStore) Set(key string, record Record) {
+	ctx := context.Background()
+
+	responseJSON, err := json.Marshal(record.Response)
+	if err != nil {
+		log.Printf("idempotency: marshal response for key %s: %v", key, err)
+		return
+	}
+	encoded, err := json.Marshal(storedRecord{
+		BodyHash:  record.BodyHash,
+		Response:  responseJSON,
+		Status:    record.Status,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("idempotency: marshal record for key %s: %v", key, err)
+		return
+	}
+
+	if err := s.redis.Set(ctx, recordKey(key), encoded, s.cfg.RecordTTL).Err(); err != nil {
+		log.Printf("idempotency: redis set for key %s: %v", key, err)
+		return
+	}
+	// Best-effort: the lock would otherwise just expire on its own
+	// (LockTTL), but deleting it promptly lets any duplicate still
+	// polling in waitForRecord pick up the record on its next check
+	// instead of waiting out the rest of its WaitTimeout.
+	if err := s.redis.Del(ctx, lockKey(key)).Err(); err != nil {
+		log.Printf("idempotency: redis unlock for key %s: %v", key, err)
+	}
+}
+
+func decodeRecord(data []byte) (Record, error) {
+	var stored storedRecord
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Record{}, err
+	}
+	return Record{BodyHash: stored.BodyHash, Response: stored.Response, Status: stored.Status, CreatedAt: stored.CreatedAt}, nil
+}
+
+func recordKey(key string) string {
+	return fmt.Sprintf("idempotency:record:%s", key)
+}
+
+func lockKey(key string) string {
+	return fmt.Sprintf("idempotency:lock:%s", key)
+}
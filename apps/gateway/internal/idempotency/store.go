@@ -0,0 +1,137 @@
+// Package idempotency holds the gateway's record of which idempotency keys
+// it has already seen, so a client retrying a request with the same key
+// gets back the original response instead of hitting ledger-core again,
+// and a client reusing a key with a *different* body gets caught early
+// rather than silently replayed. The interface is storage-agnostic: the
+// in-memory implementation here is a single-instance stand-in for
+// NewRedisStore, the shared implementation multi-instance gateways should
+// use.
+package idempotency
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Record is what's stored for a single idempotency key.
+type Record struct {
+	// BodyHash identifies the request body the key was first used with, so
+	// a later request with the same key but a different hash can be
+	// rejected instead of replayed.
+	BodyHash string
+	// Response is the response that was returned for the original
+	// request. memoryStore hands it back as the exact value Set was
+	// called with, so callers can assert it straight back to their
+	// concrete type. redisStore can't preserve a Go type across a JSON
+	// round trip, so it hands it back as json.RawMessage instead --
+	// callers using a Store that might be Redis-backed need to handle
+	// both (see handler.decodeCachedTransactionResponse).
+	Response any
+	// Status is the HTTP status the original request returned, so a
+	// replay can reproduce it exactly (e.g. 201, or 200 if the original
+	// request was itself a replay) instead of a caller having to assume
+	// one.
+	Status int
+	// CreatedAt is when Set was called for this record. A Store stamps it
+	// itself rather than trusting a caller-supplied value, so TTL
+	// enforcement can't be skewed by clock drift between the handler and
+	// the store.
+	CreatedAt time.Time
+}
+
+// Store records the first response seen for each idempotency key.
+type Store interface {
+	// Get returns the record for key, if any. A record past its store's
+	// TTL (see NewMemoryStore, RedisStoreConfig.RecordTTL) is reported as
+	// a miss, the same as a key never seen before, so the caller treats
+	// the request as new rather than replaying stale data.
+	Get(key string) (Record, bool)
+	// Set records record as the result of key's first use. Callers should
+	// only call this once per key (on the request that actually booked
+	// something), not on replays.
+	Set(key string, record Record)
+}
+
+// memoryStore is a process-local Store, for single-instance deployments
+// that have no Redis to share state across processes -- see NewMemoryStore.
+// It provides no cross-instance guarantees: a duplicate request landing on
+// a different gateway instance looks like a brand new request, not a
+// replay, since each instance only knows about keys it has personally
+// seen.
+//
+// Records are evicted on two independent conditions: ttl (a record older
+// than this is treated as a miss) and maxRecords (the least recently used
+// record is dropped once the store holds more than this many), the same
+// combination the audit service's idempotency.Cache uses. Expired records
+// are dropped lazily, on the next Get that finds them, rather than swept
+// proactively.
+type memoryStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type memoryRecord struct {
+	key    string
+	record Record
+}
+
+// NewMemoryStore returns an empty in-memory Store. A record expires ttl
+// after it was Set, at which point Get reports it as a miss; ttl <= 0
+// disables expiry, so a key is honored forever, matching this store's
+// original behavior. maxRecords caps how many records the store holds at
+// once, evicting the least recently used once the cap is exceeded;
+// maxRecords <= 0 disables the cap, leaving the store unbounded.
+func NewMemoryStore(ttl time.Duration, maxRecords int) Store {
+	return &memoryStore{
+		ttl:      ttl,
+		capacity: maxRecords,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (s *memoryStore) Get(key string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return Record{}, false
+	}
+	record := elem.Value.(*memoryRecord).record
+	if s.ttl > 0 && time.Since(record.CreatedAt) > s.ttl {
+		s.order.Remove(elem)
+		delete(s.items, key)
+		return Record{}, false
+	}
+	s.order.MoveToFront(elem)
+	return record, true
+}
+
+func (s *memoryStore) Set(key string, record Record) {
+	record.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		elem.Value.(*memoryRecord).record = record
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	elem := s.order.PushFront(&memoryRecord{key: key, record: record})
+	s.items[key] = elem
+
+	if s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		if oldest != nil {
+			s.order.Remove(oldest)
+			delete(s.items, oldest.Value.(*memoryRecord).key)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_WithinTTLReplaysRecord(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	store.Set("key-1", Record{BodyHash: "hash-1", Response: "booked", Status: 201})
+
+	record, ok := store.Get("key-1")
+	if !ok {
+		t.Fatalf("Get = miss, want hit")
+	}
+	if record.BodyHash != "hash-1" || record.Status != 201 {
+		t.Fatalf("record = %+v, want BodyHash=hash-1 Status=201", record)
+	}
+}
+
+func TestMemoryStore_ExpiredKeyIsTreatedAsNew(t *testing.T) {
+	store := NewMemoryStore(time.Millisecond, 0)
+	store.Set("key-1", Record{BodyHash: "hash-1", Response: "booked", Status: 201})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := store.Get("key-1"); ok {
+		t.Fatalf("Get = hit, want miss after TTL elapsed")
+	}
+}
+
+func TestMemoryStore_ZeroTTLNeverExpires(t *testing.T) {
+	store := NewMemoryStore(0, 0)
+	store.Set("key-1", Record{BodyHash: "hash-1", Response: "booked", Status: 201})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := store.Get("key-1"); !ok {
+		t.Fatalf("Get = miss, want hit with TTL disabled")
+	}
+}
+
+func TestMemoryStore_SetStampsCreatedAt(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	before := time.Now()
+	store.Set("key-1", Record{BodyHash: "hash-1"})
+
+	record, ok := store.Get("key-1")
+	if !ok {
+		t.Fatalf("Get = miss, want hit")
+	}
+	if record.CreatedAt.Before(before) {
+		t.Fatalf("CreatedAt = %v, want at or after %v", record.CreatedAt, before)
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsedOnceOverCapacity(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 2)
+	store.Set("key-1", Record{BodyHash: "hash-1"})
+	store.Set("key-2", Record{BodyHash: "hash-2"})
+	store.Set("key-3", Record{BodyHash: "hash-3"})
+
+	if _, ok := store.Get("key-1"); ok {
+		t.Fatalf("Get(key-1) = hit, want miss: it should have been evicted once the store exceeded its capacity of 2")
+	}
+	if _, ok := store.Get("key-2"); !ok {
+		t.Fatalf("Get(key-2) = miss, want hit")
+	}
+	if _, ok := store.Get("key-3"); !ok {
+		t.Fatalf("Get(key-3) = miss, want hit")
+	}
+}
+
+func TestMemoryStore_GetRefreshesRecency(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 2)
+	store.Set("key-1", Record{BodyHash: "hash-1"})
+	store.Set("key-2", Record{BodyHash: "hash-2"})
+
+	// Touching key-1 makes key-2 the least recently used, so it's the one
+	// evicted when key-3 pushes the store over capacity.
+	store.Get("key-1")
+	store.Set("key-3", Record{BodyHash: "hash-3"})
+
+	if _, ok := store.Get("key-1"); !ok {
+		t.Fatalf("Get(key-1) = miss, want hit: it was just touched, so it shouldn't have been evicted")
+	}
+	if _, ok := store.Get("key-2"); ok {
+		t.Fatalf("Get(key-2) = hit, want miss: it should have been evicted as the least recently used")
+	}
+}
+
+func TestMemoryStore_ZeroMaxRecordsIsUnbounded(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	for i := 0; i < 1000; i++ {
+		store.Set(string(rune(i)), Record{BodyHash: "hash"})
+	}
+
+	if _, ok := store.Get(string(rune(0))); !ok {
+		t.Fatalf("Get(0) = miss, want hit: a zero cap should never evict")
+	}
+}
@@ -0,0 +1,73 @@
+package introspection
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// breaker is the same open/half-open/closed state machine as
+// ledgerclient's circuitBreakerLedgerClient, scoped to a single
+// operation (Introspect) instead of a whole interface of RPCs, and
+// tripped by any error rather than only a specific status code -- an
+// introspection endpoint doesn't give this client a way to distinguish
+// "unreachable" from "rejected the request" the way gRPC status codes
+// do, so any failure counts.
+type breaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a call may proceed. A zero failureThreshold
+// disables the breaker entirely, so every call is allowed regardless of
+// recent history.
+func (b *breaker) allow() bool {
+	if b.failureThreshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) >= b.openDuration {
+			b.state = stateHalfOpen
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *breaker) onResult(err error) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.state = stateClosed
+		return
+	}
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.failureThreshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,186 @@
+// Package introspection implements an RFC 7662 OAuth2 token introspection
+// client: posting an opaque access token to an authorization server and
+// caching whether it's active, and who it belongs to, for a short TTL.
+// It backs middleware.Auth's AuthModeOpaque, for callers that present
+// tokens the gateway can't validate locally.
+package introspection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result is what an introspection call resolves a token to.
+type Result struct {
+	Active bool
+	// Subject and Scope are only meaningful when Active is true.
+	Subject string
+	Scope   string
+}
+
+// Config controls how Client calls the introspection endpoint, caches
+// results, and trips its circuit breaker.
+type Config struct {
+	// URL is the introspection endpoint (RFC 7662 /introspect).
+	URL string
+	// ClientID and ClientSecret authenticate the gateway itself to the
+	// introspection endpoint, sent as HTTP Basic auth per RFC 7662 section
+	// 2.1.
+	ClientID     string
+	ClientSecret string
+	// Timeout bounds each introspection HTTP call. Zero falls back to
+	// DefaultTimeout.
+	Timeout time.Duration
+	// CacheTTL bounds how long a result is reused for the same token
+	// before introspecting it again. Zero disables caching, so every
+	// request pays the round trip -- acceptable for low traffic, but a
+	// busy deployment should set this to keep the IdP's introspection
+	// endpoint from becoming the bottleneck.
+	CacheTTL time.Duration
+	// CircuitBreakerFailureThreshold and CircuitBreakerOpenDuration
+	// configure the breaker that protects the gateway from a slow or
+	// unreachable introspection endpoint; see the same-named fields on
+	// ledgerclient.CircuitBreakerConfig, which this mirrors. Zero
+	// FailureThreshold disables the breaker, so every call goes to the
+	// endpoint regardless of its recent history.
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerOpenDuration     time.Duration
+}
+
+// DefaultTimeout is applied when Config leaves Timeout at its zero value.
+const DefaultTimeout = 2 * time.Second
+
+// ErrCircuitOpen is returned by Introspect when the breaker is open,
+// instead of attempting the call.
+var ErrCircuitOpen = fmt.Errorf("introspection: circuit breaker open")
+
+// Client calls an introspection endpoint, caching results and tripping a
+// circuit breaker on repeated failures, the same shape of protection
+// ledgerclient gives the gRPC calls to ledger-core.
+type Client struct {
+	httpClient *http.Client
+	cfg        Config
+
+	mu      sync.Mutex
+	cache   map[string]cacheEntry
+	breaker breaker
+}
+
+type cacheEntry struct {
+	result    Result
+	expiresAt time.Time
+}
+
+// NewClient builds a Client from cfg.
+func NewClient(cfg Config) *Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: timeout},
+		cfg:        cfg,
+		cache:      make(map[string]cacheEntry),
+		breaker:    breaker{failureThreshold: cfg.CircuitBreakerFailureThreshold, openDuration: cfg.CircuitBreakerOpenDuration},
+	}
+}
+
+// Introspect resolves token via the configured endpoint, or a cached
+// result from a previous call within Config.CacheTTL. It returns
+// ErrCircuitOpen without attempting the call if the breaker is open.
+func (c *Client) Introspect(ctx context.Context, token string) (Result, error) {
+	key := cacheKey(token)
+
+	if cached, ok := c.cachedResult(key); ok {
+		return cached, nil
+	}
+
+	if !c.breaker.allow() {
+		return Result{}, ErrCircuitOpen
+	}
+
+	result, err := c.callIntrospectionEndpoint(ctx, token)
+	c.breaker.onResult(err)
+	if err != nil {
+		return Result{}, err
+	}
+
+	c.storeResult(key, result)
+	return result, nil
+}
+
+func (c *Client) cachedResult(key string) (Result, bool) {
+	if c.cfg.CacheTTL <= 0 {
+		return Result{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return Result{}, false
+	}
+	return entry.result, true
+}
+
+func (c *Client) storeResult(key string, result Result) {
+	if c.cfg.CacheTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[key] = cacheEntry{result: result, expiresAt: time.Now().Add(c.cfg.CacheTTL)}
+}
+
+// introspectionResponse is the subset of RFC 7662's response body this
+// client cares about.
+type introspectionResponse struct {
+	Active bool   `json:"active"`
+	Sub    string `json:"sub"`
+	Scope  string `json:"scope"`
+}
+
+func (c *Client) callIntrospectionEndpoint(ctx context.Context, token string) (Result, error) {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Result{}, fmt.Errorf("introspection: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.cfg.ClientID, c.cfg.ClientSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("introspection: call endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("introspection: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("introspection: endpoint returned status %d", resp.StatusCode)
+	}
+
+	var parsed introspectionResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return Result{}, fmt.Errorf("introspection: decode response: %w", err)
+	}
+	return Result{Active: parsed.Active, Subject: parsed.Sub, Scope: parsed.Scope}, nil
+}
+
+// cacheKey hashes token rather than using it directly, so a cache dump or
+// heap profile doesn't expose the raw opaque token.
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,91 @@
+package introspection
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_ActiveTokenReturnsSubjectAndScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":true,"sub":"demo-user","scope":"transactions:read transactions:write"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{URL: server.URL, ClientID: "gateway", ClientSecret: "secret"})
+
+	result, err := client.Introspect(context.Background(), "opaque-token-value")
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if !result.Active || result.Subject != "demo-user" || result.Scope != "transactions:read transactions:write" {
+		t.Fatalf("result = %+v, want active demo-user with both scopes", result)
+	}
+}
+
+func TestClient_InactiveTokenReturnsActiveFalse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":false}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{URL: server.URL})
+
+	result, err := client.Introspect(context.Background(), "revoked-token")
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if result.Active {
+		t.Fatalf("result = %+v, want Active=false", result)
+	}
+}
+
+func TestClient_CachesResultWithinTTL(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":true,"sub":"demo-user"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{URL: server.URL, CacheTTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Introspect(context.Background(), "same-token"); err != nil {
+			t.Fatalf("Introspect %d: %v", i, err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("endpoint called %d times, want 1 (later calls should hit the cache)", got)
+	}
+}
+
+func TestClient_CircuitBreakerOpensAfterRepeatedFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		URL:                            server.URL,
+		CircuitBreakerFailureThreshold: 2,
+		CircuitBreakerOpenDuration:     time.Minute,
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Introspect(context.Background(), "bad-token"); err == nil {
+			t.Fatalf("call %d: expected an error from the 500 response", i)
+		}
+	}
+
+	if _, err := client.Introspect(context.Background(), "bad-token"); err != ErrCircuitOpen {
+		t.Fatalf("Introspect after threshold failures = %v, want ErrCircuitOpen", err)
+	}
+}
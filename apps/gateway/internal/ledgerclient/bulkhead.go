@@ -0,0 +1,178 @@
+package ledgerclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+var bulkheadInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "gateway_ledgerclient_bulkhead_in_flight",
+	Help: "Current number of in-flight calls to ledger-core admitted by the bulkhead.",
+})
+
+// BulkheadConfig controls how many concurrent calls are allowed through and
+// how long a call may wait for a slot before being rejected.
+type BulkheadConfig struct {
+	MaxConcurrent int
+	QueueTimeout  time.Duration
+}
+
+func DefaultBulkheadConfig() BulkheadConfig {
+	return BulkheadConfig{MaxConcurrent: 64, QueueTimeout: 100 * time.Millisecond}
+}
+
+// bulkheadLedgerClient caps the number of concurrent in-flight calls to the
+// wrapped client using a buffered-channel semaphore. Callers that can't get
+// a slot within QueueTimeout are rejected with ResourceExhausted rather
+// than queuing indefinitely, so a traffic spike degrades gracefully instead
+// of piling up unbounded gRPC streams against ledger-core.
+type bulkheadLedgerClient struct {
+	next LedgerClient
+	sem  chan struct{}
+	cfg  BulkheadConfig
+}
+
+// WithBulkhead wraps next so that at most cfg.MaxConcurrent calls are
+// in flight at once. It composes with WithRetry and WithCircuitBreaker in
+// either order; placing it innermost (closest to the transport) means
+// retries each re-acquire a slot rather than holding one across retries.
+func WithBulkhead(next LedgerClient, cfg BulkheadConfig) LedgerClient {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultBulkheadConfig().MaxConcurrent
+	}
+	return &bulkheadLedgerClient{
+		next: next,
+		sem:  make(chan struct{}, cfg.MaxConcurrent),
+		cfg:  cfg,
+	}
+}
+
+// acquire blocks until a slot is free, the queue timeout elapses, or ctx is
+// canceled, whichever comes first.
+func (b *bulkheadLedgerClient) acquire(ctx context.Context) (func(), error) {
+	timer := time.NewTimer(b.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case b.sem <- struct{}{}:
+		bulkheadInFlight.Inc()
+		return func() {
+			<-b.sem
+			bulkheadInFlight.Dec()
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, status.Error(codes.ResourceExhausted, "ledgerclient: bulkhead queue full")
+	}
+}
+
+func (b *bulkheadLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.CreateTransaction(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.CreateMultiLegTransaction(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.GetTransaction(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.GetBalance(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.CreateAccount(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.GetAccount(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.CloseAccount(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.UpdateAccount(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.ListAccounts(ctx, req)
+}
+
+func (b *bulkheadLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.next.ListTransactions(ctx, req)
+}
+
+// ConnState forwards to b.next, so a readiness endpoint can see through
+// this decorator to the underlying connection state.
+func (b *bulkheadLedgerClient) ConnState() map[string]string {
+	if stater, ok := b.next.(ConnStater); ok {
+		return stater.ConnState()
+	}
+	return nil
+}
+
+func (b *bulkheadLedgerClient) Close() error {
+	return b.next.Close()
+}
@@ -0,0 +1,197 @@
+package ledgerclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// CircuitBreakerConfig controls when the breaker trips and how long it
+// stays open before allowing a single probe call through.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+}
+
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{FailureThreshold: 5, OpenDuration: 10 * time.Second}
+}
+
+// circuitBreakerLedgerClient trips to "open" after a run of consecutive
+// upstream failures and short-circuits further calls with Unavailable until
+// OpenDuration elapses, at which point a single probe call is allowed
+// through (half-open) to decide whether to close again.
+type circuitBreakerLedgerClient struct {
+	next LedgerClient
+	cfg  CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       breakerState
+	failures    int
+	openedAt    time.Time
+}
+
+// WithCircuitBreaker wraps next with the breaker behavior described above.
+func WithCircuitBreaker(next LedgerClient, cfg CircuitBreakerConfig) LedgerClient {
+	return &circuitBreakerLedgerClient{next: next, cfg: cfg, state: stateClosed}
+}
+
+func (c *circuitBreakerLedgerClient) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case stateOpen:
+		if time.Since(c.openedAt) >= c.cfg.OpenDuration {
+			c.state = stateHalfOpen
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *circuitBreakerLedgerClient) onResult(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.failures = 0
+		c.state = stateClosed
+		return
+	}
+
+	if status.Code(err) != codes.Unavailable {
+		// Only connectivity failures count toward the breaker; application
+		// errors (InvalidArgument, NotFound, ...) don't indicate ledger-core
+		// is unhealthy.
+		return
+	}
+
+	c.failures++
+	if c.state == stateHalfOpen || c.failures >= c.cfg.FailureThreshold {
+		c.state = stateOpen
+		c.openedAt = time.Now()
+	}
+}
+
+func (c *circuitBreakerLedgerClient) rejected() error {
+	return status.Error(codes.Unavailable, "ledgerclient: circuit breaker open")
+}
+
+func (c *circuitBreakerLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.CreateTransaction(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.CreateMultiLegTransaction(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.GetTransaction(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.GetBalance(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.CreateAccount(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.GetAccount(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.CloseAccount(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.UpdateAccount(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.ListAccounts(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+func (c *circuitBreakerLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	if !c.allow() {
+		return nil, c.rejected()
+	}
+	resp, err := c.next.ListTransactions(ctx, req)
+	c.onResult(err)
+	return resp, err
+}
+
+// ConnState forwards to c.next, so a readiness endpoint can see through
+// this decorator to the underlying connection state.
+func (c *circuitBreakerLedgerClient) ConnState() map[string]string {
+	if stater, ok := c.next.(ConnStater); ok {
+		return stater.ConnState()
+	}
+	return nil
+}
+
+func (c *circuitBreakerLedgerClient) Close() error {
+	return c.next.Close()
+}
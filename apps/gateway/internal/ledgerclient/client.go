@@ -0,0 +1,53 @@
+// Package ledgerclient provides the gateway's view of ledger-core: an
+// interface the HTTP handlers depend on, a gRPC-backed implementation, a
+// mock for local development and tests, and a set of decorators (retry,
+// circuit breaker, bulkhead) that compose around either.
+package ledgerclient
+
+import (
+	"context"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// LedgerClient is the gateway's abstraction over ledger-core. Handlers
+// depend on this interface, never on the concrete gRPC client, so they work
+// unchanged against the mock in tests and against decorated clients in
+// production.
+type LedgerClient interface {
+	CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error)
+	CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error)
+	GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error)
+	GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error)
+	CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error)
+	GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error)
+	CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error)
+	UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error)
+	ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error)
+	ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error)
+
+	// Close releases any underlying connection. It is a no-op for the mock.
+	Close() error
+}
+
+// Warmer is implemented by LedgerClients that can eagerly establish their
+// underlying connection. Only the gRPC client needs this (grpc.NewClient
+// dials lazily); callers should type-assert for it rather than adding it
+// to LedgerClient, since the mock has no connection to warm.
+type Warmer interface {
+	// Warmup blocks until the connection is ready to serve traffic, or ctx
+	// is done.
+	Warmup(ctx context.Context) error
+}
+
+// ConnStater is implemented by LedgerClients that can report the current
+// connectivity state of their underlying connection(s), keyed by address,
+// so a readiness endpoint can surface a flapping or down connection
+// without an operator having to dig through logs. Like Warmer, callers
+// should type-assert for it; the mock has no connection to report.
+type ConnStater interface {
+	// ConnState returns the current connectivity.State (as its String
+	// form, e.g. "READY", "TRANSIENT_FAILURE") of each underlying
+	// connection, keyed by the address it was dialed with.
+	ConnState() map[string]string
+}
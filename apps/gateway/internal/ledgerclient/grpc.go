@@ -0,0 +1,241 @@
+package ledgerclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// defaultKeepalive is applied to every connection dialGRPCLedgerClient
+// opens, so an idle connection is pinged often enough to notice a dead
+// peer (a silently dropped TCP connection otherwise looks identical to an
+// idle one) well before an RPC is attempted against it.
+var defaultKeepalive = keepalive.ClientParameters{
+	Time:                30 * time.Second,
+	Timeout:             10 * time.Second,
+	PermitWithoutStream: true,
+}
+
+// replicaKeepalive overrides defaultKeepalive for the read-replica
+// connection, not the primary: a replica is disposable (WithReadReplica
+// fails over to primary on any error), so it's worth pinging it more
+// aggressively to notice a half-open connection and recycle it quickly,
+// which would be excessive overhead to also impose on the primary.
+var replicaKeepalive = keepalive.ClientParameters{
+	Time:                20 * time.Second,
+	Timeout:             5 * time.Second,
+	PermitWithoutStream: true,
+}
+
+// TimeoutConfig controls the per-call deadline grpcLedgerClient applies,
+// split by RPC category since a read over a long history (ListTransactions)
+// can legitimately need longer than a write should ever take.
+type TimeoutConfig struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// DefaultTimeoutConfig applies the same deadline to reads and writes,
+// matching the blanket timeout this decorator had before per-category
+// config existed.
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{Read: 5 * time.Second, Write: 5 * time.Second}
+}
+
+// grpcLedgerClient talks to ledger-core over gRPC. It is deliberately thin:
+// connection lifecycle and a per-category deadline per call. Cross-cutting
+// behavior (retries, circuit breaking, bulkheading) lives in the decorators
+// in this package, not here.
+type grpcLedgerClient struct {
+	addr        string
+	conn        *grpc.ClientConn
+	client      ledgerpb.LedgerServiceClient
+	cfg         TimeoutConfig
+	stopWatcher context.CancelFunc
+}
+
+// NewGRPCLedgerClient dials ledger-core at addr and returns a LedgerClient.
+// The dial is non-blocking (grpc.NewClient does not connect eagerly); the
+// first RPC pays the connection-establishment cost unless a warmup is
+// performed by the caller.
+//
+// tlsConfig, built by the caller via tlsconfig.Build, enables TLS on the
+// connection when non-nil; nil preserves the gateway's original
+// insecure-by-default behavior, for deployments where ledger-core is
+// reached over a trusted network (e.g. the same pod or a service mesh
+// that already terminates TLS).
+//
+// If replicaAddr is non-empty, GetBalance, ListAccounts, and
+// ListTransactions are routed to a second connection dialed at
+// replicaAddr instead, via WithReadReplica, and fail over to the primary
+// if the replica errs. CreateTransaction and CreateAccount always go to
+// the primary. An empty replicaAddr preserves today's behavior: everything
+// goes to the primary.
+func NewGRPCLedgerClient(addr, replicaAddr string, cfg TimeoutConfig, tlsConfig *tls.Config) (LedgerClient, error) {
+	primary, err := dialGRPCLedgerClient(addr, cfg, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("ledgerclient: dial %s: %w", addr, err)
+	}
+	if replicaAddr == "" {
+		return primary, nil
+	}
+
+	replica, err := dialGRPCLedgerClient(replicaAddr, cfg, tlsConfig, grpc.WithKeepaliveParams(replicaKeepalive))
+	if err != nil {
+		return nil, fmt.Errorf("ledgerclient: dial replica %s: %w", replicaAddr, err)
+	}
+	return WithReadReplica(primary, replica), nil
+}
+
+func dialGRPCLedgerClient(addr string, cfg TimeoutConfig, tlsConfig *tls.Config, extraOpts ...grpc.DialOption) (*grpcLedgerClient, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(tenantUnaryInterceptor, metricsUnaryInterceptor),
+		grpc.WithKeepaliveParams(defaultKeepalive),
+	}, extraOpts...)
+
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCtx, stopWatcher := context.WithCancel(context.Background())
+	go watchConnState(watchCtx, addr, conn)
+
+	return &grpcLedgerClient{
+		addr:        addr,
+		conn:        conn,
+		client:      ledgerpb.NewLedgerServiceClient(conn),
+		cfg:         cfg,
+		stopWatcher: stopWatcher,
+	}, nil
+}
+
+// watchConnState logs every connectivity state transition on conn (e.g.
+// READY -> CONNECTING -> TRANSIENT_FAILURE) until ctx is done, which
+// happens when the owning grpcLedgerClient is closed. This is the only
+// way to notice a connection that has gone quietly unhealthy between
+// RPCs -- grpc-go doesn't otherwise surface the transition anywhere.
+func watchConnState(ctx context.Context, addr string, conn *grpc.ClientConn) {
+	state := conn.GetState()
+	for {
+		if !conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		newState := conn.GetState()
+		log.Printf("ledgerclient: connection to %s changed state %s -> %s", addr, state, newState)
+		state = newState
+	}
+}
+
+// Warmup blocks until the underlying connection reaches connectivity.Ready,
+// or ctx is done, whichever comes first. It satisfies Warmer, so callers
+// can establish the connection (and surface a slow or unreachable
+// ledger-core) before accepting traffic rather than on the first request.
+func (c *grpcLedgerClient) Warmup(ctx context.Context) error {
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("ledgerclient: warmup: %w", ctx.Err())
+		}
+	}
+}
+
+func (c *grpcLedgerClient) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+func (c *grpcLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Write)
+	defer cancel()
+	return c.client.CreateTransaction(ctx, req)
+}
+
+func (c *grpcLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Write)
+	defer cancel()
+	return c.client.CreateMultiLegTransaction(ctx, req)
+}
+
+func (c *grpcLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Read)
+	defer cancel()
+	return c.client.GetTransaction(ctx, req)
+}
+
+func (c *grpcLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Read)
+	defer cancel()
+	return c.client.GetBalance(ctx, req)
+}
+
+func (c *grpcLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Write)
+	defer cancel()
+	return c.client.CreateAccount(ctx, req)
+}
+
+func (c *grpcLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Read)
+	defer cancel()
+	return c.client.GetAccount(ctx, req)
+}
+
+func (c *grpcLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Write)
+	defer cancel()
+	return c.client.CloseAccount(ctx, req)
+}
+
+func (c *grpcLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Write)
+	defer cancel()
+	return c.client.UpdateAccount(ctx, req)
+}
+
+func (c *grpcLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Read)
+	defer cancel()
+	return c.client.ListAccounts(ctx, req)
+}
+
+func (c *grpcLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, c.cfg.Read)
+	defer cancel()
+	return c.client.ListTransactions(ctx, req)
+}
+
+// ConnState satisfies ConnStater, reporting the connection's current
+// connectivity.State. GetState is non-blocking and always reflects the
+// latest value watchConnState also logs transitions for, so this needs no
+// state of its own to track.
+func (c *grpcLedgerClient) ConnState() map[string]string {
+	return map[string]string{c.addr: c.conn.GetState().String()}
+}
+
+func (c *grpcLedgerClient) Close() error {
+	c.stopWatcher()
+	return c.conn.Close()
+}
@@ -0,0 +1,53 @@
+package ledgerclient
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// virtualNodesPerShard controls how evenly accounts spread across shards:
+// more virtual nodes per shard smooths out the lumpiness a single hash
+// point per shard would otherwise produce.
+const virtualNodesPerShard = 100
+
+type ringNode struct {
+	hash  uint64
+	shard string
+}
+
+// hashRing maps an arbitrary key to one of a fixed set of shard names via
+// consistent hashing, so adding or removing a shard only reshuffles the
+// keys nearest it on the ring rather than everything.
+type hashRing struct {
+	nodes []ringNode
+}
+
+func newHashRing(shardNames []string) *hashRing {
+	r := &hashRing{nodes: make([]ringNode, 0, len(shardNames)*virtualNodesPerShard)}
+	for _, name := range shardNames {
+		for i := 0; i < virtualNodesPerShard; i++ {
+			r.nodes = append(r.nodes, ringNode{hash: hashKey(fmt.Sprintf("%s#%d", name, i)), shard: name})
+		}
+	}
+	sort.Slice(r.nodes, func(i, j int) bool { return r.nodes[i].hash < r.nodes[j].hash })
+	return r
+}
+
+// shardFor returns the shard name key maps to: the first node clockwise
+// from key's own hash, wrapping around to the first node if key's hash is
+// past the last one.
+func (r *hashRing) shardFor(key string) string {
+	h := hashKey(key)
+	idx := sort.Search(len(r.nodes), func(i int) bool { return r.nodes[i].hash >= h })
+	if idx == len(r.nodes) {
+		idx = 0
+	}
+	return r.nodes[idx].shard
+}
+
+func hashKey(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,46 @@
+package ledgerclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var grpcClientCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "gateway_ledgerclient_grpc_calls_total",
+	Help: "Calls from the gateway to ledger-core, labeled by RPC method and resulting gRPC status code.",
+}, []string{"method", "code"})
+
+var grpcClientCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "gateway_ledgerclient_grpc_call_duration_seconds",
+	Help: "Latency of gateway calls to ledger-core, labeled by RPC method.",
+}, []string{"method"})
+
+// metricsUnaryInterceptor records a call counter (labeled by method and
+// resulting gRPC code) and a latency histogram (labeled by method) for
+// every unary RPC grpcLedgerClient makes, on the default Prometheus
+// registry. It is only wired into the gRPC path; the mock client never
+// goes through gRPC, so it never touches these metrics and tests don't
+// need a registry.
+//
+// It also adds the call's duration to ctx's upstream latency accumulator,
+// if ContextWithUpstreamLatencyTracking was used to create one -- see
+// addUpstreamLatency. A request that makes several gRPC calls (e.g. List
+// enriching with per-account GetBalance) sums across all of them, the
+// same way the Prometheus histogram above counts each one separately but
+// a caller summing latency_ms wants one aggregate number.
+func metricsUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	elapsed := time.Since(start)
+
+	grpcClientCallDuration.WithLabelValues(method).Observe(elapsed.Seconds())
+	grpcClientCallsTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+	addUpstreamLatency(ctx, elapsed)
+
+	return err
+}
@@ -0,0 +1,43 @@
+package ledgerclient
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type upstreamLatencyKey struct{}
+
+// ContextWithUpstreamLatencyTracking attaches a zeroed latency accumulator
+// to ctx. Every gRPC call made with the returned context (directly, or via
+// a context derived from it, e.g. ContextWithTenantID's result) adds its
+// duration to the accumulator -- see metricsUnaryInterceptor -- so a
+// caller like the access log middleware can read back "how much of this
+// request's total latency was spent in ledger-core" via
+// UpstreamLatencyFromContext, without every LedgerClient call site having
+// to report its own duration up the stack by hand.
+func ContextWithUpstreamLatencyTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, upstreamLatencyKey{}, new(int64))
+}
+
+// UpstreamLatencyFromContext returns the sum of every gRPC call duration
+// recorded against ctx since ContextWithUpstreamLatencyTracking was
+// called on it, or 0 if it never was (e.g. tracking wasn't enabled for
+// this request, or the LedgerClient in use isn't grpcLedgerClient and so
+// never records anything).
+func UpstreamLatencyFromContext(ctx context.Context) time.Duration {
+	v, ok := ctx.Value(upstreamLatencyKey{}).(*int64)
+	if !ok {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(v))
+}
+
+// addUpstreamLatency adds d to ctx's accumulator, if it has one. It's a
+// no-op otherwise, so call sites don't need to check
+// ContextWithUpstreamLatencyTracking was ever called.
+func addUpstreamLatency(ctx context.Context, d time.Duration) {
+	if v, ok := ctx.Value(upstreamLatencyKey{}).(*int64); ok {
+		atomic.AddInt64(v, int64(d))
+	}
+}
@@ -0,0 +1,675 @@
+package ledgerclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// mockAccount is the mock's internal representation of an account. It
+// intentionally mirrors what ledger-core would persist, not the wire type.
+type mockAccount struct {
+	id        string
+	userID    string
+	currency  string
+	balance   *big.Rat
+	version   int64
+	createdAt time.Time
+	// status is "active" or "closed"; see CloseAccount.
+	status string
+	// tenantID is the tenant that created this account, taken from
+	// context at CreateAccount time (see ContextWithTenantID). Empty when
+	// tenant scoping isn't configured, in which case ListAccounts doesn't
+	// filter on it either.
+	tenantID string
+	// label and metadata are caller-supplied annotations set via
+	// UpdateAccount; they don't affect ledger state.
+	label    string
+	metadata map[string]string
+}
+
+// mockLedgerClient is an in-memory stand-in for ledger-core, used in local
+// development (no core/Postgres available) and in handler tests. It is not
+// a mock in the testify sense: it implements real (if simplified) ledger
+// semantics so handler tests exercise real request/response shapes.
+// mockTransaction is the mock's record of a booked transfer, kept around so
+// ListTransactions has history to page through.
+type mockTransaction struct {
+	id             string
+	idempotencyKey string
+	fromAccountID  string
+	toAccountID    string
+	amount         *big.Rat
+	// amountStr preserves the original request's canonical amount string
+	// (with the currency's own decimal scale), so replays echo it back
+	// exactly rather than reformatting through a fixed scale.
+	amountStr   string
+	currency    string
+	description string
+	status      string
+	bookedAt    time.Time
+	// executeAt is non-zero for a scheduled transaction that hasn't been
+	// executed yet (status is "SCHEDULED"); the mock doesn't run a clock
+	// to execute these, per CreateTransaction's doc comment.
+	executeAt time.Time
+	// fromBalanceAfter and toBalanceAfter snapshot both accounts' balances
+	// at the moment funds moved, so a later ReturnBalances request can
+	// echo them back without having to touch the (possibly since-changed)
+	// live account balance. Both are empty for a SCHEDULED transaction.
+	fromBalanceAfter string
+	toBalanceAfter   string
+}
+
+type mockLedgerClient struct {
+	mu           sync.Mutex
+	accounts     map[string]*mockAccount
+	transactions []*mockTransaction
+	// byIdempotencyKey lets CreateTransaction recognize a replayed request
+	// and return the original booking instead of booking it again.
+	byIdempotencyKey map[string]*mockTransaction
+	// byIdempotencyKeyMultiLeg is CreateMultiLegTransaction's equivalent of
+	// byIdempotencyKey; kept separate since a multi-leg booking has no
+	// mockTransaction record to key on.
+	byIdempotencyKeyMultiLeg map[string]*ledgerpb.MultiLegTransactionResponse
+}
+
+// NewMockLedgerClient returns a LedgerClient backed by an in-memory ledger
+// seeded with a couple of accounts so the gateway is usable out of the box
+// without a running ledger-core.
+func NewMockLedgerClient() LedgerClient {
+	m := &mockLedgerClient{
+		accounts:                 make(map[string]*mockAccount),
+		byIdempotencyKey:         make(map[string]*mockTransaction),
+		byIdempotencyKeyMultiLeg: make(map[string]*ledgerpb.MultiLegTransactionResponse),
+	}
+	m.seed()
+	return m
+}
+
+func (m *mockLedgerClient) seed() {
+	now := time.Now().UTC()
+	m.accounts["00000000-0000-0000-0000-000000000001"] = &mockAccount{
+		id:        "00000000-0000-0000-0000-000000000001",
+		userID:    "demo-user",
+		currency:  "USD",
+		balance:   big.NewRat(100000, 1),
+		version:   1,
+		createdAt: now,
+		status:    "active",
+	}
+	m.accounts["00000000-0000-0000-0000-000000000002"] = &mockAccount{
+		id:        "00000000-0000-0000-0000-000000000002",
+		userID:    "demo-user",
+		currency:  "USD",
+		balance:   big.NewRat(5000, 1),
+		version:   1,
+		createdAt: now.Add(time.Minute),
+		status:    "active",
+	}
+}
+
+func (m *mockLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if req.FromAccountId == req.ToAccountId {
+		return nil, fmt.Errorf("mock: from_account_id and to_account_id must not be the same account")
+	}
+
+	if req.IdempotencyKey != "" {
+		if existing, ok := m.byIdempotencyKey[req.IdempotencyKey]; ok {
+			amount, amountOK := new(big.Rat).SetString(req.Amount)
+			if existing.fromAccountID != req.FromAccountId || existing.toAccountID != req.ToAccountId ||
+				existing.currency != req.Currency || !amountOK || existing.amount.Cmp(amount) != 0 {
+				return nil, fmt.Errorf("mock: idempotency key %q already used with different parameters", req.IdempotencyKey)
+			}
+			return m.toTransactionResponse(existing, true, req.ReturnBalances), nil
+		}
+	}
+
+	from, ok := m.accounts[req.FromAccountId]
+	if !ok {
+		return nil, fmt.Errorf("mock: from account %s not found", req.FromAccountId)
+	}
+	to, ok := m.accounts[req.ToAccountId]
+	if !ok {
+		return nil, fmt.Errorf("mock: to account %s not found", req.ToAccountId)
+	}
+	if from.status == "closed" {
+		return nil, fmt.Errorf("mock: account %s is closed", req.FromAccountId)
+	}
+	if to.status == "closed" {
+		return nil, fmt.Errorf("mock: account %s is closed", req.ToAccountId)
+	}
+
+	amount, ok := new(big.Rat).SetString(req.Amount)
+	if !ok {
+		return nil, fmt.Errorf("mock: invalid amount %q", req.Amount)
+	}
+
+	var executeAt time.Time
+	if req.ExecuteAt != "" {
+		var err error
+		executeAt, err = time.Parse(time.RFC3339, req.ExecuteAt)
+		if err != nil {
+			return nil, fmt.Errorf("mock: invalid execute_at %q", req.ExecuteAt)
+		}
+	}
+
+	tx := &mockTransaction{
+		id:             uuid.NewString(),
+		idempotencyKey: req.IdempotencyKey,
+		fromAccountID:  req.FromAccountId,
+		toAccountID:    req.ToAccountId,
+		amount:         amount,
+		amountStr:      req.Amount,
+		currency:       req.Currency,
+		description:    req.Description,
+	}
+
+	// A scheduled transaction doesn't move funds yet -- there's no clock
+	// in the mock to execute it later, so it's stored and reported as
+	// SCHEDULED until a real core would run it.
+	if !executeAt.IsZero() {
+		tx.status = "SCHEDULED"
+		tx.executeAt = executeAt
+	} else {
+		if from.balance.Cmp(amount) < 0 {
+			return nil, fmt.Errorf("mock: insufficient funds in account %s", req.FromAccountId)
+		}
+		from.balance.Sub(from.balance, amount)
+		to.balance.Add(to.balance, amount)
+		from.version++
+		to.version++
+		tx.status = "BOOKED"
+		tx.bookedAt = time.Now().UTC()
+		tx.fromBalanceAfter = from.balance.FloatString(2)
+		tx.toBalanceAfter = to.balance.FloatString(2)
+	}
+
+	m.transactions = append(m.transactions, tx)
+	if tx.idempotencyKey != "" {
+		m.byIdempotencyKey[tx.idempotencyKey] = tx
+	}
+
+	return m.toTransactionResponse(tx, false, req.ReturnBalances), nil
+}
+
+// toTransactionResponse renders tx as the wire type, marking it Replayed
+// when it's being returned for a repeat of an already-booked
+// IdempotencyKey rather than a fresh booking. FromBalance/ToBalance are
+// populated only when returnBalances is set and tx actually moved funds;
+// they're keyed off this call's returnBalances rather than the original
+// booking's, so a replay can still opt into seeing them.
+func (m *mockLedgerClient) toTransactionResponse(tx *mockTransaction, replayed, returnBalances bool) *ledgerpb.TransactionResponse {
+	resp := &ledgerpb.TransactionResponse{
+		Id:            tx.id,
+		FromAccountId: tx.fromAccountID,
+		ToAccountId:   tx.toAccountID,
+		Amount:        tx.amountStr,
+		Currency:      tx.currency,
+		Description:   tx.description,
+		Status:        tx.status,
+		Replayed:      replayed,
+	}
+	if !tx.bookedAt.IsZero() {
+		resp.BookedAt = tx.bookedAt.Format(time.RFC3339)
+	}
+	if !tx.executeAt.IsZero() {
+		resp.ExecuteAt = tx.executeAt.Format(time.RFC3339)
+	}
+	if returnBalances {
+		resp.FromBalance = tx.fromBalanceAfter
+		resp.ToBalance = tx.toBalanceAfter
+	}
+	return resp
+}
+
+// CreateMultiLegTransaction books a transaction with more than two legs.
+// Unlike CreateTransaction, it has no separate mockTransaction record --
+// the mock tracks only the balance effect on each leg's account, not a
+// multi-leg transaction history, since ListTransactions/ExportCSV don't
+// yet understand anything but a from/to pair.
+func (m *mockLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(req.Legs) == 0 {
+		return nil, fmt.Errorf("mock: multi-leg transaction must have at least one leg")
+	}
+
+	if req.IdempotencyKey != "" {
+		if existing, ok := m.byIdempotencyKeyMultiLeg[req.IdempotencyKey]; ok {
+			if !multiLegRequestMatchesResponse(req, existing) {
+				return nil, fmt.Errorf("mock: idempotency key %q already used with different parameters", req.IdempotencyKey)
+			}
+			replayed := *existing
+			replayed.Replayed = true
+			return &replayed, nil
+		}
+	}
+
+	debits := new(big.Rat)
+	credits := new(big.Rat)
+	accounts := make([]*mockAccount, len(req.Legs))
+	amounts := make([]*big.Rat, len(req.Legs))
+
+	for i, leg := range req.Legs {
+		acct, ok := m.accounts[leg.AccountId]
+		if !ok {
+			return nil, fmt.Errorf("mock: account %s not found", leg.AccountId)
+		}
+		if acct.status == "closed" {
+			return nil, fmt.Errorf("mock: account %s is closed", leg.AccountId)
+		}
+		amount, ok := new(big.Rat).SetString(leg.Amount)
+		if !ok {
+			return nil, fmt.Errorf("mock: invalid amount %q", leg.Amount)
+		}
+		switch leg.Direction {
+		case "debit":
+			debits.Add(debits, amount)
+		case "credit":
+			credits.Add(credits, amount)
+		default:
+			return nil, fmt.Errorf("mock: leg direction must be %q or %q, got %q", "debit", "credit", leg.Direction)
+		}
+		accounts[i] = acct
+		amounts[i] = amount
+	}
+	if debits.Cmp(credits) != 0 {
+		return nil, fmt.Errorf("mock: legs do not balance: debits %s, credits %s", debits.FloatString(2), credits.FloatString(2))
+	}
+
+	for i, leg := range req.Legs {
+		if leg.Direction == "debit" && accounts[i].balance.Cmp(amounts[i]) < 0 {
+			return nil, fmt.Errorf("mock: insufficient funds in account %s", leg.AccountId)
+		}
+	}
+
+	bookedLegs := make([]*ledgerpb.TransactionLeg, len(req.Legs))
+	for i, leg := range req.Legs {
+		if leg.Direction == "debit" {
+			accounts[i].balance.Sub(accounts[i].balance, amounts[i])
+		} else {
+			accounts[i].balance.Add(accounts[i].balance, amounts[i])
+		}
+		accounts[i].version++
+		bookedLegs[i] = &ledgerpb.TransactionLeg{AccountId: leg.AccountId, Amount: leg.Amount, Direction: leg.Direction}
+	}
+
+	resp := &ledgerpb.MultiLegTransactionResponse{
+		Id:          uuid.NewString(),
+		Currency:    req.Currency,
+		Description: req.Description,
+		Status:      "BOOKED",
+		BookedAt:    time.Now().UTC().Format(time.RFC3339),
+		Legs:        bookedLegs,
+	}
+	if req.IdempotencyKey != "" {
+		m.byIdempotencyKeyMultiLeg[req.IdempotencyKey] = resp
+	}
+	return resp, nil
+}
+
+// multiLegRequestMatchesResponse reports whether req would have produced
+// existing, the response already booked under req's IdempotencyKey, so a
+// replay with the same key but a different leg set is rejected rather
+// than silently returning the original booking -- mirroring
+// CreateTransaction's amount/account comparison for the two-party
+// endpoint.
+func multiLegRequestMatchesResponse(req *ledgerpb.CreateMultiLegTransactionRequest, existing *ledgerpb.MultiLegTransactionResponse) bool {
+	if req.Currency != existing.Currency || req.Description != existing.Description {
+		return false
+	}
+	if len(req.Legs) != len(existing.Legs) {
+		return false
+	}
+	for i, leg := range req.Legs {
+		got := existing.Legs[i]
+		if leg.AccountId != got.AccountId || leg.Amount != got.Amount || leg.Direction != got.Direction {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *mockLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tx := range m.transactions {
+		if tx.id == req.Id {
+			return m.toTransactionResponse(tx, false, false), nil
+		}
+	}
+	return nil, fmt.Errorf("mock: transaction %s not found", req.Id)
+}
+
+func (m *mockLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acct, ok := m.accounts[req.AccountId]
+	if !ok {
+		return nil, fmt.Errorf("mock: account %s not found", req.AccountId)
+	}
+	return &ledgerpb.BalanceResponse{
+		AccountId: acct.id,
+		Currency:  acct.currency,
+		Balance:   acct.balance.FloatString(2),
+		Version:   acct.version,
+	}, nil
+}
+
+func (m *mockLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	balance := new(big.Rat)
+	if req.InitialBalance != "" {
+		var ok bool
+		balance, ok = new(big.Rat).SetString(req.InitialBalance)
+		if !ok {
+			return nil, fmt.Errorf("mock: invalid initial balance %q", req.InitialBalance)
+		}
+	}
+
+	acct := &mockAccount{
+		id:        uuid.NewString(),
+		userID:    req.UserId,
+		currency:  req.Currency,
+		balance:   balance,
+		version:   1,
+		createdAt: time.Now().UTC(),
+		status:    "active",
+		tenantID:  TenantIDFromContext(ctx),
+	}
+	m.accounts[acct.id] = acct
+	return m.toResponse(acct), nil
+}
+
+func (m *mockLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acct, ok := m.accounts[req.AccountId]
+	if !ok {
+		return nil, fmt.Errorf("mock: account %s not found", req.AccountId)
+	}
+	return m.toResponse(acct), nil
+}
+
+// CloseAccount soft-closes an account: it's marked "closed" rather than
+// removed, so GetAccount and ListAccounts still return it, but
+// CreateTransaction will refuse to move money through it afterward.
+func (m *mockLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acct, ok := m.accounts[req.AccountId]
+	if !ok {
+		return nil, fmt.Errorf("mock: account %s not found", req.AccountId)
+	}
+	if err := checkExpectedVersion(acct, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if acct.status == "closed" {
+		return nil, status.Errorf(codes.AlreadyExists, "account %s is already closed", req.AccountId)
+	}
+	if acct.balance.Sign() != 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "account %s has a non-zero balance and cannot be closed", req.AccountId)
+	}
+
+	acct.status = "closed"
+	acct.version++
+	return m.toResponse(acct), nil
+}
+
+// UpdateAccount sets Label and/or Metadata on an account, whichever of
+// req's fields are non-nil; ledger state (currency, balance) is never
+// touched here.
+func (m *mockLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acct, ok := m.accounts[req.AccountId]
+	if !ok {
+		return nil, fmt.Errorf("mock: account %s not found", req.AccountId)
+	}
+	if err := checkExpectedVersion(acct, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	if req.Label != nil {
+		acct.label = *req.Label
+	}
+	if req.Metadata != nil {
+		acct.metadata = req.Metadata
+	}
+	acct.version++
+	return m.toResponse(acct), nil
+}
+
+func (m *mockLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// tenantID is "" when tenant scoping isn't configured, in which case
+	// every account matches on tenant the same way it always has.
+	tenantID := TenantIDFromContext(ctx)
+
+	var owned []*mockAccount
+	for _, acct := range m.accounts {
+		if acct.userID != req.UserId {
+			continue
+		}
+		if tenantID != "" && acct.tenantID != tenantID {
+			continue
+		}
+		if req.Currency != "" && acct.currency != req.Currency {
+			continue
+		}
+		if req.Status != "" && acct.status != req.Status {
+			continue
+		}
+		owned = append(owned, acct)
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		c := compareAccounts(owned[i], owned[j], req.Sort)
+		if req.Order == "desc" {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	start := int((page - 1) * pageSize)
+	end := start + int(pageSize)
+	if start > len(owned) {
+		start = len(owned)
+	}
+	if end > len(owned) {
+		end = len(owned)
+	}
+
+	resp := &ledgerpb.ListAccountsResponse{
+		TotalCount: int32(len(owned)),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for _, acct := range owned[start:end] {
+		resp.Accounts = append(resp.Accounts, m.toResponse(acct))
+	}
+	return resp, nil
+}
+
+func (m *mockLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var startDate, endDate time.Time
+	if req.StartDate != "" {
+		t, err := time.Parse(time.RFC3339, req.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("mock: invalid start_date %q", req.StartDate)
+		}
+		startDate = t
+	}
+	if req.EndDate != "" {
+		t, err := time.Parse(time.RFC3339, req.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("mock: invalid end_date %q", req.EndDate)
+		}
+		endDate = t
+	}
+
+	var matched []*mockTransaction
+	for _, tx := range m.transactions {
+		if tx.fromAccountID != req.AccountId && tx.toAccountID != req.AccountId {
+			continue
+		}
+		if !startDate.IsZero() && tx.bookedAt.Before(startDate) {
+			continue
+		}
+		if !endDate.IsZero() && tx.bookedAt.After(endDate) {
+			continue
+		}
+		matched = append(matched, tx)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].bookedAt.After(matched[j].bookedAt)
+	})
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	start := int((page - 1) * pageSize)
+	end := start + int(pageSize)
+	if start > len(matched) {
+		start = len(matched)
+	}
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	resp := &ledgerpb.ListTransactionsResponse{
+		TotalCount: int32(len(matched)),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for _, tx := range matched[start:end] {
+		resp.Transactions = append(resp.Transactions, m.toAccountTransaction(tx, req.AccountId))
+	}
+	return resp, nil
+}
+
+// toAccountTransaction renders tx from perspective's point of view: the
+// amount is negative when perspective is the source leg, and the
+// counterparty is whichever account is on the other side.
+func (m *mockLedgerClient) toAccountTransaction(tx *mockTransaction, perspective string) *ledgerpb.AccountTransaction {
+	amount := tx.amount
+	counterparty := tx.toAccountID
+	if tx.fromAccountID == perspective {
+		amount = new(big.Rat).Neg(tx.amount)
+	} else {
+		counterparty = tx.fromAccountID
+	}
+	return &ledgerpb.AccountTransaction{
+		Id:             tx.id,
+		CounterpartyId: counterparty,
+		Amount:         amount.FloatString(2),
+		Currency:       tx.currency,
+		Status:         tx.status,
+		Description:    tx.description,
+		BookedAt:       tx.bookedAt.Format(time.RFC3339),
+	}
+}
+
+func (m *mockLedgerClient) toResponse(acct *mockAccount) *ledgerpb.AccountResponse {
+	return &ledgerpb.AccountResponse{
+		Id:        acct.id,
+		UserId:    acct.userID,
+		Currency:  acct.currency,
+		Balance:   acct.balance.FloatString(2),
+		Version:   acct.version,
+		CreatedAt: acct.createdAt.Format(time.RFC3339),
+		Status:    acct.status,
+		Label:     acct.label,
+		Metadata:  acct.metadata,
+	}
+}
+
+// checkExpectedVersion enforces optimistic-concurrency checks for
+// CloseAccount/UpdateAccount: expected of 0 means the caller sent no
+// If-Match and the check is skipped, matching how a freshly-seeded or
+// never-fetched account wouldn't have a version to compare against.
+func checkExpectedVersion(acct *mockAccount, expected int64) error {
+	if expected != 0 && expected != acct.version {
+		return status.Errorf(codes.Aborted, "account %s version mismatch: expected %d, got %d", acct.id, expected, acct.version)
+	}
+	return nil
+}
+
+// compareAccounts orders a against b by field ("created_at", "currency",
+// or "balance"; anything else, including "", falls through to the
+// default), breaking ties -- and handling the default itself -- by
+// account ID, so ListAccounts is deterministic regardless of Go's map
+// iteration order.
+func compareAccounts(a, b *mockAccount, field string) int {
+	switch field {
+	case "created_at":
+		if a.createdAt.Before(b.createdAt) {
+			return -1
+		}
+		if a.createdAt.After(b.createdAt) {
+			return 1
+		}
+	case "currency":
+		if c := strings.Compare(a.currency, b.currency); c != 0 {
+			return c
+		}
+	case "balance":
+		if c := a.balance.Cmp(b.balance); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.id, b.id)
+}
+
+// ConnState satisfies ConnStater. The mock has no real connection to
+// report on, so it reports itself as always READY -- a readiness check
+// run against the mock shouldn't surface a connection problem that can't
+// exist.
+func (m *mockLedgerClient) ConnState() map[string]string {
+	return map[string]string{"mock": "READY"}
+}
+
+func (m *mockLedgerClient) Close() error {
+	return nil
+}
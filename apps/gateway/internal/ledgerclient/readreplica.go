@@ -0,0 +1,140 @@
+package ledgerclient
+
+import (
+	"context"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// readReplicaLedgerClient routes GetBalance, GetTransaction, ListAccounts,
+// and ListTransactions to replica, falling back to primary if the replica
+// call errs, while CreateTransaction and CreateAccount always go to
+// primary. GetAccount also stays on primary: handlers use it for
+// ownership checks, where slightly stale data is a correctness risk
+// GetBalance's "tolerate slightly stale" tradeoff doesn't share.
+type readReplicaLedgerClient struct {
+	primary LedgerClient
+	replica LedgerClient
+}
+
+// WithReadReplica wraps primary so read-heavy, staleness-tolerant calls
+// are offloaded to replica, freeing the primary for writes and
+// consistency-sensitive reads. Pass a nil replica to route everything to
+// primary (equivalent to not wrapping at all); this lets callers construct
+// the wrapper unconditionally and decide replica-or-not via the value
+// rather than branching at every call site.
+func WithReadReplica(primary, replica LedgerClient) LedgerClient {
+	return &readReplicaLedgerClient{primary: primary, replica: replica}
+}
+
+func (c *readReplicaLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	return c.primary.CreateTransaction(ctx, req)
+}
+
+func (c *readReplicaLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	return c.primary.CreateMultiLegTransaction(ctx, req)
+}
+
+func (c *readReplicaLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return c.primary.CreateAccount(ctx, req)
+}
+
+func (c *readReplicaLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return c.primary.GetAccount(ctx, req)
+}
+
+func (c *readReplicaLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return c.primary.CloseAccount(ctx, req)
+}
+
+func (c *readReplicaLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return c.primary.UpdateAccount(ctx, req)
+}
+
+func (c *readReplicaLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	if c.replica == nil {
+		return c.primary.GetBalance(ctx, req)
+	}
+	resp, err := c.replica.GetBalance(ctx, req)
+	if err != nil {
+		return c.primary.GetBalance(ctx, req)
+	}
+	return resp, nil
+}
+
+func (c *readReplicaLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	if c.replica == nil {
+		return c.primary.GetTransaction(ctx, req)
+	}
+	resp, err := c.replica.GetTransaction(ctx, req)
+	if err != nil {
+		return c.primary.GetTransaction(ctx, req)
+	}
+	return resp, nil
+}
+
+func (c *readReplicaLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	if c.replica == nil {
+		return c.primary.ListAccounts(ctx, req)
+	}
+	resp, err := c.replica.ListAccounts(ctx, req)
+	if err != nil {
+		return c.primary.ListAccounts(ctx, req)
+	}
+	return resp, nil
+}
+
+func (c *readReplicaLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	if c.replica == nil {
+		return c.primary.ListTransactions(ctx, req)
+	}
+	resp, err := c.replica.ListTransactions(ctx, req)
+	if err != nil {
+		return c.primary.ListTransactions(ctx, req)
+	}
+	return resp, nil
+}
+
+// Warmup warms the primary (required: CreateTransaction/CreateAccount/
+// GetAccount have nowhere else to go) and, best-effort, the replica. A
+// replica that fails to warm doesn't fail the overall Warmup, since every
+// read already fails over to the primary.
+func (c *readReplicaLedgerClient) Warmup(ctx context.Context) error {
+	if warmer, ok := c.primary.(Warmer); ok {
+		if err := warmer.Warmup(ctx); err != nil {
+			return err
+		}
+	}
+	if warmer, ok := c.replica.(Warmer); ok {
+		_ = warmer.Warmup(ctx)
+	}
+	return nil
+}
+
+// ConnState merges ConnState from the primary and replica, when either
+// implements ConnStater. The two dial different addresses, so there's no
+// collision to resolve between them.
+func (c *readReplicaLedgerClient) ConnState() map[string]string {
+	states := map[string]string{}
+	if stater, ok := c.primary.(ConnStater); ok {
+		for addr, state := range stater.ConnState() {
+			states[addr] = state
+		}
+	}
+	if stater, ok := c.replica.(ConnStater); ok {
+		for addr, state := range stater.ConnState() {
+			states[addr] = state
+		}
+	}
+	return states
+}
+
+func (c *readReplicaLedgerClient) Close() error {
+	if err := c.primary.Close(); err != nil {
+		return err
+	}
+	if c.replica != nil {
+		return c.replica.Close()
+	}
+	return nil
+}
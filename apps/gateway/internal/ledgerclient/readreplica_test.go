@@ -0,0 +1,89 @@
+package ledgerclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+const seededAccountID = "00000000-0000-0000-0000-000000000001"
+
+func TestWithReadReplica_RoutesReadsToReplica(t *testing.T) {
+	primary := NewMockLedgerClient()
+	replica := NewMockLedgerClient()
+	client := WithReadReplica(primary, replica)
+
+	if _, err := primary.CreateTransaction(context.Background(), &ledgerpb.CreateTransactionRequest{
+		FromAccountId: seededAccountID,
+		ToAccountId:   "00000000-0000-0000-0000-000000000002",
+		Amount:        "10.00",
+		Currency:      "USD",
+	}); err != nil {
+		t.Fatalf("seed CreateTransaction on primary: %v", err)
+	}
+
+	got, err := client.GetBalance(context.Background(), &ledgerpb.GetBalanceRequest{AccountId: seededAccountID})
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	want, err := replica.GetBalance(context.Background(), &ledgerpb.GetBalanceRequest{AccountId: seededAccountID})
+	if err != nil {
+		t.Fatalf("replica GetBalance: %v", err)
+	}
+	if got.Balance != want.Balance {
+		t.Fatalf("GetBalance routed to primary (balance=%s) instead of replica (balance=%s)", got.Balance, want.Balance)
+	}
+}
+
+func TestWithReadReplica_FailsOverToPrimaryOnReplicaError(t *testing.T) {
+	primary := NewMockLedgerClient()
+	replica := NewMockLedgerClient()
+	client := WithReadReplica(primary, replica)
+
+	// seededAccountID exists on both mocks (seeded by NewMockLedgerClient),
+	// so use an account that only exists on primary to force the replica
+	// to error and trigger failover.
+	if _, err := primary.CreateAccount(context.Background(), &ledgerpb.CreateAccountRequest{
+		UserId:   "demo-user",
+		Currency: "USD",
+	}); err != nil {
+		t.Fatalf("seed CreateAccount on primary: %v", err)
+	}
+
+	listResp, err := primary.ListAccounts(context.Background(), &ledgerpb.ListAccountsRequest{UserId: "demo-user"})
+	if err != nil {
+		t.Fatalf("ListAccounts on primary: %v", err)
+	}
+	var newAccountID string
+	for _, acct := range listResp.Accounts {
+		if acct.Id != seededAccountID && acct.Id != "00000000-0000-0000-0000-000000000002" {
+			newAccountID = acct.Id
+			break
+		}
+	}
+	if newAccountID == "" {
+		t.Fatalf("could not find the newly created account in primary's ListAccounts response")
+	}
+
+	got, err := client.GetBalance(context.Background(), &ledgerpb.GetBalanceRequest{AccountId: newAccountID})
+	if err != nil {
+		t.Fatalf("GetBalance did not fail over to primary: %v", err)
+	}
+	if got.AccountId != newAccountID {
+		t.Fatalf("GetBalance returned account %s, want %s", got.AccountId, newAccountID)
+	}
+}
+
+func TestWithReadReplica_NilReplicaRoutesEverythingToPrimary(t *testing.T) {
+	primary := NewMockLedgerClient()
+	client := WithReadReplica(primary, nil)
+
+	got, err := client.GetBalance(context.Background(), &ledgerpb.GetBalanceRequest{AccountId: seededAccountID})
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if got.AccountId != seededAccountID {
+		t.Fatalf("GetBalance returned account %s, want %s", got.AccountId, seededAccountID)
+	}
+}
@@ -0,0 +1,182 @@
+package ledgerclient
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// RetryConfig controls the retry decorator's backoff schedule.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryConfig mirrors what a hand-rolled retry loop would have used
+// before this decorator existed: a handful of attempts with short,
+// exponentially growing delay.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{MaxAttempts: 3, BaseDelay: 50 * time.Millisecond, MaxDelay: 1 * time.Second}
+}
+
+// retryLedgerClient retries calls that fail with a transient gRPC status
+// (Unavailable, DeadlineExceeded on the attempt, not the overall request).
+// Only idempotent reads and CreateTransaction (itself idempotency-keyed) are
+// safe to retry; mutating the retry policy per-method is left to callers
+// that need it.
+type retryLedgerClient struct {
+	next LedgerClient
+	cfg  RetryConfig
+}
+
+// WithRetry wraps next with retry-on-transient-error behavior.
+func WithRetry(next LedgerClient, cfg RetryConfig) LedgerClient {
+	return &retryLedgerClient{next: next, cfg: cfg}
+}
+
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.ResourceExhausted
+}
+
+func (c *retryLedgerClient) call(ctx context.Context, fn func() error) error {
+	var err error
+	delay := c.cfg.BaseDelay
+	for attempt := 1; attempt <= c.cfg.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !isRetryable(err) || attempt == c.cfg.MaxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > c.cfg.MaxDelay {
+			delay = c.cfg.MaxDelay
+		}
+	}
+	return err
+}
+
+func (c *retryLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	var resp *ledgerpb.TransactionResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.CreateTransaction(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	var resp *ledgerpb.MultiLegTransactionResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.CreateMultiLegTransaction(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	var resp *ledgerpb.TransactionResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.GetTransaction(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	var resp *ledgerpb.BalanceResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.GetBalance(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	var resp *ledgerpb.AccountResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.CreateAccount(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	var resp *ledgerpb.AccountResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.GetAccount(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	var resp *ledgerpb.AccountResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.CloseAccount(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	var resp *ledgerpb.AccountResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.UpdateAccount(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	var resp *ledgerpb.ListAccountsResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.ListAccounts(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+func (c *retryLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	var resp *ledgerpb.ListTransactionsResponse
+	err := c.call(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.next.ListTransactions(ctx, req)
+		return innerErr
+	})
+	return resp, err
+}
+
+// ConnState forwards to c.next, so a readiness endpoint can see through
+// this decorator to the underlying connection state.
+func (c *retryLedgerClient) ConnState() map[string]string {
+	if stater, ok := c.next.(ConnStater); ok {
+		return stater.ConnState()
+	}
+	return nil
+}
+
+func (c *retryLedgerClient) Close() error {
+	return c.next.Close()
+}
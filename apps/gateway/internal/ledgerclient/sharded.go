@@ -0,0 +1,185 @@
+package ledgerclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+// ShardedLedgerClient routes RPCs to one of several per-shard
+// LedgerClients, chosen by a consistent hash (see hashRing), so the
+// gateway can scale past what a single ledger-core can hold.
+//
+// Account-scoped calls (GetAccount, GetBalance, ListTransactions) hash on
+// the account ID. User-scoped calls that don't have an account ID yet
+// (CreateAccount, ListAccounts) hash on the user ID instead, so a user's
+// accounts land on the shard their existing accounts would route to.
+//
+// CreateTransaction hashes on the from-account; if the to-account hashes
+// to a different shard, it's rejected with FailedPrecondition rather than
+// silently debited on one shard and never credited on the other --
+// cross-shard transfers aren't supported without a distributed
+// transaction protocol, which is future work.
+type ShardedLedgerClient struct {
+	ring   *hashRing
+	shards map[string]LedgerClient
+}
+
+// NewShardedLedgerClient builds a ShardedLedgerClient from shards, keyed
+// by whatever identifier the caller wants attributed in errors (typically
+// the shard's ledger-core address).
+func NewShardedLedgerClient(shards map[string]LedgerClient) *ShardedLedgerClient {
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		names = append(names, name)
+	}
+	return &ShardedLedgerClient{ring: newHashRing(names), shards: shards}
+}
+
+func (s *ShardedLedgerClient) shardFor(key string) LedgerClient {
+	return s.shards[s.ring.shardFor(key)]
+}
+
+func (s *ShardedLedgerClient) CreateTransaction(ctx context.Context, req *ledgerpb.CreateTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	fromShard := s.ring.shardFor(req.FromAccountId)
+	toShard := s.ring.shardFor(req.ToAccountId)
+	if fromShard != toShard {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"ledgerclient: cross-shard transfers are not supported (from-account routes to shard %q, to-account routes to shard %q)",
+			fromShard, toShard)
+	}
+	return s.shards[fromShard].CreateTransaction(ctx, req)
+}
+
+// CreateMultiLegTransaction hashes on the first leg's account, mirroring
+// CreateTransaction's from-account routing; every other leg's account must
+// hash to the same shard or the whole leg set is rejected with
+// FailedPrecondition, for the same cross-shard-atomicity reason
+// CreateTransaction rejects a cross-shard from/to pair.
+func (s *ShardedLedgerClient) CreateMultiLegTransaction(ctx context.Context, req *ledgerpb.CreateMultiLegTransactionRequest) (*ledgerpb.MultiLegTransactionResponse, error) {
+	if len(req.Legs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ledgerclient: multi-leg transaction must have at least one leg")
+	}
+	shard := s.ring.shardFor(req.Legs[0].AccountId)
+	for _, leg := range req.Legs[1:] {
+		legShard := s.ring.shardFor(leg.AccountId)
+		if legShard != shard {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"ledgerclient: cross-shard multi-leg transactions are not supported (account %q routes to shard %q, expected %q)",
+				leg.AccountId, legShard, shard)
+		}
+	}
+	return s.shards[shard].CreateMultiLegTransaction(ctx, req)
+}
+
+// GetTransaction has no account ID to route on -- a transaction ID alone
+// doesn't say which shard booked it -- so it fans out to every shard
+// concurrently and returns whichever finds it. NotFound only if every
+// shard reports NotFound; any other error from a shard is returned as-is
+// once no shard has produced a hit, since it may be masking the shard
+// that actually has the transaction.
+func (s *ShardedLedgerClient) GetTransaction(ctx context.Context, req *ledgerpb.GetTransactionRequest) (*ledgerpb.TransactionResponse, error) {
+	type result struct {
+		resp *ledgerpb.TransactionResponse
+		err  error
+	}
+	results := make(chan result, len(s.shards))
+	for _, shard := range s.shards {
+		go func(shard LedgerClient) {
+			resp, err := shard.GetTransaction(ctx, req)
+			results <- result{resp: resp, err: err}
+		}(shard)
+	}
+
+	var lastErr error
+	for i := 0; i < len(s.shards); i++ {
+		r := <-results
+		if r.err == nil {
+			return r.resp, nil
+		}
+		if status.Code(r.err) != codes.NotFound {
+			lastErr = r.err
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, status.Errorf(codes.NotFound, "ledgerclient: transaction %s not found on any shard", req.Id)
+}
+
+func (s *ShardedLedgerClient) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.BalanceResponse, error) {
+	return s.shardFor(req.AccountId).GetBalance(ctx, req)
+}
+
+func (s *ShardedLedgerClient) GetAccount(ctx context.Context, req *ledgerpb.GetAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return s.shardFor(req.AccountId).GetAccount(ctx, req)
+}
+
+func (s *ShardedLedgerClient) CloseAccount(ctx context.Context, req *ledgerpb.CloseAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return s.shardFor(req.AccountId).CloseAccount(ctx, req)
+}
+
+func (s *ShardedLedgerClient) UpdateAccount(ctx context.Context, req *ledgerpb.UpdateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return s.shardFor(req.AccountId).UpdateAccount(ctx, req)
+}
+
+func (s *ShardedLedgerClient) ListTransactions(ctx context.Context, req *ledgerpb.ListTransactionsRequest) (*ledgerpb.ListTransactionsResponse, error) {
+	return s.shardFor(req.AccountId).ListTransactions(ctx, req)
+}
+
+func (s *ShardedLedgerClient) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountResponse, error) {
+	return s.shardFor(req.UserId).CreateAccount(ctx, req)
+}
+
+func (s *ShardedLedgerClient) ListAccounts(ctx context.Context, req *ledgerpb.ListAccountsRequest) (*ledgerpb.ListAccountsResponse, error) {
+	return s.shardFor(req.UserId).ListAccounts(ctx, req)
+}
+
+// Warmup warms every shard that implements Warmer. Unlike
+// readReplicaLedgerClient, where the replica is an optional optimization,
+// every shard here is load-bearing -- losing one means a slice of accounts
+// is unreachable -- so a single shard's warmup failure fails the whole
+// call rather than degrading silently.
+func (s *ShardedLedgerClient) Warmup(ctx context.Context) error {
+	var errs []error
+	for name, shard := range s.shards {
+		warmer, ok := shard.(Warmer)
+		if !ok {
+			continue
+		}
+		if err := warmer.Warmup(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shard %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ConnState merges ConnState from every shard that implements ConnStater,
+// keyed by each shard's own address so a single unhealthy shard is
+// identifiable in the readiness response.
+func (s *ShardedLedgerClient) ConnState() map[string]string {
+	states := map[string]string{}
+	for _, shard := range s.shards {
+		if stater, ok := shard.(ConnStater); ok {
+			for addr, state := range stater.ConnState() {
+				states[addr] = state
+			}
+		}
+	}
+	return states
+}
+
+func (s *ShardedLedgerClient) Close() error {
+	var errs []error
+	for _, shard := range s.shards {
+		if err := shard.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package ledgerclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerpb"
+)
+
+func TestShardedLedgerClient_RoutesByAccountID(t *testing.T) {
+	shardA := NewMockLedgerClient()
+	shardB := NewMockLedgerClient()
+	shards := map[string]LedgerClient{"a": shardA, "b": shardB}
+	client := NewShardedLedgerClient(shards)
+
+	resolved := client.shardFor(seededAccountID)
+	got, err := client.GetBalance(context.Background(), &ledgerpb.GetBalanceRequest{AccountId: seededAccountID})
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	want, err := resolved.GetBalance(context.Background(), &ledgerpb.GetBalanceRequest{AccountId: seededAccountID})
+	if err != nil {
+		t.Fatalf("resolved shard GetBalance: %v", err)
+	}
+	if got.Balance != want.Balance {
+		t.Fatalf("GetBalance did not route to the shard the hash ring picked (got=%s want=%s)", got.Balance, want.Balance)
+	}
+}
+
+func TestShardedLedgerClient_RejectsCrossShardTransaction(t *testing.T) {
+	shardA := NewMockLedgerClient()
+	shardB := NewMockLedgerClient()
+	shards := map[string]LedgerClient{"a": shardA, "b": shardB}
+	client := NewShardedLedgerClient(shards)
+
+	// Scan account IDs until the ring assigns two of them to different
+	// shards -- the assignment isn't predictable from the IDs alone.
+	var fromID, toID string
+	for i := 0; fromID == "" || toID == ""; i++ {
+		id := fmt.Sprintf("acct-%d", i)
+		if fromID == "" {
+			fromID = id
+			continue
+		}
+		if client.ring.shardFor(id) != client.ring.shardFor(fromID) {
+			toID = id
+		}
+		if i > 1000 {
+			t.Fatalf("could not find two account IDs that hash to different shards")
+		}
+	}
+
+	_, err := client.CreateTransaction(context.Background(), &ledgerpb.CreateTransactionRequest{
+		FromAccountId: fromID,
+		ToAccountId:   toID,
+		Amount:        "10.00",
+		Currency:      "USD",
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("CreateTransaction across shards returned %v, want FailedPrecondition", err)
+	}
+}
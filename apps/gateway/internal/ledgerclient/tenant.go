@@ -0,0 +1,48 @@
+package ledgerclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// tenantMetadataKey is the outgoing gRPC metadata key tenantUnaryInterceptor
+// forwards the caller's tenant ID under, so ledger-core can read it back
+// the same way it would any other request metadata.
+const tenantMetadataKey = "x-tenant-id"
+
+type tenantContextKey struct{}
+
+// ContextWithTenantID attaches tenantID to ctx, so a LedgerClient call made
+// with the returned context carries it downstream -- over gRPC metadata
+// for grpcLedgerClient (see tenantUnaryInterceptor), or read straight back
+// out of ctx for mockLedgerClient, which has no transport to carry
+// metadata over. An empty tenantID is a no-op, so callers can pass through
+// userIDFromContext-style "no tenant configured" values without a guard.
+func ContextWithTenantID(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantIDFromContext returns the tenant ID ContextWithTenantID attached to
+// ctx, or "" if none was.
+func TenantIDFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// tenantUnaryInterceptor forwards the tenant ID ContextWithTenantID
+// attached to ctx, if any, to ledger-core as outgoing gRPC metadata --
+// the way a proxy forwards a header it didn't originate but the next hop
+// still needs to see. A call made with no tenant ID in context (tenant
+// scoping not configured, or an RPC that doesn't go through a handler) is
+// unaffected.
+func tenantUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if tenantID := TenantIDFromContext(ctx); tenantID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tenantMetadataKey, tenantID)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,207 @@
+// Code generated from api/proto/v1/ledger.proto. Regenerate with `make proto`.
+// Hand-maintained until the protoc toolchain is wired into CI — keep in sync
+// with the .proto source.
+
+// Package ledgerpb contains the request/response types and client stub for
+// ledger.v1.LedgerService, the gRPC service implemented by ledger-core.
+package ledgerpb
+
+type CreateTransactionRequest struct {
+	IdempotencyKey string
+	FromAccountId  string
+	ToAccountId    string
+	Amount         string
+	Currency       string
+	Description    string
+	// ExecuteAt, if set (RFC3339), schedules the transfer for a future
+	// time instead of executing it immediately.
+	ExecuteAt string
+	// ReturnBalances asks ledger-core to echo the post-transaction
+	// FromBalance/ToBalance on TransactionResponse, saving the caller a
+	// round trip to fetch them separately. ledger-core is free to leave
+	// them empty if it doesn't support this.
+	ReturnBalances bool
+}
+
+type TransactionResponse struct {
+	Id            string
+	FromAccountId string
+	ToAccountId   string
+	Amount        string
+	Currency      string
+	Description   string
+	// Status is "BOOKED", "SCHEDULED" (a future ExecuteAt that hasn't
+	// executed yet), or a terminal state reached afterward.
+	Status string
+	// BookedAt is empty while Status is "SCHEDULED".
+	BookedAt string
+	// ExecuteAt echoes the request's scheduled time, empty for
+	// immediately-executed transactions.
+	ExecuteAt string
+	// Replayed is true when this response is the previously booked
+	// transaction for an already-used IdempotencyKey, rather than a newly
+	// booked one.
+	Replayed bool
+	// FromBalance and ToBalance are the post-transaction balances of
+	// FromAccountId and ToAccountId, set only when the request had
+	// ReturnBalances and the transaction actually moved funds (empty for a
+	// SCHEDULED transaction, or if ledger-core didn't provide them).
+	FromBalance string
+	ToBalance   string
+}
+
+type GetTransactionRequest struct {
+	Id string
+}
+
+// TransactionLeg is one side of a multi-leg transaction: Direction is
+// "debit" or "credit", and Amount is a positive, currency-scaled decimal
+// string, matching CreateTransactionRequest.Amount's conventions.
+type TransactionLeg struct {
+	AccountId string
+	Amount    string
+	Direction string
+}
+
+// CreateMultiLegTransactionRequest books a transaction with more than two
+// sides -- e.g. one debit split across several credits. Legs must balance
+// to zero (sum of debits equals sum of credits) in Currency; ledger-core
+// rejects an unbalanced set with InvalidArgument.
+type CreateMultiLegTransactionRequest struct {
+	IdempotencyKey string
+	Currency       string
+	Description    string
+	Legs           []*TransactionLeg
+}
+
+// MultiLegTransactionResponse is the booked (or replayed) multi-leg
+// transaction. Unlike TransactionResponse, it has no single from/to pair --
+// Legs echoes the booked sides in the order they were requested.
+type MultiLegTransactionResponse struct {
+	Id          string
+	Currency    string
+	Description string
+	Status      string
+	BookedAt    string
+	Legs        []*TransactionLeg
+	// Replayed is true when this response is the previously booked
+	// transaction for an already-used IdempotencyKey, rather than a newly
+	// booked one.
+	Replayed bool
+}
+
+type GetBalanceRequest struct {
+	AccountId string
+}
+
+type BalanceResponse struct {
+	AccountId string
+	Currency  string
+	Balance   string
+	// Version is the account's current version, for clients doing
+	// optimistic-concurrency updates via If-Match.
+	Version int64
+}
+
+type CreateAccountRequest struct {
+	UserId         string
+	Currency       string
+	InitialBalance string
+}
+
+type AccountResponse struct {
+	Id        string
+	UserId    string
+	Currency  string
+	Balance   string
+	Version   int64
+	CreatedAt string
+	// Status is "active" or "closed"; see CloseAccountRequest.
+	Status   string
+	Label    string
+	Metadata map[string]string
+}
+
+type GetAccountRequest struct {
+	AccountId string
+}
+
+// CloseAccountRequest soft-closes an account. ledger-core rejects the
+// close with FailedPrecondition if the account's balance isn't zero.
+//
+// ExpectedVersion, if non-zero, is the version the caller last observed
+// (typically from an ETag header); ledger-core rejects the call with
+// Aborted if the account's current version doesn't match, so a client
+// can't unknowingly close an account that changed underneath it.
+type CloseAccountRequest struct {
+	AccountId       string
+	ExpectedVersion int64
+}
+
+// UpdateAccountRequest updates an account's Label and/or Metadata, never
+// its ledger state (Currency, Balance). Label and Metadata are both
+// optional; a nil field leaves the existing value unchanged, matching
+// PATCH's partial-update semantics.
+//
+// ExpectedVersion, if non-zero, is the version the caller last observed;
+// see CloseAccountRequest.ExpectedVersion.
+type UpdateAccountRequest struct {
+	AccountId       string
+	Label           *string
+	Metadata        map[string]string
+	ExpectedVersion int64
+}
+
+// ListAccountsRequest pages through the caller's accounts. Sort, if set,
+// is one of "created_at", "currency", or "balance"; the unset default
+// orders by account ID. Order is "asc" or "desc", defaulting to "asc".
+// Currency and Status, if set, filter the results to that currency code
+// and account status ("active" or "closed") respectively.
+type ListAccountsRequest struct {
+	UserId   string
+	Page     int32
+	PageSize int32
+	Sort     string
+	Order    string
+	Currency string
+	Status   string
+}
+
+type ListAccountsResponse struct {
+	Accounts   []*AccountResponse
+	TotalCount int32
+	Page       int32
+	PageSize   int32
+}
+
+// ListTransactionsRequest asks for a page of an account's transaction
+// history, optionally bounded to [StartDate, EndDate] (RFC3339; either may
+// be empty for an open-ended range).
+type ListTransactionsRequest struct {
+	AccountId string
+	Page      int32
+	PageSize  int32
+	StartDate string
+	EndDate   string
+}
+
+// AccountTransaction is one entry in an account's transaction history, from
+// that account's perspective: Amount is signed (negative when the account
+// is the source leg), and CounterpartyId is the account on the other side
+// of the transfer.
+type AccountTransaction struct {
+	Id             string
+	CounterpartyId string
+	Amount         string
+	Currency       string
+	Status         string
+	Description    string
+	BookedAt       string
+}
+
+type ListTransactionsResponse struct {
+	Transactions []*AccountTransaction
+	TotalCount   int32
+	Page         int32
+	PageSize     int32
+}
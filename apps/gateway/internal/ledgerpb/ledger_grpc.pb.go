@@ -0,0 +1,126 @@
+// Code generated from api/proto/v1/ledger.proto. Regenerate with `make proto`.
+
+package ledgerpb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	ServiceName = "ledger.v1.LedgerService"
+
+	MethodCreateTransaction         = "/ledger.v1.LedgerService/CreateTransaction"
+	MethodCreateMultiLegTransaction = "/ledger.v1.LedgerService/CreateMultiLegTransaction"
+	MethodGetBalance                = "/ledger.v1.LedgerService/GetBalance"
+	MethodCreateAccount             = "/ledger.v1.LedgerService/CreateAccount"
+	MethodGetAccount                = "/ledger.v1.LedgerService/GetAccount"
+	MethodCloseAccount              = "/ledger.v1.LedgerService/CloseAccount"
+	MethodUpdateAccount             = "/ledger.v1.LedgerService/UpdateAccount"
+	MethodListAccounts              = "/ledger.v1.LedgerService/ListAccounts"
+	MethodListTransactions          = "/ledger.v1.LedgerService/ListTransactions"
+	MethodGetTransaction            = "/ledger.v1.LedgerService/GetTransaction"
+)
+
+// LedgerServiceClient is the client API for LedgerService.
+type LedgerServiceClient interface {
+	CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	CreateMultiLegTransaction(ctx context.Context, in *CreateMultiLegTransactionRequest, opts ...grpc.CallOption) (*MultiLegTransactionResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error)
+	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error)
+	CloseAccount(ctx context.Context, in *CloseAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error)
+	UpdateAccount(ctx context.Context, in *UpdateAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error)
+	ListAccounts(ctx context.Context, in *ListAccountsRequest, opts ...grpc.CallOption) (*ListAccountsResponse, error)
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+}
+
+type ledgerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLedgerServiceClient(cc grpc.ClientConnInterface) LedgerServiceClient {
+	return &ledgerServiceClient{cc: cc}
+}
+
+func (c *ledgerServiceClient) CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	if err := c.cc.Invoke(ctx, MethodCreateTransaction, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) CreateMultiLegTransaction(ctx context.Context, in *CreateMultiLegTransactionRequest, opts ...grpc.CallOption) (*MultiLegTransactionResponse, error) {
+	out := new(MultiLegTransactionResponse)
+	if err := c.cc.Invoke(ctx, MethodCreateMultiLegTransaction, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	if err := c.cc.Invoke(ctx, MethodGetTransaction, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	if err := c.cc.Invoke(ctx, MethodGetBalance, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error) {
+	out := new(AccountResponse)
+	if err := c.cc.Invoke(ctx, MethodCreateAccount, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error) {
+	out := new(AccountResponse)
+	if err := c.cc.Invoke(ctx, MethodGetAccount, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) CloseAccount(ctx context.Context, in *CloseAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error) {
+	out := new(AccountResponse)
+	if err := c.cc.Invoke(ctx, MethodCloseAccount, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) UpdateAccount(ctx context.Context, in *UpdateAccountRequest, opts ...grpc.CallOption) (*AccountResponse, error) {
+	out := new(AccountResponse)
+	if err := c.cc.Invoke(ctx, MethodUpdateAccount, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) ListAccounts(ctx context.Context, in *ListAccountsRequest, opts ...grpc.CallOption) (*ListAccountsResponse, error) {
+	out := new(ListAccountsResponse)
+	if err := c.cc.Invoke(ctx, MethodListAccounts, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	out := new(ListTransactionsResponse)
+	if err := c.cc.Invoke(ctx, MethodListTransactions, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
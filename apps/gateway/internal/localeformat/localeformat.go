@@ -0,0 +1,126 @@
+// Package localeformat renders an already-computed monetary amount for
+// display using a locale's thousands and decimal separators. It never
+// touches the arithmetic behind that amount -- see money and currency for
+// that -- so a display bug here can change how a balance looks but never
+// what it is.
+package localeformat
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/currency"
+)
+
+// separators is the thousands and decimal mark a locale formats with.
+type separators struct {
+	thousands string
+	decimal   string
+}
+
+// DefaultLocale is used when the requested locale has no entry in table.
+const DefaultLocale = "en-US"
+
+// table is embedded in the binary and covers the locales we've had actual
+// requests for. Unlike currency's table, there's no ISO standard to check
+// an unrecognized tag against, so Format falls back to DefaultLocale's
+// separators rather than guessing at one. Extend as new locales are
+// needed.
+var table = map[string]separators{
+	"en-US": {thousands: ",", decimal: "."},
+	"en-GB": {thousands: ",", decimal: "."},
+	"de-DE": {thousands: ".", decimal: ","},
+	"fr-FR": {thousands: " ", decimal: ","},
+	"ja-JP": {thousands: ",", decimal: "."},
+}
+
+// languageDefaults maps a bare language tag ("en") to the table entry used
+// when an Accept-Language value names only the language, not a region.
+var languageDefaults = map[string]string{
+	"en": "en-US",
+	"de": "de-DE",
+	"fr": "fr-FR",
+	"ja": "ja-JP",
+}
+
+// Supported reports whether locale has its own table entry, as opposed to
+// Format silently falling back to DefaultLocale.
+func Supported(locale string) bool {
+	_, ok := table[locale]
+	return ok
+}
+
+// Format parses amount (a canonical decimal string such as "10000.00")
+// and re-renders it at currencyCode's minor-unit precision (see
+// currency.MinorUnits), grouped and decimal-marked per locale. An
+// unrecognized locale falls back to DefaultLocale rather than erroring,
+// since a typo'd locale tag shouldn't be able to fail a display request.
+// It returns an error only when amount itself isn't a valid decimal
+// number.
+func Format(amount, currencyCode, locale string) (string, error) {
+	rat, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "", fmt.Errorf("localeformat: %q is not a valid decimal number", amount)
+	}
+	canonical := rat.FloatString(currency.MinorUnits(currencyCode))
+
+	sep, ok := table[locale]
+	if !ok {
+		sep = table[DefaultLocale]
+	}
+
+	negative := strings.HasPrefix(canonical, "-")
+	if negative {
+		canonical = canonical[1:]
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(canonical, ".")
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	b.WriteString(group(intPart, sep.thousands))
+	if hasFrac {
+		b.WriteString(sep.decimal)
+		b.WriteString(fracPart)
+	}
+	return b.String(), nil
+}
+
+// LocaleFromAcceptLanguage picks the first locale named in header (an
+// Accept-Language value) that resolves to a table entry, trying an exact
+// match ("en-US") before falling back to languageDefaults for a
+// language-only tag ("en"). It returns DefaultLocale if header is empty
+// or nothing in it resolves.
+func LocaleFromAcceptLanguage(header string) string {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])
+		if tag == "" {
+			continue
+		}
+		if Supported(tag) {
+			return tag
+		}
+		lang, _, _ := strings.Cut(tag, "-")
+		if locale, ok := languageDefaults[lang]; ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// group inserts sep every three digits from the right of digits.
+func group(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	var groups []string
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+	return strings.Join(groups, sep)
+}
@@ -0,0 +1,83 @@
+package localeformat
+
+import "testing"
+
+func TestFormat_GroupsThousandsPerLocale(t *testing.T) {
+	got, err := Format("1234567.89", "USD", "en-US")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "1,234,567.89" {
+		t.Fatalf("got %q, want %q", got, "1,234,567.89")
+	}
+}
+
+func TestFormat_SwapsSeparatorsForLocale(t *testing.T) {
+	got, err := Format("1234567.89", "EUR", "de-DE")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "1.234.567,89" {
+		t.Fatalf("got %q, want %q", got, "1.234.567,89")
+	}
+}
+
+func TestFormat_UnrecognizedLocaleFallsBackToDefault(t *testing.T) {
+	got, err := Format("1234.50", "USD", "xx-XX")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "1,234.50" {
+		t.Fatalf("got %q, want %q", got, "1,234.50")
+	}
+}
+
+func TestFormat_RespectsCurrencyMinorUnits(t *testing.T) {
+	got, err := Format("1234567", "JPY", "en-US")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "1,234,567" {
+		t.Fatalf("got %q, want %q", got, "1,234,567")
+	}
+}
+
+func TestFormat_NegativeAmountKeepsSignOutsideGrouping(t *testing.T) {
+	got, err := Format("-1234.50", "USD", "en-US")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "-1,234.50" {
+		t.Fatalf("got %q, want %q", got, "-1,234.50")
+	}
+}
+
+func TestFormat_InvalidAmountErrors(t *testing.T) {
+	if _, err := Format("not-a-number", "USD", "en-US"); err == nil {
+		t.Fatal("Format: want error for invalid amount")
+	}
+}
+
+func TestLocaleFromAcceptLanguage_ExactMatch(t *testing.T) {
+	if got := LocaleFromAcceptLanguage("fr-FR,en;q=0.8"); got != "fr-FR" {
+		t.Fatalf("got %q, want %q", got, "fr-FR")
+	}
+}
+
+func TestLocaleFromAcceptLanguage_LanguageOnlyFallsBackToLanguageDefault(t *testing.T) {
+	if got := LocaleFromAcceptLanguage("de;q=0.9"); got != "de-DE" {
+		t.Fatalf("got %q, want %q", got, "de-DE")
+	}
+}
+
+func TestLocaleFromAcceptLanguage_NoMatchFallsBackToDefault(t *testing.T) {
+	if got := LocaleFromAcceptLanguage("zh-CN,zh;q=0.9"); got != DefaultLocale {
+		t.Fatalf("got %q, want %q", got, DefaultLocale)
+	}
+}
+
+func TestLocaleFromAcceptLanguage_EmptyHeaderFallsBackToDefault(t *testing.T) {
+	if got := LocaleFromAcceptLanguage(""); got != DefaultLocale {
+		t.Fatalf("got %q, want %q", got, DefaultLocale)
+	}
+}
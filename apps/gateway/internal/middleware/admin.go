@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader carries the shared secret that authorizes admin-only
+// endpoints. There's no broader auth system in the gateway yet, so this is
+// deliberately the simplest thing that works: a single operator-held token,
+// not a per-admin credential.
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdmin rejects any request whose X-Admin-Token header doesn't
+// match token with 401, and rejects every request if token is empty (an
+// unconfigured admin token must fail closed, not grant free access).
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(AdminTokenHeader)), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": gin.H{
+					"code":    "UNAUTHORIZED",
+					"message": "admin token required",
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,378 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDHeader carries the caller's sub claim until real auth (JWT
+// verification, a session store, whatever comes first) is wired in. See
+// handler.userIDFromContext's fallback for what happens when this
+// middleware isn't enabled at all. Unlike the other claims Auth checks,
+// sub is never optional: Auth rejects a request that's missing it.
+//
+// There's no in-process token issuance to go with this -- no
+// AuthHandler, no dev-token endpoint -- the gateway only ever validates
+// headers an upstream IdP or edge proxy already set. A lifetime clamp on
+// a dev-token generator belongs there once one exists; there's nothing
+// in this service to attach it to yet.
+const UserIDHeader = "X-User-ID"
+
+// TokenAudHeader and TokenNbfHeader extend the same placeholder trust as
+// UserIDHeader and the X-Token-* headers in replay.go: the caller's token
+// audience and not-before claim, trusted outright pending real JWT
+// verification. TokenAudHeader is comma-separated, since a token may
+// legitimately carry more than one audience.
+const (
+	TokenAudHeader = "X-Token-Aud"
+	TokenNbfHeader = "X-Token-Nbf"
+)
+
+// claimHeaderPrefix is prepended to a name in AuthConfig.RequiredClaims to
+// form the header validateRequiredClaims reads it from -- the same
+// trust-the-header convention as UserIDHeader and the other Token*Header consts,
+// extended to an open-ended set of names instead of a fixed list, since
+// which extra claims a deployment needs (tenant_id, org, ...) varies by
+// environment.
+const claimHeaderPrefix = "X-Token-Claim-"
+
+// ClaimType is the value type AuthConfig.RequiredClaims expects a claim to
+// parse as. An unrecognized ClaimType is treated as ClaimTypeString,
+// i.e. any non-empty value is accepted.
+type ClaimType string
+
+const (
+	ClaimTypeString ClaimType = "string"
+	ClaimTypeInt    ClaimType = "int"
+	ClaimTypeBool   ClaimType = "bool"
+)
+
+// claimsContextKey is where Auth stores the validated RequiredClaims
+// values, read back via ClaimsFromContext.
+const claimsContextKey = "claims"
+
+// scopeContextKey is where Auth stores the scope string an Introspector
+// resolved for an opaque token, read back via ScopeFromContext. Only set
+// in AuthModeOpaque.
+const scopeContextKey = "scope"
+
+// AuthMode selects how Auth validates a request. The zero value is
+// AuthModeJWT.
+type AuthMode string
+
+const (
+	// AuthModeJWT is Auth's original behavior: trust the X-User-ID and
+	// X-Token-* headers an upstream IdP or edge proxy already set, the
+	// same placeholder trust UserIDHeader's doc comment describes.
+	AuthModeJWT AuthMode = "jwt"
+	// AuthModeOpaque validates the bearer token in the Authorization
+	// header against AuthConfig.Introspector instead of trusting headers,
+	// for callers that present opaque access tokens rather than JWTs.
+	AuthModeOpaque AuthMode = "opaque"
+)
+
+// Introspector resolves an opaque access token to whether it's active
+// and who it belongs to. introspection.Client is the concrete
+// implementation used in production, posting to an RFC 7662
+// introspection endpoint; Auth only depends on this interface so tests
+// can substitute a fake without spinning up an HTTP server.
+type Introspector interface {
+	Introspect(ctx context.Context, token string) (IntrospectionResult, error)
+}
+
+// IntrospectionResult is Introspector's resolution of a token, mirroring
+// introspection.Result.
+type IntrospectionResult struct {
+	Active  bool
+	Subject string
+	Scope   string
+}
+
+// AuthConfig configures Auth's validation of the token-derived headers.
+// The zero value preserves the gateway's original behavior beyond sub
+// (which Auth always requires): no audience restriction, no clock-skew
+// leeway on exp/nbf, and no claims required beyond sub, so a deployment
+// that never sets these fields is unaffected by any of those checks.
+type AuthConfig struct {
+	// Mode selects AuthModeJWT (the default, zero value) or
+	// AuthModeOpaque. Every other field below except Introspector is only
+	// consulted in AuthModeJWT.
+	Mode AuthMode
+	// Introspector validates the bearer token in AuthModeOpaque. Required
+	// when Mode is AuthModeOpaque; ignored otherwise.
+	Introspector Introspector
+
+	// AcceptedAudiences, if non-empty, requires TokenAudHeader to contain
+	// at least one of these values; a token with none of them (or no
+	// TokenAudHeader at all) is rejected with 401. Empty accepts any
+	// audience.
+	AcceptedAudiences []string
+	// ClockSkewLeeway widens exp/nbf validation by this much in the
+	// token's favor, so minor clock skew between the IdP and the gateway
+	// doesn't spuriously reject a token that's only "expired" or "not yet
+	// valid" by a few seconds.
+	ClockSkewLeeway time.Duration
+	// RequiredClaims names additional claims (beyond sub, which is always
+	// required) that must be present and parse as their given ClaimType.
+	// Keys are claim names, e.g. "tenant_id"; a claim named here is read
+	// from the header claimHeaderPrefix+name. This lets a deployment adopt
+	// a multi-tenancy claim like tenant_id or org purely through
+	// configuration. Empty requires nothing beyond sub.
+	RequiredClaims map[string]ClaimType
+}
+
+// Auth rejects a request missing UserIDHeader (sub) and sets the
+// "user_id" context key from it, which handlers read via
+// userIDFromContext. It's a placeholder for real authentication: it
+// trusts the headers outright rather than verifying a token, so it
+// belongs only behind a gateway that itself authenticates callers (e.g. an
+// internal network or an edge proxy that sets the headers after its own
+// auth check).
+//
+// It also enforces cfg's audience, clock-skew leeway, and required
+// claims, the same way replay.go's RequireSingleUseToken enforces
+// jti/exp: TokenExpHeader and TokenNbfHeader, if present, must be within
+// leeway of now; TokenAudHeader, if cfg.AcceptedAudiences is non-empty,
+// must contain one of them; and every name in cfg.RequiredClaims must
+// have a non-empty, correctly-typed header, rejected with a message
+// naming the specific claim that failed. exp, nbf, and aud being absent
+// from the headers is not itself a failure -- they're all optional JWT
+// claims -- so a deployment that doesn't set a given header is only ever
+// as strict as it already was before this check existed; sub and
+// cfg.RequiredClaims are the exception, since both are meant to be
+// mandatory once configured.
+//
+// On success, every value validated from cfg.RequiredClaims is stored in
+// context under "claims" (see ClaimsFromContext), the same way sub is
+// stored under "user_id".
+//
+// Every rejection also emits an auth_failure log line carrying a reason
+// code, the caller's IP, the request ID, and the route -- never the
+// header values themselves, since those stand in for token contents --
+// so a SIEM watching the gateway's logs can distinguish an expired token
+// from a forged audience without the 401 body (deliberately vague)
+// giving that away to the caller.
+//
+// In AuthConfig.Mode AuthModeOpaque, none of the above applies: Auth
+// instead validates the Authorization bearer token via cfg.Introspector
+// (see authOpaque).
+func Auth(cfg AuthConfig) gin.HandlerFunc {
+	if cfg.Mode == AuthModeOpaque {
+		return authOpaque(cfg)
+	}
+	return func(c *gin.Context) {
+		userID := c.GetHeader(UserIDHeader)
+		if userID == "" {
+			logAuthFailure(c, "missing_sub")
+			unauthorized(c, "MISSING_CLAIM", "required claim missing: sub")
+			return
+		}
+		c.Set("user_id", userID)
+
+		claims, name, reason := validateRequiredClaims(c, cfg.RequiredClaims)
+		if reason != "" {
+			logAuthFailure(c, requiredClaimFailureReason(reason))
+			unauthorized(c, "MISSING_CLAIM", fmt.Sprintf("required claim %q %s", name, reason))
+			return
+		}
+		c.Set(claimsContextKey, claims)
+
+		if len(cfg.AcceptedAudiences) > 0 && !audienceAccepted(c.GetHeader(TokenAudHeader), cfg.AcceptedAudiences) {
+			logAuthFailure(c, "invalid_audience")
+			unauthorized(c, "INVALID_AUDIENCE", "token audience not accepted by this deployment")
+			return
+		}
+
+		if ok, reason := withinTimeClaims(c.GetHeader(TokenExpHeader), c.GetHeader(TokenNbfHeader), cfg.ClockSkewLeeway); !ok {
+			logAuthFailure(c, reason)
+			unauthorized(c, "TOKEN_TIME_INVALID", "token is expired or not yet valid")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>"
+// header, or "" if the header is missing or doesn't use the Bearer
+// scheme.
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// authOpaque is Auth's AuthModeOpaque implementation: it validates the
+// Authorization bearer token against cfg.Introspector instead of
+// trusting headers the way AuthModeJWT does. On success it stores the
+// resolved subject under "user_id" (so handler.userIDFromContext works
+// exactly as it does for AuthModeJWT) and the resolved scope under
+// "scope" (see ScopeFromContext).
+func authOpaque(cfg AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			logAuthFailure(c, "missing_bearer_token")
+			unauthorized(c, "MISSING_CLAIM", "required bearer token missing")
+			return
+		}
+
+		result, err := cfg.Introspector.Introspect(c.Request.Context(), token)
+		if err != nil {
+			logAuthFailure(c, "introspection_unavailable")
+			unauthorized(c, "INTROSPECTION_UNAVAILABLE", "token introspection temporarily unavailable")
+			return
+		}
+		if !result.Active {
+			logAuthFailure(c, "token_inactive")
+			unauthorized(c, "TOKEN_INACTIVE", "token is not active")
+			return
+		}
+
+		c.Set("user_id", result.Subject)
+		c.Set(scopeContextKey, result.Scope)
+		c.Next()
+	}
+}
+
+// ScopeFromContext returns the scope string Auth resolved for the
+// caller's opaque token in AuthModeOpaque (a space-separated list, per
+// RFC 7662), or "" if Auth wasn't registered, ran in AuthModeJWT, or the
+// introspection result carried no scope.
+func ScopeFromContext(c *gin.Context) string {
+	if v, ok := c.Get(scopeContextKey); ok {
+		if scope, ok := v.(string); ok {
+			return scope
+		}
+	}
+	return ""
+}
+
+// logAuthFailure emits the auth_failure security log line Auth's doc
+// comment describes. It's deliberately the only place that reads
+// c.ClientIP() and c.FullPath() for this purpose, so every rejection
+// branch logs the same shape of line.
+func logAuthFailure(c *gin.Context, reason string) {
+	log.Printf("auth_failure reason=%s request_id=%s client_ip=%s route=%q", reason, RequestIDFromContext(c), c.ClientIP(), c.Request.Method+" "+c.FullPath())
+}
+
+// requiredClaimFailureReason maps validateRequiredClaims' human-readable
+// failure reason to a stable auth_failure reason code, without exposing
+// the claim's name or value: "missing_claim" when the header wasn't
+// set at all, "invalid_claim_format" when it was set but didn't parse
+// as its ClaimType.
+func requiredClaimFailureReason(reason string) string {
+	if strings.Contains(reason, "missing") {
+		return "missing_claim"
+	}
+	return "invalid_claim_format"
+}
+
+// ClaimsFromContext returns the claim values Auth validated against
+// AuthConfig.RequiredClaims, keyed by claim name, or nil if Auth wasn't
+// registered or cfg.RequiredClaims was empty.
+func ClaimsFromContext(c *gin.Context) map[string]string {
+	if v, ok := c.Get(claimsContextKey); ok {
+		if claims, ok := v.(map[string]string); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// validateRequiredClaims reads and type-checks every claim named in
+// required from its header (claimHeaderPrefix+name). It returns the
+// validated values on success, or a zero claims map plus the name and
+// failure reason ("is required but missing" / "does not parse as <type>")
+// of the first claim that isn't satisfied. Iteration order over required
+// is unspecified, so which claim is reported first when several fail is
+// not guaranteed -- any one of them is a legitimate rejection.
+func validateRequiredClaims(c *gin.Context, required map[string]ClaimType) (claims map[string]string, failedName, failureReason string) {
+	if len(required) == 0 {
+		return nil, "", ""
+	}
+	claims = make(map[string]string, len(required))
+	for name, claimType := range required {
+		value := c.GetHeader(claimHeaderPrefix + name)
+		if value == "" {
+			return nil, name, "is required but missing"
+		}
+		if !claimMatchesType(value, claimType) {
+			return nil, name, fmt.Sprintf("does not parse as %s", claimType)
+		}
+		claims[name] = value
+	}
+	return claims, "", ""
+}
+
+// claimMatchesType reports whether value parses as claimType. An
+// unrecognized claimType (including the zero value) falls back to
+// ClaimTypeString, which accepts any non-empty value.
+func claimMatchesType(value string, claimType ClaimType) bool {
+	switch claimType {
+	case ClaimTypeInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case ClaimTypeBool:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// audienceAccepted reports whether audHeader (TokenAudHeader's
+// comma-separated value) contains at least one of accepted.
+func audienceAccepted(audHeader string, accepted []string) bool {
+	if audHeader == "" {
+		return false
+	}
+	tokenAudiences := strings.Split(audHeader, ",")
+	for _, want := range accepted {
+		for _, got := range tokenAudiences {
+			if strings.TrimSpace(got) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withinTimeClaims reports whether exp/nbf (Unix-second header values,
+// either of which may be empty) hold as of now, widened by leeway in the
+// token's favor. When ok is false, reason names which claim failed
+// ("token_expired" or "token_not_yet_valid"), for logAuthFailure; a
+// malformed header is attributed to whichever claim it belongs to.
+func withinTimeClaims(expHeader, nbfHeader string, leeway time.Duration) (ok bool, reason string) {
+	now := time.Now()
+	if expHeader != "" {
+		exp, err := parseUnixSeconds(expHeader)
+		if err != nil || now.After(exp.Add(leeway)) {
+			return false, "token_expired"
+		}
+	}
+	if nbfHeader != "" {
+		nbf, err := parseUnixSeconds(nbfHeader)
+		if err != nil || now.Before(nbf.Add(-leeway)) {
+			return false, "token_not_yet_valid"
+		}
+	}
+	return true, ""
+}
+
+func parseUnixSeconds(v string) (time.Time, error) {
+	unix, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
@@ -0,0 +1,455 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubIntrospector is a fake Introspector for AuthModeOpaque tests, so
+// they don't need a real HTTP introspection endpoint -- introspection.Client
+// itself is tested against one in the introspection package.
+type stubIntrospector struct {
+	result IntrospectionResult
+	err    error
+}
+
+func (s stubIntrospector) Introspect(ctx context.Context, token string) (IntrospectionResult, error) {
+	return s.result, s.err
+}
+
+func newAuthTestRouter(cfg AuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Auth(cfg))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestAuth_MissingSubReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_SubPresentWithNoOtherClaimsPasses(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_ExpiredTokenWithoutLeewayReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenExpHeader, strconv.FormatInt(time.Now().Add(-5*time.Second).Unix(), 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_RecentlyExpiredTokenWithinLeewayPasses(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{ClockSkewLeeway: 30 * time.Second})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenExpHeader, strconv.FormatInt(time.Now().Add(-5*time.Second).Unix(), 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_NotYetValidTokenWithoutLeewayReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenNbfHeader, strconv.FormatInt(time.Now().Add(5*time.Second).Unix(), 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_NotYetValidTokenWithinLeewayPasses(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{ClockSkewLeeway: 30 * time.Second})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenNbfHeader, strconv.FormatInt(time.Now().Add(5*time.Second).Unix(), 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_NoAudienceRestrictionAcceptsAnyToken(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_MatchingAudiencePasses(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{AcceptedAudiences: []string{"ledger-gateway", "admin-console"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenAudHeader, "some-other-service, ledger-gateway")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_NonMatchingAudienceReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{AcceptedAudiences: []string{"ledger-gateway"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenAudHeader, "some-other-service")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_MissingAudienceHeaderWithRestrictionReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{AcceptedAudiences: []string{"ledger-gateway"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_MissingRequiredClaimReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{RequiredClaims: map[string]ClaimType{"tenant_id": ClaimTypeString}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_RequiredClaimWrongTypeReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{RequiredClaims: map[string]ClaimType{"tier": ClaimTypeInt}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(claimHeaderPrefix+"tier", "not-an-int")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_RequiredClaimsSatisfiedStoresClaimsInContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Auth(AuthConfig{RequiredClaims: map[string]ClaimType{"tenant_id": ClaimTypeString, "tier": ClaimTypeInt}}))
+
+	var got map[string]string
+	router.GET("/ping", func(c *gin.Context) {
+		got = ClaimsFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(claimHeaderPrefix+"tenant_id", "acme")
+	req.Header.Set(claimHeaderPrefix+"tier", "2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got["tenant_id"] != "acme" || got["tier"] != "2" {
+		t.Fatalf("claims = %+v, want tenant_id=acme tier=2", got)
+	}
+}
+
+func TestClaimsFromContext_NotRegisteredReturnsNil(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if got := ClaimsFromContext(c); got != nil {
+		t.Fatalf("ClaimsFromContext = %+v, want nil", got)
+	}
+}
+
+// captureAuthFailureLog redirects the standard logger's output for the
+// duration of fn, returning whatever was written to it. Auth logs via
+// the plain top-level log package, the same as replay.go's redis-error
+// line, rather than an injected writer.
+func captureAuthFailureLog(fn func()) string {
+	var out bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&out)
+	defer log.SetOutput(orig)
+	fn()
+	return out.String()
+}
+
+func TestAuth_MissingSubLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=missing_sub") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=missing_sub", logOutput)
+	}
+}
+
+func TestAuth_MissingRequiredClaimLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{RequiredClaims: map[string]ClaimType{"tenant_id": ClaimTypeString}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=missing_claim") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=missing_claim", logOutput)
+	}
+}
+
+func TestAuth_RequiredClaimWrongTypeLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{RequiredClaims: map[string]ClaimType{"tier": ClaimTypeInt}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(claimHeaderPrefix+"tier", "not-an-int")
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=invalid_claim_format") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=invalid_claim_format", logOutput)
+	}
+}
+
+func TestAuth_NonMatchingAudienceLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{AcceptedAudiences: []string{"ledger-gateway"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenAudHeader, "some-other-service")
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=invalid_audience") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=invalid_audience", logOutput)
+	}
+}
+
+func TestAuth_ExpiredTokenLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenExpHeader, strconv.FormatInt(time.Now().Add(-5*time.Second).Unix(), 10))
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=token_expired") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=token_expired", logOutput)
+	}
+}
+
+func TestAuth_NotYetValidTokenLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(UserIDHeader, "demo-user")
+	req.Header.Set(TokenNbfHeader, strconv.FormatInt(time.Now().Add(5*time.Second).Unix(), 10))
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=token_not_yet_valid") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=token_not_yet_valid", logOutput)
+	}
+}
+
+func TestAuth_OpaqueModeMissingBearerTokenReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{Mode: AuthModeOpaque, Introspector: stubIntrospector{}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_OpaqueModeIntrospectionErrorReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{Mode: AuthModeOpaque, Introspector: stubIntrospector{err: errors.New("endpoint unreachable")}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", "Bearer opaque-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_OpaqueModeInactiveTokenReturns401(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{Mode: AuthModeOpaque, Introspector: stubIntrospector{result: IntrospectionResult{Active: false}}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", "Bearer opaque-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuth_OpaqueModeActiveTokenSetsUserIDAndScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Auth(AuthConfig{
+		Mode:         AuthModeOpaque,
+		Introspector: stubIntrospector{result: IntrospectionResult{Active: true, Subject: "demo-user", Scope: "transactions:read"}},
+	}))
+
+	var gotScope string
+	router.GET("/ping", func(c *gin.Context) {
+		gotScope = ScopeFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", "Bearer opaque-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if gotScope != "transactions:read" {
+		t.Fatalf("scope = %q, want %q", gotScope, "transactions:read")
+	}
+}
+
+func TestAuth_OpaqueModeFailureLogsReasonCode(t *testing.T) {
+	router := newAuthTestRouter(AuthConfig{Mode: AuthModeOpaque, Introspector: stubIntrospector{result: IntrospectionResult{Active: false}}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", "Bearer opaque-token")
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "auth_failure reason=token_inactive") {
+		t.Fatalf("log output = %q, want it to contain auth_failure reason=token_inactive", logOutput)
+	}
+}
+
+func TestScopeFromContext_NotRegisteredReturnsEmpty(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if got := ScopeFromContext(c); got != "" {
+		t.Fatalf("ScopeFromContext = %q, want empty", got)
+	}
+}
+
+func TestAuth_FailureLogIncludesRequestIDClientIPAndRouteButNotHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(Auth(AuthConfig{}))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = "203.0.113.7:12345"
+	w := httptest.NewRecorder()
+
+	logOutput := captureAuthFailureLog(func() {
+		router.ServeHTTP(w, req)
+	})
+
+	if !strings.Contains(logOutput, "request_id=") || !strings.Contains(logOutput, "client_ip=203.0.113.7") || !strings.Contains(logOutput, `route="GET /ping"`) {
+		t.Fatalf("log output = %q, missing expected fields", logOutput)
+	}
+}
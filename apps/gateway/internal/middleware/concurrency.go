@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var concurrencyLimitInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "gateway_concurrency_limit_in_flight",
+	Help: "Current number of requests admitted by ConcurrencyLimit.",
+})
+
+// concurrencyLimitRetryAfterSeconds is a fixed, deliberately short backoff
+// hint: the ceiling this middleware enforces is sized for memory
+// exhaustion, not sustained overload, so a caller that waits a second and
+// retries is the expected recovery path rather than a client doing real
+// exponential backoff.
+const concurrencyLimitRetryAfterSeconds = "1"
+
+// ConcurrencyLimit rejects a request with 503 (and Retry-After) once max
+// requests are already in flight, using a buffered-channel semaphore the
+// same way ledgerclient.WithBulkhead caps concurrent calls to ledger-core
+// -- except here rejection is immediate rather than waiting for a queue
+// timeout, since the point is protecting the process from a flood, not
+// smoothing a burst. A non-positive max disables the limit entirely (every
+// request is admitted unconditionally), so a deployment can list
+// "concurrency_limit" in config.Config.Middleware without committing to a
+// ceiling.
+//
+// The slot is released via defer, so it's freed even when a downstream
+// handler panics -- the deferred release runs during the panic's stack
+// unwind, before Recovery's own deferred recover (further up the call
+// stack, since Recovery is always registered first) turns it into a 500.
+//
+// /healthz, /readyz, and /metrics are exempt: a flood severe enough to
+// trip this limit is exactly when an operator or a readiness probe most
+// needs those to keep responding.
+func ConcurrencyLimit(max int) gin.HandlerFunc {
+	if max <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	sem := make(chan struct{}, max)
+	return func(c *gin.Context) {
+		switch c.Request.URL.Path {
+		case "/healthz", "/readyz", "/metrics":
+			c.Next()
+			return
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			c.Header("Retry-After", concurrencyLimitRetryAfterSeconds)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": gin.H{
+					"code":    "CONCURRENCY_LIMIT_EXCEEDED",
+					"message": "server is at its concurrent request limit, retry shortly",
+				},
+			})
+			return
+		}
+		concurrencyLimitInFlight.Inc()
+		defer func() {
+			<-sem
+			concurrencyLimitInFlight.Dec()
+		}()
+
+		c.Next()
+	}
+}
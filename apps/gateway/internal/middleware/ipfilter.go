@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPAllowlist gates a route group to callers whose IP matches one of a
+// fixed set of CIDRs, for internal-only endpoints (currently /debug/pprof)
+// that shouldn't rely on bearer-token auth alone.
+type IPAllowlist struct {
+	cidrs []*net.IPNet
+}
+
+// NewIPAllowlist parses cidrs into an IPAllowlist. It returns an error if
+// any entry fails to parse.
+func NewIPAllowlist(cidrs []string) (IPAllowlist, error) {
+	a := IPAllowlist{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return IPAllowlist{}, fmt.Errorf("middleware: parse CIDR %q: %w", cidr, err)
+		}
+		a.cidrs = append(a.cidrs, n)
+	}
+	return a, nil
+}
+
+// RequireAllowedIP rejects any request whose client IP doesn't match one
+// of a's CIDRs with 403. An empty allowlist rejects every caller, so an
+// unconfigured allowlist fails closed rather than granting free access.
+func (a IPAllowlist) RequireAllowedIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		allowed := false
+		if ip != nil {
+			for _, n := range a.cidrs {
+				if n.Contains(ip) {
+					allowed = true
+					break
+				}
+			}
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": gin.H{
+					"code":    "FORBIDDEN",
+					"message": "caller IP is not allowed",
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/redact"
+)
+
+// clientClosedRequestStatus is logged in place of the real response status
+// when the client disconnected before a response was sent, following the
+// same convention as nginx's 499.
+const clientClosedRequestStatus = 499
+
+// Logging emits one access-log line per request with method, path, status,
+// and latency, written to out (stdout, or a rotating file via the
+// accesslog package). It should be registered after Recovery so panics
+// still get logged with their resulting status, and after RequestID so
+// the slow-request warning below can include the request ID.
+//
+// Logging also stashes a RequestLogger in context for handlers to use via
+// RequestLoggerFromContext, so application log lines get the same
+// request_id/user_id/route tags as the access log line without every
+// handler formatting them by hand.
+//
+// The line also carries upstream_latency_ms, the summed duration of every
+// gRPC call the handler made to ledger-core (via
+// ledgerclient.ContextWithUpstreamLatencyTracking/metricsUnaryInterceptor),
+// so a slow request can be attributed to the gateway or to ledger-core
+// without cross-referencing traces.
+//
+// When a request's latency exceeds slowThreshold, Logging also emits a
+// second, WARN-level line so latency regressions show up before they erode
+// p99 enough to page anyone. A non-positive slowThreshold disables this.
+// The check is a single comparison on the already-computed latency, so it
+// costs nothing extra on the fast path.
+//
+// When logHeaders is true, Logging also emits a DEBUG-level line with the
+// request's headers, passed through redactor first so credentials (e.g.
+// Authorization) never reach the log. This exists ahead of any real
+// request-body debug logging specifically so that capability is redaction-
+// safe from the day it's turned on, not retrofitted afterward.
+func Logging(slowThreshold time.Duration, out io.Writer, redactor redact.Redactor, logHeaders bool) gin.HandlerFunc {
+	logger := log.New(out, "", log.LstdFlags)
+
+	return func(c *gin.Context) {
+		c.Set(requestLoggerContextKey, &RequestLogger{c: c})
+
+		if logHeaders {
+			logger.Printf("DEBUG: request headers request_id=%s method=%s path=%s headers=%v",
+				RequestIDFromContext(c), c.Request.Method, c.Request.URL.Path, redactor.Headers(c.Request.Header))
+		}
+
+		c.Request = c.Request.WithContext(ledgerclient.ContextWithUpstreamLatencyTracking(c.Request.Context()))
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+		upstreamLatency := ledgerclient.UpstreamLatencyFromContext(c.Request.Context())
+
+		if c.Request.Context().Err() == context.Canceled {
+			// The client disconnected mid-flight (a browser navigation, a
+			// canceled fetch, ...); this isn't a server problem, so log it
+			// at a lower severity than a normal access line, and as a 499
+			// rather than whatever status happened to be set when the
+			// handler gave up.
+			logger.Printf("INFO: client disconnected method=%s path=%s status=%d latency_ms=%d upstream_latency_ms=%d",
+				c.Request.Method, c.Request.URL.Path, clientClosedRequestStatus, latency.Milliseconds(), upstreamLatency.Milliseconds())
+			return
+		}
+
+		logger.Printf("method=%s path=%s status=%d latency_ms=%d upstream_latency_ms=%d",
+			c.Request.Method, c.Request.URL.Path, c.Writer.Status(), latency.Milliseconds(), upstreamLatency.Milliseconds())
+
+		if slowThreshold > 0 && latency > slowThreshold {
+			logger.Printf("WARN: slow request request_id=%s method=%s path=%s latency_ms=%d upstream_latency_ms=%d threshold_ms=%d",
+				RequestIDFromContext(c), c.Request.Method, c.Request.URL.Path, latency.Milliseconds(), upstreamLatency.Milliseconds(), slowThreshold.Milliseconds())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/redact"
+)
+
+func TestLogging_RedactsAuthorizationHeader(t *testing.T) {
+	const token = "Bearer super-secret-token-value"
+
+	var logOutput bytes.Buffer
+	redactor := redact.New(redact.DefaultConfig())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Logging(0, &logOutput, redactor, true))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if strings.Contains(logOutput.String(), "super-secret-token-value") {
+		t.Fatalf("log output contains the raw Authorization token: %s", logOutput.String())
+	}
+	if !strings.Contains(logOutput.String(), "[REDACTED]") {
+		t.Fatalf("log output missing redaction marker: %s", logOutput.String())
+	}
+}
+
+func TestLogging_EmitsZeroUpstreamLatencyWhenNoGRPCCallsMade(t *testing.T) {
+	var logOutput bytes.Buffer
+	redactor := redact.New(redact.DefaultConfig())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Logging(0, &logOutput, redactor, false))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !strings.Contains(logOutput.String(), "upstream_latency_ms=0") {
+		t.Fatalf("log output missing upstream_latency_ms=0: %s", logOutput.String())
+	}
+}
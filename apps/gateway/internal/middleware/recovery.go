@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/redact"
+)
+
+// Recovery converts a panic in a downstream handler into a 500 response
+// instead of crashing the process. It must be registered before any other
+// middleware so it can catch panics from them too.
+//
+// Panic logs include the request's headers (redacted via redactor), since
+// a panic is exactly when someone debugging it is tempted to dump the
+// whole request -- this ensures that never includes a credential even if
+// whatever panicked was triggered by handling the request itself.
+func Recovery(redactor redact.Redactor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if c.Request.Context().Err() == context.Canceled {
+					// The client is already gone; writing a 500 body would
+					// either fail or just add noise to logs that should be
+					// reserved for real server errors.
+					log.Printf("panic recovered after client disconnect: %v request_id=%s headers=%v",
+						rec, RequestIDFromContext(c), redactor.Headers(c.Request.Header))
+					c.Abort()
+					return
+				}
+				log.Printf("panic recovered: %v request_id=%s headers=%v",
+					rec, RequestIDFromContext(c), redactor.Headers(c.Request.Header))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": gin.H{
+						"code":    "INTERNAL",
+						"message": "internal server error",
+					},
+				})
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenJTIHeader and TokenExpHeader carry a token's jti (a unique ID minted
+// per-issue) and exp (Unix seconds) claims the same way UserIDHeader
+// carries identity: trusted outright rather than cryptographically
+// verified, pending real JWT verification being wired into Auth. They're
+// only read by RequireSingleUseToken, so a deployment that never sets them
+// simply never satisfies it.
+const (
+	TokenJTIHeader = "X-Token-Jti"
+	TokenExpHeader = "X-Token-Exp"
+)
+
+// ReplayProtection rejects a second write made with the same jti within
+// that token's remaining lifetime, for routes that opt into
+// RequireSingleUseToken. It's a different, narrower guarantee than
+// idempotency.Store: idempotency lets a client safely retry the same
+// logical write (same idempotency_key) as many times as it wants,
+// including with a different token after a refresh, and returns the
+// cached result rather than erroring. ReplayProtection instead catches
+// literal token reuse -- the same jti presented twice -- which matters for
+// operations where a captured token being replayed is itself the threat,
+// independent of whether the replayed request happens to carry a
+// previously-used idempotency key. The two are meant to run together, not
+// as alternatives: a legitimate retry (same idempotency_key, same or
+// different jti) is unaffected by ReplayProtection as long as it reuses
+// the same jti at most once; an attacker who captured a token and resends
+// the exact same request is caught by ReplayProtection even before
+// idempotency is checked.
+type ReplayProtection struct {
+	redis *redis.Client
+}
+
+// NewReplayProtection returns a ReplayProtection backed by the Redis
+// instance at addr.
+func NewReplayProtection(addr string) *ReplayProtection {
+	return &ReplayProtection{redis: redis.NewClient(&redis.Options{Addr: addr})}
+}
+
+// RequireSingleUseToken rejects a request with 401 if it's missing
+// TokenJTIHeader or TokenExpHeader, if TokenExpHeader isn't a valid future
+// Unix timestamp, or if this jti has already been recorded by a previous
+// call to a RequireSingleUseToken route sharing this ReplayProtection. A
+// first use records the jti in Redis with a TTL equal to the token's
+// remaining lifetime, so the record disappears on its own once the token
+// itself would no longer be valid.
+//
+// This is meant to be registered on specific routes flagged single-use,
+// not as global middleware -- most routes only need idempotency, and
+// requiring a jti claim from every caller would break any deployment whose
+// auth layer doesn't mint one yet.
+func (r *ReplayProtection) RequireSingleUseToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jti := c.GetHeader(TokenJTIHeader)
+		if jti == "" {
+			unauthorized(c, "JTI_REQUIRED", "this operation requires a single-use token with a jti claim")
+			return
+		}
+
+		ttl, err := tokenRemainingLifetime(c.GetHeader(TokenExpHeader))
+		if err != nil || ttl <= 0 {
+			unauthorized(c, "TOKEN_EXPIRED", "token is missing a valid, unexpired exp claim")
+			return
+		}
+
+		ok, err := r.redis.SetNX(c.Request.Context(), "replay:jti:"+jti, "1", ttl).Result()
+		if err != nil {
+			log.Printf("replay protection: redis error request_id=%s err=%v", RequestIDFromContext(c), err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": gin.H{
+					"code":    "REPLAY_PROTECTION_UNAVAILABLE",
+					"message": "replay protection temporarily unavailable",
+				},
+			})
+			return
+		}
+		if !ok {
+			unauthorized(c, "TOKEN_REPLAYED", "this token has already been used")
+			return
+		}
+		c.Next()
+	}
+}
+
+// tokenRemainingLifetime parses exp as a Unix timestamp and returns how
+// far in the future it is.
+func tokenRemainingLifetime(exp string) (time.Duration, error) {
+	if exp == "" {
+		return 0, fmt.Errorf("middleware: token exp claim required")
+	}
+	parsed, err := parseUnixSeconds(exp)
+	if err != nil {
+		return 0, fmt.Errorf("middleware: invalid token exp claim %q: %w", exp, err)
+	}
+	return time.Until(parsed), nil
+}
+
+func unauthorized(c *gin.Context, code, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newReplayTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	rp := NewReplayProtection("127.0.0.1:0")
+	router.POST("/v1/transactions", rp.RequireSingleUseToken(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func TestRequireSingleUseToken_MissingJTIReturns401(t *testing.T) {
+	router := newReplayTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", nil)
+	req.Header.Set(TokenExpHeader, "9999999999")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d; body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+}
+
+func TestRequireSingleUseToken_ExpiredTokenReturns401(t *testing.T) {
+	router := newReplayTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", nil)
+	req.Header.Set(TokenJTIHeader, "jti-1")
+	req.Header.Set(TokenExpHeader, "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d; body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+}
+
+func TestRequireSingleUseToken_MalformedExpReturns401(t *testing.T) {
+	router := newReplayTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/transactions", nil)
+	req.Header.Set(TokenJTIHeader, "jti-1")
+	req.Header.Set(TokenExpHeader, "not-a-timestamp")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d; body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+}
+
+func TestTokenRemainingLifetime_ValidFutureExp(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	ttl, err := tokenRemainingLifetime(strconv.FormatInt(exp, 10))
+	if err != nil {
+		t.Fatalf("tokenRemainingLifetime: %v", err)
+	}
+	if ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("ttl = %v, want a positive duration close to 1h", ttl)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is both read (to honor an ID set by an upstream proxy)
+// and written (so the caller can correlate logs) by RequestID.
+const RequestIDHeader = "X-Request-Id"
+
+const requestIDContextKey = "request_id"
+
+// RequestID assigns each request a unique ID, reusing an inbound
+// X-Request-Id header if one is already set, so the ID stays stable across
+// hops instead of getting reassigned at every proxy. It should be
+// registered before Logging so the ID is available to the access log.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFromContext returns the ID set by RequestID, or "" if it wasn't
+// registered.
+func RequestIDFromContext(c *gin.Context) string {
+	if v, ok := c.Get(requestIDContextKey); ok {
+		if id, ok := v.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const requestLoggerContextKey = "request_logger"
+
+// RequestLogger emits log lines tagged with the request ID, user ID, and
+// route of the request that created it, so handlers don't each have to
+// format that correlation boilerplate by hand -- including around calls
+// made through the ledgerclient layer, where a "calling CreateTransaction"
+// / "CreateTransaction failed: %v" pair of lines is exactly the kind of
+// thing that's useless without a request ID to tie it back to an access
+// log line.
+//
+// Tags are read from the underlying *gin.Context at each Printf call
+// rather than captured once, since Logging (which stashes a RequestLogger
+// in context) runs before Auth sets user_id in the default middleware
+// chain -- by the time a handler calls Logger(c), Auth has already run,
+// so the tag is there.
+//
+// The zero value is a no-op logger that falls back to a plain,
+// untagged log.Printf, so RequestLoggerFromContext never needs to return
+// nil and callers never need a nil check.
+type RequestLogger struct {
+	c *gin.Context
+}
+
+// Printf formats and logs a line the same way log.Printf does, prefixed
+// with request_id, user_id, and route tags when this RequestLogger was
+// stashed by Logging. Without that (the zero value, or the fallback
+// RequestLoggerFromContext returns when Logging wasn't registered), it
+// logs the line untagged.
+func (r *RequestLogger) Printf(format string, args ...interface{}) {
+	if r == nil || r.c == nil {
+		log.Printf(format, args...)
+		return
+	}
+	log.Printf("request_id=%s user_id=%s route=%q "+format,
+		append([]interface{}{RequestIDFromContext(r.c), userIDForLog(r.c), r.c.Request.Method + " " + r.c.FullPath()}, args...)...)
+}
+
+// userIDForLog mirrors handler.userIDFromContext's context-key read
+// without importing handler, the same way userIDFromContext itself reads
+// the key middleware.Auth sets without importing middleware.
+func userIDForLog(c *gin.Context) string {
+	if v, ok := c.Get("user_id"); ok {
+		if userID, ok := v.(string); ok {
+			return userID
+		}
+	}
+	return ""
+}
+
+// RequestLoggerFromContext returns the RequestLogger Logging stashed in c,
+// or a no-op RequestLogger if Logging wasn't registered -- e.g. in a
+// handler unit test that builds its own gin.Context without the full
+// middleware chain.
+func RequestLoggerFromContext(c *gin.Context) *RequestLogger {
+	if v, ok := c.Get(requestLoggerContextKey); ok {
+		if logger, ok := v.(*RequestLogger); ok {
+			return logger
+		}
+	}
+	return &RequestLogger{}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/redact"
+)
+
+func TestRequestLogger_TagsLinesWithRequestIDAndRouteAndLateUserID(t *testing.T) {
+	var logOutput bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(orig)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(Logging(0, &bytes.Buffer{}, redact.New(redact.DefaultConfig()), false))
+	router.Use(func(c *gin.Context) {
+		// Simulates Auth, which runs after Logging in the default chain,
+		// setting user_id only after RequestLogger was already stashed.
+		c.Set("user_id", "user-123")
+		c.Next()
+	})
+	router.GET("/accounts/:id", func(c *gin.Context) {
+		RequestLoggerFromContext(c).Printf("looked up account %s", c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/42", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	out := logOutput.String()
+	if !strings.Contains(out, "user_id=user-123") {
+		t.Fatalf("log output missing late-set user_id tag: %s", out)
+	}
+	if !strings.Contains(out, `route="GET /accounts/:id"`) {
+		t.Fatalf("log output missing route tag: %s", out)
+	}
+	if !strings.Contains(out, "looked up account 42") {
+		t.Fatalf("log output missing the logged message: %s", out)
+	}
+}
+
+func TestRequestLoggerFromContext_NoOpWhenLoggingNotRegistered(t *testing.T) {
+	var logOutput bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(orig)
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/ping", nil)
+
+	RequestLoggerFromContext(c).Printf("no middleware chain here")
+
+	if !strings.Contains(logOutput.String(), "no middleware chain here") {
+		t.Fatalf("expected the no-op logger to still log the message, got: %s", logOutput.String())
+	}
+	if strings.Contains(logOutput.String(), "request_id=") {
+		t.Fatalf("expected no tags from the no-op logger, got: %s", logOutput.String())
+	}
+}
+
+func TestRequestLogger_NilReceiverDoesNotPanic(t *testing.T) {
+	var logger *RequestLogger
+	logger.Printf("still works")
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisableWriteTimeout clears http.Server's WriteTimeout for this request
+// via http.ResponseController, so a long-lived streaming response (SSE,
+// WebSocket) isn't cut off partway through by the deadline every other
+// route keeps. The zero time.Time passed to SetWriteDeadline means "no
+// deadline", the same as never setting one at all.
+//
+// This has to happen per request rather than by lowering WriteTimeout on
+// the server as a whole, since that timeout applies to every route; a
+// handler that isn't streaming still wants to be killed if it hangs.
+func DisableWriteTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := http.NewResponseController(c.Writer)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			// Falls back to whatever deadline the server already set
+			// (e.g. the underlying ResponseWriter doesn't support
+			// per-request deadlines), which is the same behavior this
+			// middleware didn't exist at all.
+			log.Printf("middleware: disable write timeout: %v", err)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+// Package money provides exact-decimal summation for monetary amounts, so
+// callers summing balances or transaction amounts across many accounts
+// never do it with float64, which drifts for values that have no exact
+// binary representation (0.10 + 0.20 != 0.30 in float64 but sums exactly
+// as a rational). It's built on math/big.Rat, which represents any finite
+// decimal string exactly.
+package money
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/currency"
+)
+
+// Totals accumulates exact decimal sums per currency code and formats
+// each to that currency's minor-unit precision (see currency.MinorUnits)
+// once accumulation is done. The zero value is ready to use.
+type Totals struct {
+	sums map[string]*big.Rat
+}
+
+// Add parses amount as a decimal string and adds it to currencyCode's
+// running total. It returns an error if amount isn't a valid decimal
+// number, rather than silently skipping it, so a caller that wants to
+// tolerate malformed upstream values (e.g. one bad balance among many
+// accounts) does so explicitly at the call site instead of money masking
+// the problem.
+func (t *Totals) Add(currencyCode, amount string) error {
+	rat, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return fmt.Errorf("money: %q is not a valid decimal number", amount)
+	}
+	if t.sums == nil {
+		t.sums = make(map[string]*big.Rat)
+	}
+	if t.sums[currencyCode] == nil {
+		t.sums[currencyCode] = new(big.Rat)
+	}
+	t.sums[currencyCode].Add(t.sums[currencyCode], rat)
+	return nil
+}
+
+// Format returns every currency's running total as a decimal string fixed
+// to that currency's minor-unit precision, e.g. "10.50" for USD or "10"
+// for JPY.
+func (t *Totals) Format() map[string]string {
+	out := make(map[string]string, len(t.sums))
+	for code, sum := range t.sums {
+		out[code] = sum.FloatString(currency.MinorUnits(code))
+	}
+	return out
+}
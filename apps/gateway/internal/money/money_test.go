@@ -0,0 +1,64 @@
+package money
+
+import "testing"
+
+func TestTotals_AddSumsExactly(t *testing.T) {
+	var totals Totals
+	for i := 0; i < 10; i++ {
+		if err := totals.Add("USD", "0.10"); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	got := totals.Format()
+	if got["USD"] != "1.00" {
+		t.Fatalf("USD total = %q, want %q (float64 would drift off 1.00 here)", got["USD"], "1.00")
+	}
+}
+
+func TestTotals_AddManySmallValuesStaysExact(t *testing.T) {
+	var totals Totals
+	for i := 0; i < 1000; i++ {
+		if err := totals.Add("USD", "0.01"); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	got := totals.Format()
+	if got["USD"] != "10.00" {
+		t.Fatalf("USD total = %q, want %q", got["USD"], "10.00")
+	}
+}
+
+func TestTotals_AddKeepsCurrenciesSeparate(t *testing.T) {
+	var totals Totals
+	if err := totals.Add("USD", "5.00"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := totals.Add("JPY", "500"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	got := totals.Format()
+	if got["USD"] != "5.00" {
+		t.Fatalf("USD total = %q, want %q", got["USD"], "5.00")
+	}
+	if got["JPY"] != "500" {
+		t.Fatalf("JPY total = %q, want %q", got["JPY"], "500")
+	}
+}
+
+func TestTotals_AddRejectsInvalidAmount(t *testing.T) {
+	var totals Totals
+	if err := totals.Add("USD", "not-a-number"); err == nil {
+		t.Fatal("expected an error for a non-numeric amount")
+	}
+}
+
+func TestTotals_FormatOnZeroValueIsEmpty(t *testing.T) {
+	var totals Totals
+	got := totals.Format()
+	if len(got) != 0 {
+		t.Fatalf("Format() on zero value = %v, want empty map", got)
+	}
+}
@@ -0,0 +1,334 @@
+// Package ratelimit implements a Redis-backed fixed-window rate limiter,
+// applied as gin middleware in front of abuse-sensitive endpoints.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/middleware"
+)
+
+// Config controls how the limiter counts requests and what it does when
+// Redis itself is unavailable.
+type Config struct {
+	// Limit is the maximum number of requests a key may make per Window.
+	Limit int
+	// Window is the fixed window over which Limit is enforced.
+	Window time.Duration
+	// FailOpen controls what happens when Redis returns an error (as
+	// opposed to reporting the key over Limit): true, the default,
+	// preserves the old behavior of letting the request through so a
+	// Redis outage doesn't take down unrelated traffic. Abuse-sensitive
+	// endpoints may prefer false, which returns 503 instead so an outage
+	// can't be used to bypass the limit.
+	FailOpen bool
+
+	// Scope labels this Limiter for logs and the X-RateLimit-Scope
+	// response header ("user", "global", ...), so when two Limiters are
+	// stacked in front of the same routes, a rejection is attributable to
+	// the one that actually tripped.
+	Scope string
+	// ExceededStatus is the HTTP status returned when the limit is hit.
+	// Per-user limiters use 429 (the default): the caller did something
+	// about it. A global limiter should use 503 instead, since hitting it
+	// reflects aggregate system load rather than this caller's own
+	// behavior.
+	ExceededStatus int
+}
+
+// DefaultConfig returns a Config with a permissive per-user limit and
+// fail-open behavior, suitable as a starting point for most endpoints.
+func DefaultConfig() Config {
+	return Config{
+		Limit:          60,
+		Window:         time.Minute,
+		FailOpen:       true,
+		Scope:          "user",
+		ExceededStatus: http.StatusTooManyRequests,
+	}
+}
+
+// DefaultGlobalConfig returns a Config for a system-wide limiter meant to
+// sit in front of all /v1 traffic as a safety valve against a large number
+// of distinct callers hammering simultaneously, on top of (not instead of)
+// each caller's own per-user limiter. Its limit is deliberately high and
+// it fails closed: a global limiter that fails open is no safety valve at
+// all.
+func DefaultGlobalConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Limit = 5000
+	cfg.FailOpen = false
+	cfg.Scope = "global"
+	cfg.ExceededStatus = http.StatusServiceUnavailable
+	return cfg
+}
+
+// GlobalIdentity is the IdentityFunc for a global Limiter: every request
+// counts against the same key, regardless of caller.
+func GlobalIdentity(c *gin.Context) Identity {
+	return Identity{Key: "global"}
+}
+
+// DefaultIPConfig returns a Config for a Limiter keyed on the caller's IP
+// (see IPIdentity) -- the fallback identity for callers Auth hasn't
+// resolved a user_id for yet. Same limit and fail-open behavior as
+// DefaultConfig, just scoped "ip" for its logs and X-RateLimit-Scope
+// header.
+func DefaultIPConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Scope = "ip"
+	return cfg
+}
+
+// IPIdentity is the IdentityFunc for an IP-keyed Limiter. It resolves the
+// caller's IP via ClientIP, so the key it counts against -- and the
+// Allowlist CIDR check a Limiter runs before counting -- reflect the real
+// client rather than a X-Forwarded-For header any caller can set
+// themselves unless config.Config.TrustedProxies says otherwise.
+func IPIdentity(c *gin.Context) Identity {
+	ip := ClientIP(c)
+	return Identity{Key: ip, IP: ip}
+}
+
+// ClientIP resolves the caller's real IP, honoring X-Forwarded-For/
+// X-Real-IP only when the immediate peer is a trusted proxy (see
+// config.Config.TrustedProxies and server.SetupRouter's
+// router.SetTrustedProxies call). It's a thin wrapper around
+// gin.Context.ClientIP, which already enforces that trust boundary once
+// SetTrustedProxies has been called; the wrapper gives every IP-keyed
+// call site in this package one place to point at instead of each one
+// needing to know why reading the header directly isn't safe.
+func ClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// Allowlist exempts trusted identities (internal service accounts,
+// monitoring) from the limit entirely. A caller matching either list skips
+// Redis altogether, so an allowlisted identity never pays the limiter's
+// latency and never shows up in its counters.
+type Allowlist struct {
+	userIDs map[string]struct{}
+	cidrs   []*net.IPNet
+}
+
+// NewAllowlist builds an Allowlist from user IDs (matched exactly) and IP
+// CIDRs (e.g. "10.0.0.0/8" for an internal network). It returns an error if
+// any CIDR fails to parse.
+func NewAllowlist(userIDs []string, cidrs []string) (Allowlist, error) {
+	a := Allowlist{userIDs: make(map[string]struct{}, len(userIDs))}
+	for _, id := range userIDs {
+		a.userIDs[id] = struct{}{}
+	}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return Allowlist{}, fmt.Errorf("ratelimit: parse CIDR %q: %w", cidr, err)
+		}
+		a.cidrs = append(a.cidrs, n)
+	}
+	return a, nil
+}
+
+func (a Allowlist) exempts(identity Identity) bool {
+	if identity.UserID != "" {
+		if _, ok := a.userIDs[identity.UserID]; ok {
+			return true
+		}
+	}
+	if identity.IP == "" {
+		return false
+	}
+	ip := net.ParseIP(identity.IP)
+	if ip == nil {
+		return false
+	}
+	for _, n := range a.cidrs {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Identity is the caller information Middleware needs to decide both the
+// rate-limit key and whether the caller is exempt.
+type Identity struct {
+	// Key is what the limit is actually counted against (typically equal
+	// to UserID or IP, but kept separate so callers can key on something
+	// else, e.g. an API token).
+	Key    string
+	UserID string
+	IP     string
+}
+
+// IdentityFunc resolves the calling identity for a request.
+type IdentityFunc func(c *gin.Context) Identity
+
+// Limiter enforces Config against a Redis-backed fixed-window counter,
+// except for identities matching its Allowlist, which bypass the limit
+// entirely. Its Config is held behind an atomic pointer rather than a
+// plain field so UpdateLimits can swap it in without a lock: a request
+// racing the swap sees either the whole old Config or the whole new one,
+// never a mix of one's Limit with the other's Window.
+type Limiter struct {
+	redis     *redis.Client
+	cfg       atomic.Pointer[Config]
+	allowlist Allowlist
+}
+
+// NewRateLimiter returns a Limiter backed by the Redis instance at addr,
+// exempting any identity matched by allowlist.
+func NewRateLimiter(addr string, cfg Config, allowlist Allowlist) *Limiter {
+	l := &Limiter{
+		redis:     redis.NewClient(&redis.Options{Addr: addr}),
+		allowlist: allowlist,
+	}
+	l.cfg.Store(&cfg)
+	return l
+}
+
+// config returns the Limiter's current Config.
+func (l *Limiter) config() Config {
+	return *l.cfg.Load()
+}
+
+// UpdateLimits atomically swaps in a new Limit and Window, leaving Scope,
+// FailOpen, and ExceededStatus unchanged. Limit and Window are the only
+// settings meant to be tuned without a restart -- an incident response
+// wants "tighten the limit" to take effect immediately for every request
+// from that point on, not a redeploy. The rest shape how the limiter
+// behaves structurally rather than how strict it is, so they stay fixed
+// for the Limiter's lifetime. It returns the resulting Config.
+func (l *Limiter) UpdateLimits(limit int, window time.Duration) Config {
+	next := l.config()
+	next.Limit = limit
+	next.Window = window
+	l.cfg.Store(&next)
+	return next
+}
+
+// Middleware rejects requests once identityFunc's key has exceeded
+// cfg.Limit within cfg.Window, returning cfg.ExceededStatus. An identity
+// matched by the Limiter's Allowlist is checked first and, if exempt,
+// bypasses Redis entirely; it gets an "X-RateLimit-Limit: unlimited"
+// response header instead of whatever header a real count would otherwise
+// carry. Every response from this middleware also carries
+// "X-RateLimit-Scope: <cfg.Scope>", so when a global Limiter (see
+// DefaultGlobalConfig, GlobalIdentity) is chained in front of a per-user
+// one, a 503 is attributable to whichever of the two actually tripped:
+//
+//	router.Use(globalLimiter.Middleware(ratelimit.GlobalIdentity))
+//	router.Use(userLimiter.Middleware(identityFromAuth))
+//
+// If Redis itself errors for a non-exempt caller, the request is allowed
+// through or rejected with 503 depending on cfg.FailOpen; either way the
+// error is logged with the request ID so a Redis outage is distinguishable
+// from organic abuse in the logs.
+func (l *Limiter) Middleware(identityFunc IdentityFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := l.config()
+		c.Header("X-RateLimit-Scope", cfg.Scope)
+		identity := identityFunc(c)
+
+		if l.allowlist.exempts(identity) {
+			c.Header("X-RateLimit-Limit", "unlimited")
+			c.Next()
+			return
+		}
+
+		allowed, err := l.checkLimit(c.Request.Context(), identity.Key)
+		if err != nil {
+			log.Printf("rate limiter: redis error request_id=%s scope=%s fail_open=%t err=%v",
+				middleware.RequestIDFromContext(c), cfg.Scope, cfg.FailOpen, err)
+			if !cfg.FailOpen {
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+					"error": gin.H{
+						"code":    "RATE_LIMITER_UNAVAILABLE",
+						"message": "rate limiter temporarily unavailable",
+					},
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(cfg.ExceededStatus, gin.H{
+				"error": gin.H{
+					"code":    "RATE_LIMITED",
+					"message": "rate limit exceeded",
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkLimit increments key's counter for the current window and reports
+// whether it is still within cfg.Limit. The counter's TTL is (re)armed only
+// on the first increment of a window, so concurrent requests racing on the
+// same key don't each reset it.
+func (l *Limiter) checkLimit(ctx context.Context, key string) (bool, error) {
+	cfg := l.config()
+	count, err := l.redis.Incr(ctx, redisKey(key)).Result()
+	if err != nil {
+		return false, fmt.Errorf("ratelimit: incr: %w", err)
+	}
+	if count == 1 {
+		if err := l.redis.Expire(ctx, redisKey(key), cfg.Window).Err(); err != nil {
+			return false, fmt.Errorf("ratelimit: expire: %w", err)
+		}
+	}
+
+	return count <= int64(cfg.Limit), nil
+}
+
+// Status is a point-in-time read of a key's window, for the admin
+// ratelimit-status endpoint.
+type Status struct {
+	Count     int64
+	Limit     int
+	Remaining int64
+}
+
+// Status reports key's current count and remaining budget for this window,
+// without incrementing it. A key with no counter yet (it hasn't made a
+// request this window) reports a count of 0.
+func (l *Limiter) Status(ctx context.Context, key string) (Status, error) {
+	cfg := l.config()
+	count, err := l.redis.Get(ctx, redisKey(key)).Int64()
+	if err != nil && err != redis.Nil {
+		return Status{}, fmt.Errorf("ratelimit: get: %w", err)
+	}
+
+	remaining := int64(cfg.Limit) - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Status{Count: count, Limit: cfg.Limit, Remaining: remaining}, nil
+}
+
+// Reset clears key's counter, letting it make a full Limit of requests
+// again before the window would otherwise have expired. It reports the
+// number of keys actually deleted (0 if the key had no counter).
+func (l *Limiter) Reset(ctx context.Context, key string) (int64, error) {
+	n, err := l.redis.Del(ctx, redisKey(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("ratelimit: del: %w", err)
+	}
+	return n, nil
+}
+
+func redisKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s", key)
+}
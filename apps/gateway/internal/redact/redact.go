@@ -0,0 +1,96 @@
+// Package redact masks sensitive values before they reach a log line:
+// credentials that should never be logged at all (e.g. Authorization),
+// and identifiers (e.g. account IDs) that are fine to log in truncated
+// form for correlation but not in full.
+package redact
+
+import (
+	"net/http"
+	"strings"
+)
+
+const maskedValue = "[REDACTED]"
+
+// Config is the configurable list of header names and JSON field names
+// Redactor treats as sensitive.
+type Config struct {
+	// Headers are header names (case-insensitive) replaced with
+	// "[REDACTED]" entirely.
+	Headers []string
+	// JSONFields are JSON field names (exact match) whose string values
+	// are masked to their last 4 characters rather than dropped, so
+	// they're still useful for correlating a specific record without
+	// exposing the full identifier.
+	JSONFields []string
+}
+
+// DefaultConfig redacts the Authorization header and the account ID fields
+// the gateway's own request/response types use.
+func DefaultConfig() Config {
+	return Config{
+		Headers:    []string{"Authorization"},
+		JSONFields: []string{"account_id", "from_account_id", "to_account_id", "counterparty_id"},
+	}
+}
+
+// Redactor applies Config to headers and structured fields before they're
+// logged.
+type Redactor struct {
+	headers    map[string]struct{}
+	jsonFields map[string]struct{}
+}
+
+// New builds a Redactor from cfg.
+func New(cfg Config) Redactor {
+	r := Redactor{
+		headers:    make(map[string]struct{}, len(cfg.Headers)),
+		jsonFields: make(map[string]struct{}, len(cfg.JSONFields)),
+	}
+	for _, h := range cfg.Headers {
+		r.headers[strings.ToLower(h)] = struct{}{}
+	}
+	for _, f := range cfg.JSONFields {
+		r.jsonFields[f] = struct{}{}
+	}
+	return r
+}
+
+// Headers returns a copy of h with every configured header name's values
+// replaced by "[REDACTED]", leaving h itself untouched.
+func (r Redactor) Headers(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if _, ok := r.headers[strings.ToLower(name)]; ok {
+			out[name] = []string{maskedValue}
+		}
+	}
+	return out
+}
+
+// Fields returns a copy of fields with every configured JSON field name's
+// value masked via MaskTail, leaving fields itself untouched. Only
+// top-level string values are masked; non-string values for a matched
+// field are left as-is, since there's nothing sensible to truncate.
+func (r Redactor) Fields(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for k, v := range fields {
+		if _, ok := r.jsonFields[k]; ok {
+			if s, ok := v.(string); ok {
+				out[k] = MaskTail(s, 4)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// MaskTail replaces all but the last keep characters of s with "*". A
+// string no longer than keep is masked entirely, so a short ID doesn't
+// leak in full just because it happened to be short.
+func MaskTail(s string, keep int) string {
+	if len(s) <= keep {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-keep) + s[len(s)-keep:]
+}
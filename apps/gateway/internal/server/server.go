@@ -0,0 +1,297 @@
+// Package server wires the gateway's HTTP router: middleware, routes, and
+// the handlers that back them.
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/accesslog"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/balancecache"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/handler"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/idempotency"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/introspection"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/middleware"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ratelimit"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/redact"
+)
+
+// New builds the gateway's *http.Server, routing requests through client,
+// which should already be wrapped with whatever decorators (retry, circuit
+// breaker, bulkhead) the caller wants applied. It fails if cfg.AccessLogPath
+// is set but can't be opened, so a misconfigured log destination is caught
+// at startup rather than silently dropping access logs. The returned
+// *handler.HealthHandler is the same one wired into /readyz, so a caller
+// can call its SetShuttingDown method as part of graceful shutdown.
+func New(cfg *config.Config, client ledgerclient.LedgerClient) (*http.Server, *handler.HealthHandler, error) {
+	router, health, err := SetupRouter(cfg, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      router,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+	}, health, nil
+}
+
+// SetupRouter registers middleware and routes on a fresh gin.Engine. It
+// also returns the HealthHandler backing /readyz, so New can hand it to
+// callers that need to drive shutdown-time readiness.
+func SetupRouter(cfg *config.Config, client ledgerclient.LedgerClient) (*gin.Engine, *handler.HealthHandler, error) {
+	accessLogWriter, err := accesslog.NewWriter(accesslog.Config{
+		Path:         cfg.AccessLogPath,
+		MaxSizeBytes: cfg.AccessLogMaxSizeBytes,
+		MaxBackups:   cfg.AccessLogMaxBackups,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redactor := redact.New(redact.Config{
+		Headers:    cfg.LogRedactHeaders,
+		JSONFields: cfg.LogRedactJSONFields,
+	})
+
+	router := gin.New()
+	// An empty TrustedProxies trusts none, which is what makes ClientIP
+	// fall back to the TCP remote address instead of a spoofable
+	// X-Forwarded-For header -- see config.Config.TrustedProxies.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, nil, fmt.Errorf("server: set trusted proxies: %w", err)
+	}
+	router.Use(middleware.Recovery(redactor))
+
+	enabled, rateLimiters, err := buildMiddleware(cfg, accessLogWriter, redactor)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, mw := range enabled {
+		router.Use(mw)
+	}
+
+	healthHandler := handler.NewHealthHandler(client, cfg)
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	openapi := handler.NewOpenAPIHandler()
+	router.GET("/openapi.json", openapi.Spec)
+
+	idempotencyStore := idempotency.NewMemoryStore(cfg.IdempotencyRecordTTL, cfg.IdempotencyMaxRecords)
+	if cfg.IdempotencyRedisAddr != "" {
+		idempotencyStore = idempotency.NewRedisStore(cfg.IdempotencyRedisAddr, idempotency.RedisStoreConfig{
+			LockTTL:      cfg.IdempotencyLockTTL,
+			WaitTimeout:  cfg.IdempotencyLockWaitTimeout,
+			PollInterval: cfg.IdempotencyLockPollInterval,
+			RecordTTL:    cfg.IdempotencyRecordTTL,
+		})
+	}
+	txHandler := handler.NewTransactionHandler(client, cfg, idempotencyStore)
+	acctHandler := handler.NewAccountHandler(client, cfg)
+	balHandler := handler.NewBalanceHandler(client, cfg, balancecache.NewMemoryCache())
+	currencyHandler := handler.NewCurrencyHandler()
+	summaryHandler := handler.NewSummaryHandler(client, cfg)
+	wsHandler := handler.NewWSHandler(client, cfg)
+
+	v1 := router.Group("/v1")
+	{
+		v1.GET("/currencies", currencyHandler.List)
+
+		// CreateTransaction is the gateway's highest-value write, so it's
+		// the one route flagged single-use when replay protection is on;
+		// see middleware.ReplayProtection's doc comment for how this
+		// interacts with idempotency retries.
+		createTransaction := []gin.HandlerFunc{txHandler.Create}
+		if cfg.ReplayProtectionEnabled {
+			replayProtection := middleware.NewReplayProtection(cfg.ReplayProtectionRedisAddr)
+			createTransaction = []gin.HandlerFunc{replayProtection.RequireSingleUseToken(), txHandler.Create}
+		}
+		v1.POST("/transactions", createTransaction...)
+		v1.POST("/transactions/multi", txHandler.CreateMultiLeg)
+		v1.POST("/transfers/internal", txHandler.CreateInternalTransfer)
+
+		v1.GET("/accounts/:id/transactions", txHandler.List)
+		v1.GET("/accounts/:id/transactions.csv", txHandler.ExportCSV)
+
+		// Events and ws.Serve are long-lived: they hold the connection
+		// open to stream updates rather than writing one response and
+		// returning, so they run with http.Server's WriteTimeout disabled
+		// per request rather than inheriting the short timeout every
+		// other route keeps.
+		v1.GET("/transactions/:id/events", middleware.DisableWriteTimeout(), txHandler.Events)
+
+		v1.POST("/accounts", acctHandler.Create)
+		v1.POST("/accounts/batch", acctHandler.CreateBatch)
+		v1.GET("/accounts", acctHandler.List)
+		v1.GET("/accounts/:id", acctHandler.Get)
+		v1.DELETE("/accounts/:id", acctHandler.Close)
+		v1.PATCH("/accounts/:id", acctHandler.Update)
+		v1.GET("/accounts/:id/balance", balHandler.Get)
+		v1.POST("/accounts/balances", balHandler.GetBatch)
+
+		v1.GET("/summary", summaryHandler.Get)
+
+		v1.GET("/ws", middleware.DisableWriteTimeout(), wsHandler.Serve)
+	}
+
+	// The admin group sits outside /v1 and is never subject to the rate
+	// limiter itself, so an operator locked out by their own limit can
+	// still reach the endpoint that clears it.
+	if cfg.AdminRateLimitEnabled {
+		limiter := ratelimit.NewRateLimiter(cfg.RateLimitRedisAddr, ratelimit.DefaultConfig(), ratelimit.Allowlist{})
+		adminRateLimitHandler := handler.NewAdminRateLimitHandler(limiter, rateLimiters)
+
+		admin := router.Group("/admin", middleware.RequireAdmin(cfg.AdminToken))
+		admin.GET("/ratelimit/:user_id", adminRateLimitHandler.Status)
+		admin.DELETE("/ratelimit/:user_id", adminRateLimitHandler.Reset)
+		admin.PATCH("/ratelimit/config", adminRateLimitHandler.ReloadLimits)
+	}
+
+	if cfg.PprofEnabled {
+		if err := registerPprof(router, cfg.PprofAllowedCIDRs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return router, healthHandler, nil
+}
+
+// registerPprof registers net/http/pprof's handlers under /debug/pprof,
+// restricted to callers whose IP matches one of allowedCIDRs. It's only
+// called when cfg.PprofEnabled is true, so a profiling endpoint never
+// exists at all in a deployment that hasn't opted in.
+func registerPprof(router *gin.Engine, allowedCIDRs []string) error {
+	allowlist, err := middleware.NewIPAllowlist(allowedCIDRs)
+	if err != nil {
+		return fmt.Errorf("server: build pprof IP allowlist: %w", err)
+	}
+
+	debug := router.Group("/debug/pprof", allowlist.RequireAllowedIP())
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	debug.GET("/:name", func(c *gin.Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+	return nil
+}
+
+// buildMiddleware resolves cfg.Middleware against the registry below, in
+// order. Recovery isn't in the registry -- SetupRouter always installs it
+// first, ahead of anything configurable -- so a deployment can't
+// accidentally disable crash recovery by omitting a name.
+//
+// It also returns the global and IP rate limiters it built, keyed by
+// their Config.Scope, so SetupRouter can hand them to the admin reload
+// endpoint -- they're the only two limiters reachable from config, and
+// so the only two a deployment can tune live.
+func buildMiddleware(cfg *config.Config, accessLogWriter io.Writer, redactor redact.Redactor) ([]gin.HandlerFunc, map[string]*ratelimit.Limiter, error) {
+	allowlist, err := ratelimit.NewAllowlist(cfg.RateLimitAllowlistUserIDs, cfg.RateLimitAllowlistCIDRs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("server: build rate limit allowlist: %w", err)
+	}
+	globalLimiter := ratelimit.NewRateLimiter(cfg.RateLimitRedisAddr, ratelimit.DefaultGlobalConfig(), allowlist)
+	ipLimiter := ratelimit.NewRateLimiter(cfg.RateLimitRedisAddr, ratelimit.DefaultIPConfig(), allowlist)
+	rateLimiters := map[string]*ratelimit.Limiter{
+		"global": globalLimiter,
+		"ip":     ipLimiter,
+	}
+
+	registry := map[string]gin.HandlerFunc{
+		"request_id":        middleware.RequestID(),
+		"logging":           middleware.Logging(cfg.SlowRequestThreshold, accessLogWriter, redactor, cfg.LogRequestHeaders),
+		"auth":              middleware.Auth(authConfig(cfg)),
+		"rate_limit":        globalLimiter.Middleware(ratelimit.GlobalIdentity),
+		"rate_limit_ip":     ipLimiter.Middleware(ratelimit.IPIdentity),
+		"concurrency_limit": middleware.ConcurrencyLimit(cfg.ConcurrencyLimitMax),
+	}
+
+	handlers := make([]gin.HandlerFunc, 0, len(cfg.Middleware))
+	for _, name := range cfg.Middleware {
+		mw, ok := registry[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("server: unknown middleware %q", name)
+		}
+		handlers = append(handlers, mw)
+	}
+	return handlers, rateLimiters, nil
+}
+
+// authConfig builds middleware.AuthConfig from cfg, branching on
+// cfg.AuthMode: "opaque" wires an introspection.Client (wrapped in
+// introspector to satisfy middleware.Introspector) in AuthModeOpaque,
+// anything else (including the unset zero value) keeps Auth's original
+// AuthModeJWT behavior.
+func authConfig(cfg *config.Config) middleware.AuthConfig {
+	if cfg.AuthMode == "opaque" {
+		client := introspection.NewClient(introspection.Config{
+			URL:                            cfg.AuthIntrospectionURL,
+			ClientID:                       cfg.AuthIntrospectionClientID,
+			ClientSecret:                   cfg.AuthIntrospectionClientSecret,
+			Timeout:                        cfg.AuthIntrospectionTimeout,
+			CacheTTL:                       cfg.AuthIntrospectionCacheTTL,
+			CircuitBreakerFailureThreshold: cfg.AuthIntrospectionCircuitBreakerFailureThreshold,
+			CircuitBreakerOpenDuration:     cfg.AuthIntrospectionCircuitBreakerOpenDuration,
+		})
+		return middleware.AuthConfig{
+			Mode:         middleware.AuthModeOpaque,
+			Introspector: introspector{client: client},
+		}
+	}
+	return middleware.AuthConfig{
+		AcceptedAudiences: cfg.AuthAcceptedAudiences,
+		ClockSkewLeeway:   cfg.AuthClockSkewLeeway,
+		RequiredClaims:    requiredClaimTypes(cfg.AuthRequiredClaims),
+	}
+}
+
+// introspector adapts *introspection.Client to middleware.Introspector,
+// converting introspection.Result to middleware.IntrospectionResult --
+// the two are otherwise identical, but middleware can't depend on
+// introspection's concrete type without introducing a cycle back through
+// config, so Auth depends only on its own Introspector interface.
+type introspector struct {
+	client *introspection.Client
+}
+
+func (i introspector) Introspect(ctx context.Context, token string) (middleware.IntrospectionResult, error) {
+	result, err := i.client.Introspect(ctx, token)
+	if err != nil {
+		return middleware.IntrospectionResult{}, err
+	}
+	return middleware.IntrospectionResult{
+		Active:  result.Active,
+		Subject: result.Subject,
+		Scope:   result.Scope,
+	}, nil
+}
+
+// requiredClaimTypes converts cfg.AuthRequiredClaims' string-typed values
+// ("string", "int", "bool") to middleware.ClaimType, so config stays free
+// of a dependency on the middleware package. An unrecognized type string
+// is passed through as-is; middleware.Auth treats any type it doesn't
+// recognize the same as ClaimTypeString.
+func requiredClaimTypes(claims map[string]string) map[string]middleware.ClaimType {
+	if len(claims) == 0 {
+		return nil
+	}
+	out := make(map[string]middleware.ClaimType, len(claims))
+	for name, claimType := range claims {
+		out[name] = middleware.ClaimType(claimType)
+	}
+	return out
+}
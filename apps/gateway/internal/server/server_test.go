@@ -0,0 +1,174 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/config"
+	"github.com/ChungTau/double-entry-ledger/apps/gateway/internal/ledgerclient"
+)
+
+func TestSetupRouter_AuthModeOpaqueValidatesBearerTokenViaIntrospection(t *testing.T) {
+	introspectionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":true,"sub":"someone-else"}`))
+	}))
+	defer introspectionServer.Close()
+
+	cfg := &config.Config{
+		Middleware:           []string{"request_id", "auth"},
+		AuthMode:             "opaque",
+		AuthIntrospectionURL: introspectionServer.URL,
+	}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	req.Header.Set("Authorization", "Bearer opaque-token-value")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// someone-else owns no accounts, so a 200 with an empty list confirms
+	// the handler ran with the subject the introspection response named.
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestSetupRouter_AuthModeOpaqueRejectsMissingBearerToken(t *testing.T) {
+	cfg := &config.Config{
+		Middleware: []string{"request_id", "auth"},
+		AuthMode:   "opaque",
+	}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSetupRouter_UnknownMiddlewareFailsStartup(t *testing.T) {
+	cfg := &config.Config{Middleware: []string{"request_id", "not_a_real_middleware"}}
+
+	if _, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient()); err == nil {
+		t.Fatal("expected an error for an unrecognized middleware name, got nil")
+	}
+}
+
+func TestSetupRouter_EmptyMiddlewareListStillServesRequests(t *testing.T) {
+	cfg := &config.Config{Middleware: nil}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestSetupRouter_AuthMiddlewareSetsUserIDFromHeader(t *testing.T) {
+	cfg := &config.Config{Middleware: []string{"request_id", "auth"}}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts", nil)
+	req.Header.Set("X-User-ID", "someone-else")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// someone-else owns no accounts, so a 200 with an empty list confirms
+	// the handler ran with that identity rather than erroring out.
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestSetupRouter_InvalidTrustedProxyFailsStartup(t *testing.T) {
+	cfg := &config.Config{TrustedProxies: []string{"not-a-cidr"}}
+
+	if _, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient()); err == nil {
+		t.Fatal("expected an error for an invalid trusted proxy CIDR, got nil")
+	}
+}
+
+func TestSetupRouter_PprofDisabledByDefault(t *testing.T) {
+	cfg := &config.Config{}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestSetupRouter_InvalidPprofAllowedCIDRFailsStartup(t *testing.T) {
+	cfg := &config.Config{PprofEnabled: true, PprofAllowedCIDRs: []string{"not-a-cidr"}}
+
+	if _, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient()); err == nil {
+		t.Fatal("expected an error for an invalid pprof allowed CIDR, got nil")
+	}
+}
+
+func TestSetupRouter_PprofEnabledRejectsDisallowedIP(t *testing.T) {
+	cfg := &config.Config{PprofEnabled: true, PprofAllowedCIDRs: []string{"10.0.0.0/8"}}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	req.RemoteAddr = "203.0.113.1:12345"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestSetupRouter_PprofEnabledAllowsMatchingIP(t *testing.T) {
+	cfg := &config.Config{PprofEnabled: true, PprofAllowedCIDRs: []string{"10.0.0.0/8"}}
+
+	router, _, err := SetupRouter(cfg, ledgerclient.NewMockLedgerClient())
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	req.RemoteAddr = "10.1.2.3:12345"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
@@ -0,0 +1,43 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestBuild_DefaultsToTLS12AndDefaultCipherSuites(t *testing.T) {
+	cfg, err := Build(Config{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("MinVersion = %x, want TLS 1.2", cfg.MinVersion)
+	}
+	if len(cfg.CipherSuites) != len(DefaultCipherSuites) {
+		t.Fatalf("CipherSuites = %v, want %d entries", cfg.CipherSuites, len(DefaultCipherSuites))
+	}
+}
+
+func TestBuild_RejectsUnsupportedMinVersion(t *testing.T) {
+	_, err := Build(Config{MinVersion: "1.0"})
+	if err == nil {
+		t.Fatal("expected an error for an unsupported minimum TLS version")
+	}
+}
+
+func TestBuild_RejectsUnrecognizedCipherSuite(t *testing.T) {
+	_, err := Build(Config{CipherSuites: []string{"NOT_A_REAL_CIPHER_SUITE"}})
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized cipher suite")
+	}
+}
+
+func TestBuild_AcceptsTLS13WithExplicitCipherSuites(t *testing.T) {
+	cfg, err := Build(Config{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("MinVersion = %x, want TLS 1.3", cfg.MinVersion)
+	}
+}